@@ -0,0 +1,143 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+// Command tron queries and transacts on the TRON network from the command line.
+//
+// Usage:
+//
+//	tron [global flags] <command> [flags] [arguments]
+//
+// Run "tron help" for the list of commands.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dszi/go-tron/pkg"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	usage string // arguments and flags, after the command name
+	help  string
+	run   func(env *env, args []string) (*result, error)
+}
+
+var commands = map[string]*command{
+	"account":        {"<address>", "Show an account", runAccount},
+	"balance":        {"<address>", "Show the TRX balance of an account", runBalance},
+	"resources":      {"<address>", "Show the bandwidth and energy of an account", runResources},
+	"block":          {"[number]", "Show a block, the latest by default", runBlock},
+	"tx":             {"<txid>", "Show a transaction and its receipt", runTx},
+	"call":           {"--contract <address> --method <signature> [--args <json>] [--from <address>]", "Call a contract method without a transaction", runCall},
+	"transfer":       {"--to <address> --amount <sun>", "Send TRX", runTransfer},
+	"trc20-transfer": {"--contract <address> --to <address> --amount <units> [--fee-limit <sun>]", "Send TRC-20 tokens", runTRC20Transfer},
+	"freeze":         {"--amount <sun> [--resource energy|bandwidth]", "Stake TRX for a resource", runFreeze},
+	"delegate":       {"--to <address> --amount <sun> [--resource energy|bandwidth] [--lock-period <blocks>]", "Delegate staked resources", runDelegate},
+	"vote":           {"<witness>=<votes>...", "Vote for super representatives", runVote},
+	"trigger":        {"--contract <address> --method <signature> [--args <json>] [--value <sun>] [--fee-limit <sun>]", "Send a contract call transaction", runTrigger},
+}
+
+// env holds the node client shared by the commands.
+type env struct {
+	client pkg.TronClient
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "tron:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("tron", flag.ContinueOnError)
+	fs.Usage = func() { usage(fs) }
+	node := fs.String("node", "grpc.trongrid.io:50051", "gRPC address, or http(s):// URL of the HTTP API")
+	apiKey := fs.String("api-key", os.Getenv("TRON_PRO_API_KEY"), "TronGrid API key (default $TRON_PRO_API_KEY)")
+	solidity := fs.Bool("solidity", false, "query confirmed state from the solidity endpoints (HTTP API only)")
+	format := fs.String("output", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+
+	if fs.NArg() == 0 || fs.Arg(0) == "help" {
+		usage(fs)
+		return nil
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q; run \"tron help\"", fs.Arg(0))
+	}
+	out, err := newOutput(*format, stdout)
+	if err != nil {
+		return err
+	}
+
+	options := []pkg.Option{pkg.WithTimeout(*timeout)}
+	if *apiKey != "" {
+		options = append(options, pkg.WithAPIKey(*apiKey))
+	}
+	var client pkg.TronClient
+	if strings.HasPrefix(*node, "http://") || strings.HasPrefix(*node, "https://") {
+		httpOptions := make([]pkg.HTTPOption, 0, len(options)+1)
+		for _, opt := range options {
+			httpOptions = append(httpOptions, opt)
+		}
+		if *solidity {
+			httpOptions = append(httpOptions, pkg.WithSolidity())
+		}
+		client = pkg.NewHTTPClient(*node, httpOptions...)
+	} else {
+		if *solidity {
+			return fmt.Errorf("--solidity requires an HTTP node")
+		}
+		options = append(options, pkg.WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+		client = pkg.NewGrpcClient(*node, options...)
+	}
+	if err := client.Start(); err != nil {
+		return err
+	}
+	defer client.Stop()
+
+	res, err := cmd.run(&env{client: client}, fs.Args()[1:])
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+	return out.print(res)
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: tron [global flags] <command> [flags] [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-15s %s\n  %-15s   %s %s\n", name, commands[name].help, "", name, commands[name].usage)
+	}
+	fmt.Fprintln(w, "\nCommands that send transactions sign with --keystore <file>; the password is read from")
+	fmt.Fprintln(w, "--password-file, $TRON_KEYSTORE_PASSWORD or the first line of standard input.")
+	fmt.Fprintln(w, "\nGlobal flags:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRunBalance(t *testing.T) {
+	addr := "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9"
+	raw, _ := base58.DecodeCheck(addr)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/walletsolidity/getaccount", r.URL.Path)
+		_, _ = io.WriteString(w, `{"address":"`+hex.EncodeToString(raw)+`","balance":1500000}`)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	require.Nil(t, run([]string{"--node", server.URL, "--solidity", "--output", "json", "balance", addr}, &out))
+	var data map[string]any
+	require.Nil(t, json.Unmarshal(out.Bytes(), &data))
+	assert.Equal(t, "1.500000", data["balance_trx"])
+	assert.Equal(t, float64(1500000), data["balance_sun"])
+
+	out.Reset()
+	require.Nil(t, run([]string{"--node", server.URL, "--solidity", "balance", addr}, &out))
+	assert.Contains(t, out.String(), "balance_trx  1.500000")
+
+	assert.ErrorContains(t, run([]string{"--node", server.URL, "balance"}, &out), "expected one argument")
+	assert.ErrorContains(t, run([]string{"--node", server.URL, "nope"}, &out), "unknown command")
+	assert.ErrorContains(t, run([]string{"--solidity", "balance", addr}, &out), "requires an HTTP node")
+}
+
+func TestRunTransfer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	from := pkg.PublicKeyToAddress(key.PublicKey)
+	fromBytes, _ := base58.DecodeCheck(from)
+
+	account, err := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key, "secret")
+	require.Nil(t, err)
+	path := account.URL.Path
+	t.Setenv("TRON_KEYSTORE_PASSWORD", "secret")
+
+	var broadcast *core.Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		body, _ := io.ReadAll(r.Body)
+		require.Nil(t, json.Unmarshal(body, &req))
+		switch r.URL.Path {
+		case "/wallet/createtransaction":
+			assert.Equal(t, hex.EncodeToString(fromBytes), req["owner_address"])
+			_, _ = io.WriteString(w, `{"raw_data":{"contract":[{"parameter":{"value":{"amount":5,"owner_address":"`+
+				hex.EncodeToString(fromBytes)+`","to_address":"`+hex.EncodeToString(fromBytes)+`"},`+
+				`"type_url":"type.googleapis.com/protocol.TransferContract"},"type":"TransferContract"}],`+
+				`"ref_block_bytes":"a1b2","ref_block_hash":"0102030405060708","expiration":1700000060000}}`)
+		case "/wallet/broadcasthex":
+			raw, _ := hex.DecodeString(req["transaction"].(string))
+			broadcast = new(core.Transaction)
+			require.Nil(t, proto.Unmarshal(raw, broadcast))
+			_, _ = io.WriteString(w, `{"result":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	require.Nil(t, run([]string{"--node", server.URL, "transfer", "--keystore", path, "--to", from, "--amount", "5"}, &out))
+	require.NotNil(t, broadcast)
+	require.Len(t, broadcast.Signature, 1)
+
+	raw, _ := proto.Marshal(broadcast.RawData)
+	txid := sha256.Sum256(raw)
+	pub, err := crypto.SigToPub(txid[:], broadcast.Signature[0])
+	require.Nil(t, err)
+	assert.Equal(t, from, pkg.PublicKeyToAddress(*pub))
+	assert.Contains(t, out.String(), hex.EncodeToString(txid[:]))
+
+	assert.ErrorContains(t, run([]string{"--node", server.URL, "transfer", "--to", from, "--amount", "5"}, &out), "--keystore is required")
+}
@@ -0,0 +1,77 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// result is the output of a command: data is printed as JSON, and the header
+// and rows as a table.
+type result struct {
+	data   any
+	header []string
+	rows   [][]string
+}
+
+// fields is an ordered list of name/value pairs, printed as a two-column table
+// or a JSON object.
+type fields []field
+
+type field struct {
+	name  string
+	value any
+}
+
+// newFieldsResult creates a result with one row per field.
+func newFieldsResult(f fields) *result {
+	data := make(map[string]any, len(f))
+	res := &result{data: data, header: []string{"FIELD", "VALUE"}}
+	for _, kv := range f {
+		data[kv.name] = kv.value
+		res.rows = append(res.rows, []string{kv.name, fmt.Sprint(kv.value)})
+	}
+	return res
+}
+
+// output writes results in the selected format.
+type output struct {
+	format string
+	w      io.Writer
+}
+
+func newOutput(format string, w io.Writer) (*output, error) {
+	switch format {
+	case "table", "json":
+		return &output{format: format, w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected table or json", format)
+	}
+}
+
+func (o *output) print(res *result) error {
+	if o.format == "json" {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res.data)
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+	if len(res.header) > 0 {
+		fmt.Fprintln(tw, strings.Join(res.header, "\t"))
+	}
+	for _, row := range res.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,293 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg"
+	"github.com/dszi/go-tron/pkg/abi"
+)
+
+// sunPerTRX is the number of sun in one TRX.
+const sunPerTRX = 1_000_000
+
+// formatTRX formats an amount of sun in TRX.
+func formatTRX(sun int64) string {
+	sign := ""
+	if sun < 0 {
+		sign, sun = "-", -sun
+	}
+	return fmt.Sprintf("%s%d.%06d", sign, sun/sunPerTRX, sun%sunPerTRX)
+}
+
+// formatTime formats a timestamp in milliseconds, empty when unset.
+func formatTime(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+// encodeAddress formats a raw address in base58, empty when unset.
+func encodeAddress(addr []byte) string {
+	if len(addr) == 0 {
+		return ""
+	}
+	return base58.EncodeCheck(addr)
+}
+
+// oneArg checks that exactly one positional argument was given.
+func oneArg(args []string, name string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected one argument: <%s>", name)
+	}
+	return args[0], nil
+}
+
+func runAccount(e *env, args []string) (*result, error) {
+	addr, err := oneArg(args, "address")
+	if err != nil {
+		return nil, err
+	}
+	acc, err := e.client.GetAccount(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := fields{
+		{"address", addr},
+		{"name", string(acc.GetAccountName())},
+		{"balance", formatTRX(acc.GetBalance())},
+		{"created", formatTime(acc.GetCreateTime())},
+		{"witness", acc.GetIsWitness()},
+	}
+	for _, frozen := range acc.GetFrozenV2() {
+		if frozen.GetAmount() > 0 {
+			f = append(f, field{"staked_" + strings.ToLower(frozen.GetType().String()), formatTRX(frozen.GetAmount())})
+		}
+	}
+	for _, unfrozen := range acc.GetUnfrozenV2() {
+		f = append(f, field{"unstaking_" + strings.ToLower(unfrozen.GetType().String()),
+			formatTRX(unfrozen.GetUnfreezeAmount()) + " until " + formatTime(unfrozen.GetUnfreezeExpireTime())})
+	}
+	for _, v := range acc.GetVotes() {
+		f = append(f, field{"votes_" + encodeAddress(v.GetVoteAddress()), v.GetVoteCount()})
+	}
+	return newFieldsResult(f), nil
+}
+
+func runBalance(e *env, args []string) (*result, error) {
+	addr, err := oneArg(args, "address")
+	if err != nil {
+		return nil, err
+	}
+	balance, err := e.client.GetAccountBalance(addr)
+	if err != nil {
+		return nil, err
+	}
+	return newFieldsResult(fields{
+		{"address", addr},
+		{"balance_sun", balance},
+		{"balance_trx", formatTRX(balance)},
+	}), nil
+}
+
+func runResources(e *env, args []string) (*result, error) {
+	addr, err := oneArg(args, "address")
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.client.GetAccountResource(addr)
+	if err != nil {
+		return nil, err
+	}
+	return newFieldsResult(fields{
+		{"address", addr},
+		{"free_bandwidth_used", res.GetFreeNetUsed()},
+		{"free_bandwidth_limit", res.GetFreeNetLimit()},
+		{"bandwidth_used", res.GetNetUsed()},
+		{"bandwidth_limit", res.GetNetLimit()},
+		{"energy_used", res.GetEnergyUsed()},
+		{"energy_limit", res.GetEnergyLimit()},
+		{"tron_power_used", res.GetTronPowerUsed()},
+		{"tron_power_limit", res.GetTronPowerLimit()},
+	}), nil
+}
+
+func runBlock(e *env, args []string) (*result, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("expected at most one argument: [number]")
+	}
+	block, err := e.client.GetNowBlock()
+	if len(args) == 1 {
+		num, perr := strconv.ParseInt(args[0], 10, 64)
+		if perr != nil {
+			return nil, fmt.Errorf("invalid block number %q", args[0])
+		}
+		block, err = e.client.GetBlockByNum(num)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	raw := block.GetBlockHeader().GetRawData()
+	txids := make([]string, 0, len(block.GetTransactions()))
+	for _, tx := range block.GetTransactions() {
+		txids = append(txids, hex.EncodeToString(tx.GetTxid()))
+	}
+	res := newFieldsResult(fields{
+		{"number", raw.GetNumber()},
+		{"id", hex.EncodeToString(block.GetBlockid())},
+		{"parent", hex.EncodeToString(raw.GetParentHash())},
+		{"time", formatTime(raw.GetTimestamp())},
+		{"witness", encodeAddress(raw.GetWitnessAddress())},
+		{"transactions", len(txids)},
+	})
+	res.data.(map[string]any)["txids"] = txids
+	for _, txid := range txids {
+		res.rows = append(res.rows, []string{"tx", txid})
+	}
+	return res, nil
+}
+
+func runTx(e *env, args []string) (*result, error) {
+	id, err := oneArg(args, "txid")
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.GetTransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := pkg.NewTransactionDecoder(e.client.GetContractABI).Decode(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := fields{
+		{"txid", decoded.TxID},
+		{"status", transactionStatus(tx)},
+	}
+	if !decoded.Timestamp.IsZero() {
+		f = append(f, field{"time", decoded.Timestamp.UTC().Format(time.RFC3339)})
+	}
+	if decoded.FeeLimit > 0 {
+		f = append(f, field{"fee_limit", formatTRX(decoded.FeeLimit)})
+	}
+	if decoded.Memo != "" {
+		f = append(f, field{"memo", decoded.Memo})
+	}
+	// The receipt is missing until the transaction is in a block.
+	if info, err := e.client.GetTransactionInfoByID(id); err == nil && info.GetBlockNumber() > 0 {
+		f = append(f,
+			field{"block", info.GetBlockNumber()},
+			field{"fee", formatTRX(info.GetFee())},
+			field{"energy", info.GetReceipt().GetEnergyUsageTotal()},
+			field{"bandwidth", info.GetReceipt().GetNetUsage()},
+		)
+		if msg := string(info.GetResMessage()); msg != "" {
+			f = append(f, field{"message", msg})
+		}
+	}
+
+	res := newFieldsResult(f)
+	contracts := make([]map[string]any, 0, len(decoded.Contracts))
+	for _, c := range decoded.Contracts {
+		contract := map[string]any{"type": c.Type.String(), "fields": c.Fields}
+		res.rows = append(res.rows, []string{"contract", c.Type.String()})
+		keys := make([]string, 0, len(c.Fields))
+		for k := range c.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			res.rows = append(res.rows, []string{"  " + k, fmt.Sprint(c.Fields[k])})
+		}
+		if c.Call != nil {
+			contract["call"] = c.Call
+			res.rows = append(res.rows, []string{"  call", formatCall(c.Call)})
+		}
+		contracts = append(contracts, contract)
+	}
+	res.data.(map[string]any)["contracts"] = contracts
+	return res, nil
+}
+
+// transactionStatus returns the contract result recorded in a transaction.
+func transactionStatus(tx *core.Transaction) string {
+	if len(tx.GetRet()) == 0 {
+		return "PENDING"
+	}
+	return tx.GetRet()[0].GetContractRet().String()
+}
+
+// formatCall formats a decoded contract call as name(arg=value, ...).
+func formatCall(call *abi.MethodCall) string {
+	args := make([]string, len(call.Args))
+	for i, a := range call.Args {
+		args[i] = fmt.Sprintf("%s=%v", a.Name, a.Value)
+	}
+	return call.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func runCall(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	contract := fs.String("contract", "", "contract address")
+	method := fs.String("method", "", "method signature, e.g. balanceOf(address)")
+	jsonArgs := fs.String("args", "[]", `method arguments, e.g. [{"address":"T..."}]`)
+	from := fs.String("from", "", "caller address (default the contract)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *contract == "" || *method == "" {
+		return nil, fmt.Errorf("--contract and --method are required")
+	}
+	if *from == "" {
+		*from = *contract
+	}
+
+	data, err := packCall(*method, *jsonArgs)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.TriggerConstantContract(*from, *contract, data)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]string, len(tx.GetConstantResult()))
+	for i, r := range tx.GetConstantResult() {
+		results[i] = hex.EncodeToString(r)
+	}
+	return newFieldsResult(fields{
+		{"result", strings.Join(results, ",")},
+		{"energy_used", tx.GetEnergyUsed()},
+	}), nil
+}
+
+// packCall encodes a method call from its signature and JSON arguments.
+func packCall(method, jsonArgs string) ([]byte, error) {
+	params, err := abi.LoadFromJSON(jsonArgs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --args: %w", err)
+	}
+	data, err := abi.Pack(method, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode call: %w", err)
+	}
+	return data, nil
+}
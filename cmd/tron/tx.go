@@ -0,0 +1,283 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg"
+	"golang.org/x/term"
+)
+
+// defaultFeeLimit is the fee limit of contract calls, 100 TRX.
+const defaultFeeLimit = 100 * sunPerTRX
+
+// signer holds the keystore flags of the commands that send transactions.
+type signer struct {
+	keystore     *string
+	passwordFile *string
+}
+
+func newSigner(fs *flag.FlagSet) *signer {
+	return &signer{
+		keystore:     fs.String("keystore", "", "keystore file of the sending account"),
+		passwordFile: fs.String("password-file", "", "file holding the keystore password"),
+	}
+}
+
+// load decrypts the keystore and returns the key and its address.
+func (s *signer) load() (*ecdsa.PrivateKey, string, error) {
+	if *s.keystore == "" {
+		return nil, "", fmt.Errorf("--keystore is required")
+	}
+	password, err := s.password()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := pkg.LoadKeystore(*s.keystore, password)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, pkg.PublicKeyToAddress(key.PublicKey), nil
+}
+
+// password reads the keystore password from the password file, the environment or
+// standard input, without echoing it when standard input is a terminal.
+func (s *signer) password() (string, error) {
+	if *s.passwordFile != "" {
+		data, err := os.ReadFile(*s.passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv("TRON_KEYSTORE_PASSWORD"); ok {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "Keystore password: ")
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return string(password), nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// send signs and broadcasts a transaction created by the node.
+func send(e *env, key *ecdsa.PrivateKey, tx *api.TransactionExtention) (*result, error) {
+	if tx.GetTransaction() == nil {
+		return nil, fmt.Errorf("the node returned no transaction")
+	}
+	if err := pkg.SignTransaction(tx.Transaction, key); err != nil {
+		return nil, err
+	}
+	ret, err := e.client.BroadcastTransaction(tx.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	if !ret.GetResult() {
+		return nil, fmt.Errorf("broadcast rejected: %s: %s", ret.GetCode(), ret.GetMessage())
+	}
+	return newFieldsResult(fields{
+		{"txid", hex.EncodeToString(tx.GetTxid())},
+		{"result", "broadcast"},
+	}), nil
+}
+
+// parseResource parses a resource name.
+func parseResource(s string) (core.ResourceCode, error) {
+	switch strings.ToLower(s) {
+	case "energy":
+		return core.ResourceCode_ENERGY, nil
+	case "bandwidth":
+		return core.ResourceCode_BANDWIDTH, nil
+	default:
+		return 0, fmt.Errorf("unknown resource %q, expected energy or bandwidth", s)
+	}
+}
+
+// positive checks that an amount flag is set.
+func positive(name string, v int64) error {
+	if v <= 0 {
+		return fmt.Errorf("--%s must be positive", name)
+	}
+	return nil
+}
+
+func runTransfer(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("transfer", flag.ContinueOnError)
+	s := newSigner(fs)
+	to := fs.String("to", "", "recipient address")
+	amount := fs.Int64("amount", 0, "amount in sun")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := positive("amount", *amount); err != nil {
+		return nil, err
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.CreateTransaction(from, *to, *amount)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
+
+func runTRC20Transfer(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("trc20-transfer", flag.ContinueOnError)
+	s := newSigner(fs)
+	contract := fs.String("contract", "", "token contract address")
+	to := fs.String("to", "", "recipient address")
+	amount := fs.String("amount", "", "amount in the smallest token unit")
+	feeLimit := fs.Int64("fee-limit", defaultFeeLimit, "fee limit in sun")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if v, ok := new(big.Int).SetString(*amount, 10); !ok || v.Sign() <= 0 {
+		return nil, fmt.Errorf("--amount must be a positive integer")
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	jsonArgs := fmt.Sprintf(`[{"address":%q},{"uint256":%q}]`, *to, *amount)
+	tx, err := e.client.TriggerContract(from, *contract, "transfer(address,uint256)", jsonArgs, *feeLimit, 0, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
+
+func runFreeze(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("freeze", flag.ContinueOnError)
+	s := newSigner(fs)
+	amount := fs.Int64("amount", 0, "amount to stake in sun")
+	resource := fs.String("resource", "energy", "energy or bandwidth")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := positive("amount", *amount); err != nil {
+		return nil, err
+	}
+	code, err := parseResource(*resource)
+	if err != nil {
+		return nil, err
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.FreezeBalanceV2(from, code, *amount)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
+
+func runDelegate(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("delegate", flag.ContinueOnError)
+	s := newSigner(fs)
+	to := fs.String("to", "", "receiver address")
+	amount := fs.Int64("amount", 0, "staked amount to delegate in sun")
+	resource := fs.String("resource", "energy", "energy or bandwidth")
+	lockPeriod := fs.Int64("lock-period", 0, "lock the delegation for this many blocks")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := positive("amount", *amount); err != nil {
+		return nil, err
+	}
+	code, err := parseResource(*resource)
+	if err != nil {
+		return nil, err
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.DelegateResource(from, *to, code, *amount, *lockPeriod > 0, *lockPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
+
+func runVote(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("vote", flag.ContinueOnError)
+	s := newSigner(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		return nil, fmt.Errorf("expected at least one <witness>=<votes>")
+	}
+	votes := make(map[string]int64, fs.NArg())
+	for _, arg := range fs.Args() {
+		witness, count, ok := strings.Cut(arg, "=")
+		n, err := strconv.ParseInt(count, 10, 64)
+		if !ok || err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid vote %q, expected <witness>=<votes>", arg)
+		}
+		votes[witness] = n
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.VoteWitnessAccount(from, votes)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
+
+func runTrigger(e *env, args []string) (*result, error) {
+	fs := flag.NewFlagSet("trigger", flag.ContinueOnError)
+	s := newSigner(fs)
+	contract := fs.String("contract", "", "contract address")
+	method := fs.String("method", "", "method signature, e.g. approve(address,uint256)")
+	jsonArgs := fs.String("args", "[]", `method arguments, e.g. [{"address":"T..."},{"uint256":"1"}]`)
+	value := fs.Int64("value", 0, "TRX to send with the call, in sun")
+	feeLimit := fs.Int64("fee-limit", defaultFeeLimit, "fee limit in sun")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *contract == "" || *method == "" {
+		return nil, fmt.Errorf("--contract and --method are required")
+	}
+	key, from, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := e.client.TriggerContract(from, *contract, *method, *jsonArgs, *feeLimit, *value, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	return send(e, key, tx)
+}
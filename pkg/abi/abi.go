@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"golang.org/x/crypto/sha3"
+	"math"
 	"math/big"
 	"reflect"
 	"strconv"
@@ -34,8 +35,11 @@ func LoadFromJSON(jString string) ([]Param, error) {
 	if len(jString) == 0 {
 		return nil, nil
 	}
+	// Numbers are kept as json.Number so that large integers are not rounded.
 	var data []Param
-	err := json.Unmarshal([]byte(jString), &data)
+	dec := json.NewDecoder(strings.NewReader(jString))
+	dec.UseNumber()
+	err := dec.Decode(&data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
@@ -45,6 +49,9 @@ func LoadFromJSON(jString string) ([]Param, error) {
 // convertToAddress converts a TRON base58-encoded address into an Ethereum-style address (20 bytes).
 // It extracts the last 20 bytes from the decoded address.
 func convertToAddress(v any) (common.Address, error) {
+	if addr, ok := v.(common.Address); ok {
+		return addr, nil
+	}
 	str, ok := v.(string)
 	if !ok {
 		return common.Address{}, errors.New("invalid address type")
@@ -62,132 +69,224 @@ func convertToAddress(v any) (common.Address, error) {
 	return common.BytesToAddress(addr[len(addr)-20:]), nil
 }
 
-// convertToInt converts various integer types into the required ABI integer format.
-// Supports int8, int16, int32, int64, uint8, uint16, uint32, uint64, *big.Int, and string representations.
-func convertToInt(ty eABI.Type, v interface{}) (interface{}, error) {
+// bigIntType is the Go type go-ethereum uses for integers that do not fit a native type.
+var bigIntType = reflect.TypeOf((*big.Int)(nil))
+
+// convertToInt converts an integer into the Go type expected for the ABI integer type.
+// Supports Go integers, *big.Int, JSON numbers and decimal or hex strings, and
+// checks that the value fits in the type.
+func convertToInt(ty eABI.Type, v any) (any, error) {
+	var (
+		n   *big.Int
+		err error
+	)
 	switch val := v.(type) {
+	case *big.Int:
+		n = val
 	case string:
-		return parseIntFromString(ty, val)
+		n, err = parseBigInt(strings.TrimSpace(val))
+	case json.Number:
+		n, err = parseBigInt(val.String())
+	case float64:
+		if val != math.Trunc(val) {
+			return nil, fmt.Errorf("%v is not an integer", val)
+		}
+		n, _ = big.NewFloat(val).Int(nil)
 	case int, int8, int16, int32, int64:
-		return castIntSize(reflect.ValueOf(val).Int(), ty.Size), nil
+		n = big.NewInt(reflect.ValueOf(val).Int())
 	case uint, uint8, uint16, uint32, uint64:
-		return castUintSize(reflect.ValueOf(val).Uint(), ty.Size), nil
-	case *big.Int:
-		return val, nil
+		n = new(big.Int).SetUint64(reflect.ValueOf(val).Uint())
 	default:
 		return nil, fmt.Errorf("unsupported type for integer conversion: %T", v)
 	}
-}
-
-// parseIntFromString parses string representations of integers (decimal or hex) into appropriate numeric values.
-func parseIntFromString(ty eABI.Type, s string) (interface{}, error) {
-	if ty.T == eABI.IntTy && ty.Size <= 64 {
-		tmp, err := strconv.ParseInt(s, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse int: %w", err)
-		}
-		return castIntSize(tmp, ty.Size), nil
+	if err != nil {
+		return nil, err
 	}
 
-	if ty.T == eABI.UintTy && ty.Size <= 64 {
-		tmp, err := strconv.ParseUint(s, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse uint: %w", err)
+	if ty.T == eABI.UintTy {
+		if n.Sign() < 0 || n.BitLen() > ty.Size {
+			return nil, fmt.Errorf("%s out of range for %s", n, ty)
 		}
-		return castUintSize(tmp, ty.Size), nil
-	}
-
-	// Handle large integers (e.g., uint256)
-	var intValue *big.Int
-	if strings.HasPrefix(s, "0x") {
-		intValue, _ = new(big.Int).SetString(s[2:], 16)
 	} else {
-		intValue, _ = new(big.Int).SetString(s, 10)
-	}
-
-	if intValue == nil {
-		return nil, fmt.Errorf("failed to parse big.Int from: %s", s)
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(ty.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, fmt.Errorf("%s out of range for %s", n, ty)
+		}
 	}
-	return intValue, nil
-}
 
-// castIntSize ensures signed integer types match the expected ABI size.
-func castIntSize(value int64, size int) interface{} {
-	switch size {
-	case 8:
-		return int8(value)
-	case 16:
-		return int16(value)
-	case 32:
-		return int32(value)
-	case 64:
-		return int64(value)
+	target := ty.GetType()
+	switch {
+	case target == bigIntType:
+		return n, nil
+	case ty.T == eABI.UintTy:
+		return reflect.ValueOf(n.Uint64()).Convert(target).Interface(), nil
 	default:
-		return value
+		return reflect.ValueOf(n.Int64()).Convert(target).Interface(), nil
 	}
 }
 
-// castUintSize ensures unsigned integer types match the expected ABI size.
-func castUintSize(value uint64, size int) interface{} {
-	switch size {
-	case 8:
-		return uint8(value)
-	case 16:
-		return uint16(value)
-	case 32:
-		return uint32(value)
-	case 64:
-		return uint64(value)
+// convertToBool converts a bool or a "true"/"false" string.
+func convertToBool(v any) (bool, error) {
+	switch val := v.(type) {
+	case bool:
+		return val, nil
+	case string:
+		return strconv.ParseBool(val)
 	default:
-		return value
+		return false, fmt.Errorf("unsupported type for bool conversion: %T", v)
 	}
 }
 
-// convertToBytes converts string input to a byte slice for ABI encoding.
-// Supports both **hex** and **base64** encoding.
-func convertToBytes(ty eABI.Type, v interface{}) (interface{}, error) {
-	if data, ok := v.(string); ok {
-		dataBytes, err := hex.DecodeString(data)
+// decodeBytes decodes a byte string given as hex (with or without 0x) or base64.
+func decodeBytes(v any) ([]byte, error) {
+	switch data := v.(type) {
+	case []byte:
+		return data, nil
+	case string:
+		dataBytes, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
 		if err != nil {
 			dataBytes, err = base64.StdEncoding.DecodeString(data)
 			if err != nil {
 				return nil, err
 			}
 		}
+		return dataBytes, nil
+	default:
+		return nil, fmt.Errorf("unsupported type for bytes conversion: %T", v)
+	}
+}
+
+// convertToBytes converts string input to a byte slice or fixed-size byte array for ABI encoding.
+// Supports both **hex** and **base64** encoding.
+func convertToBytes(ty eABI.Type, v any) (any, error) {
+	dataBytes, err := decodeBytes(v)
+	if err != nil {
+		return nil, err
+	}
+	if ty.T == eABI.BytesTy {
+		return dataBytes, nil
+	}
 
-		if ty.T == eABI.BytesTy || ty.Size == 0 {
-			return dataBytes, nil
+	// bytes1..bytes32 and function are fixed-size arrays
+	value := reflect.New(ty.GetType()).Elem()
+	if len(dataBytes) != value.Len() {
+		return nil, fmt.Errorf("invalid size: %d/%d", value.Len(), len(dataBytes))
+	}
+	reflect.Copy(value, reflect.ValueOf(dataBytes))
+	return value.Interface(), nil
+}
+
+// convertToArray converts a Go or JSON list into the slice or array type of ty.
+func convertToArray(ty eABI.Type, v any) (any, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected array for %s but got %T", ty, v)
+	}
+	if ty.T == eABI.ArrayTy && rv.Len() != ty.Size {
+		return nil, fmt.Errorf("invalid array size, expected %d but got %d", ty.Size, rv.Len())
+	}
+
+	var out reflect.Value
+	if ty.T == eABI.ArrayTy {
+		out = reflect.New(ty.GetType()).Elem()
+	} else {
+		out = reflect.MakeSlice(ty.GetType(), rv.Len(), rv.Len())
+	}
+	for i := 0; i < rv.Len(); i++ {
+		elem, err := convertValue(*ty.Elem, rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out.Index(i).Set(reflect.ValueOf(elem))
+	}
+	return out.Interface(), nil
+}
+
+// convertToTuple converts a JSON object keyed by component name, or a list of
+// component values in order, into the struct type of ty.
+func convertToTuple(ty eABI.Type, v any) (any, error) {
+	out := reflect.New(ty.GetType()).Elem()
+
+	var values []any
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) != len(ty.TupleElems) {
+			return nil, fmt.Errorf("expected %d tuple components but got %d", len(ty.TupleElems), len(val))
+		}
+		for _, name := range ty.TupleRawNames {
+			field, ok := val[name]
+			if !ok {
+				return nil, fmt.Errorf("missing tuple component %s", name)
+			}
+			values = append(values, field)
+		}
+	case Param:
+		return convertToTuple(ty, map[string]any(val))
+	case []any:
+		if len(val) != len(ty.TupleElems) {
+			return nil, fmt.Errorf("expected %d tuple components but got %d", len(ty.TupleElems), len(val))
+		}
+		values = val
+	default:
+		// Go structs are matched by field name, as go-ethereum names tuple fields.
+		rv := reflect.Indirect(reflect.ValueOf(v))
+		if rv.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("expected object or array for %s but got %T", ty, v)
+		}
+		for _, name := range ty.TupleRawNames {
+			field := rv.FieldByName(eABI.ToCamelCase(name))
+			if !field.IsValid() || !field.CanInterface() {
+				return nil, fmt.Errorf("missing tuple component %s in %T", name, v)
+			}
+			values = append(values, field.Interface())
 		}
-		if len(dataBytes) != ty.Size {
-			return nil, fmt.Errorf("invalid size: %d/%d", ty.Size, len(dataBytes))
+	}
+
+	for i, elem := range ty.TupleElems {
+		field, err := convertValue(*elem, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", ty.TupleRawNames[i], err)
 		}
-		switch ty.Size {
-		case 1:
-			value := [1]byte{}
-			copy(value[:], dataBytes[:1])
-			return value, nil
-		case 2:
-			value := [2]byte{}
-			copy(value[:], dataBytes[:2])
-			return value, nil
-		case 8:
-			value := [8]byte{}
-			copy(value[:], dataBytes[:8])
-			return value, nil
-		case 16:
-			value := [16]byte{}
-			copy(value[:], dataBytes[:16])
-			return value, nil
-		case 32:
-			value := [32]byte{}
-			copy(value[:], dataBytes[:32])
-			return value, nil
+		out.Field(i).Set(reflect.ValueOf(field))
+	}
+	return out.Interface(), nil
+}
+
+// convertValue converts a parameter value into the Go value go-ethereum packs for ty.
+// Values that already have the expected Go type are used as they are.
+func convertValue(ty eABI.Type, v any) (any, error) {
+	if v == nil {
+		return nil, fmt.Errorf("missing value for %s", ty)
+	}
+	if reflect.TypeOf(v) == ty.GetType() {
+		return v, nil
+	}
+
+	switch ty.T {
+	case eABI.IntTy, eABI.UintTy:
+		return convertToInt(ty, v)
+	case eABI.BoolTy:
+		return convertToBool(v)
+	case eABI.StringTy:
+		if s, ok := v.(string); ok {
+			return s, nil
 		}
+		return nil, fmt.Errorf("expected string but got %T", v)
+	case eABI.AddressTy:
+		return convertToAddress(v)
+	case eABI.BytesTy, eABI.FixedBytesTy, eABI.FunctionTy:
+		return convertToBytes(ty, v)
+	case eABI.SliceTy, eABI.ArrayTy:
+		return convertToArray(ty, v)
+	case eABI.TupleTy:
+		return convertToTuple(ty, v)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", ty)
 	}
-	return v, nil
 }
 
 // GetPaddedParam encodes input parameters into ABI-compliant byte arrays.
+// Each Param maps a type to its value; see NewType for the accepted types.
 func GetPaddedParam(param []Param) ([]byte, error) {
 	values := make([]interface{}, 0)
 	arguments := eABI.Arguments{}
@@ -199,75 +298,16 @@ func GetPaddedParam(param []Param) ([]byte, error) {
 
 		for k, v := range p {
 			// Determine the ABI type
-			ty, err := eABI.NewType(k, "", nil)
+			ty, err := NewType(k)
 			if err != nil {
 				return nil, fmt.Errorf("invalid parameter type %s: %+v", k, err)
 			}
 			arguments = append(arguments, eABI.Argument{Name: "", Type: ty})
 
-			// Handle arrays and slices
-			if ty.T == eABI.SliceTy || ty.T == eABI.ArrayTy {
-				switch ty.Elem.T {
-				case eABI.AddressTy:
-					tmp, ok := v.([]interface{})
-					if !ok {
-						return nil, fmt.Errorf("expected address array but got %+v", p)
-					}
-
-					if ty.T == eABI.ArrayTy && len(tmp) != ty.Size {
-						return nil, fmt.Errorf("invalid array size, expected %d but got %d", ty.Size, len(tmp))
-					}
-
-					addresses := make([]common.Address, len(tmp))
-					for i := range tmp {
-						addr, err := convertToAddress(tmp[i])
-						if err != nil {
-							return nil, err
-						}
-						addresses[i] = addr
-					}
-					v = addresses
-
-				case eABI.IntTy, eABI.UintTy:
-					if ty.Elem.Size > 64 {
-						tmp := make([]*big.Int, 0)
-						tmpSlice, ok := v.([]string)
-						if !ok {
-							return nil, fmt.Errorf("expected array of uint but got %+v", p)
-						}
-						for i := range tmpSlice {
-							val, err := parseBigInt(tmpSlice[i])
-							if err != nil {
-								return nil, err
-							}
-							tmp = append(tmp, val)
-						}
-						v = tmp
-					}
-				}
-			}
-
-			if ty.T == eABI.AddressTy {
-				v, err = convertToAddress(v)
-				if err != nil {
-					return nil, err
-				}
-			}
-
-			if (ty.T == eABI.IntTy || ty.T == eABI.UintTy) && reflect.TypeOf(v).Kind() == reflect.String {
-				v, err = convertToInt(ty, v)
-				if err != nil {
-					return nil, err
-				}
-			}
-
-			if ty.T == eABI.BytesTy || ty.T == eABI.FixedBytesTy {
-				v, err = convertToBytes(ty, v)
-				if err != nil {
-					return nil, err
-				}
+			v, err = convertValue(ty, v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", k, err)
 			}
-
 			values = append(values, v)
 		}
 	}
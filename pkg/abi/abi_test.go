@@ -11,9 +11,13 @@ package abi
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
+	"reflect"
 	"testing"
 
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +99,106 @@ func TestABIParamHexUint256(t *testing.T) {
 	assert.Len(t, b, 64, fmt.Sprintf("Wrong length %d/%d", len(b), 64))
 	assert.Equal(t, "000000000000000000000000000000000000000000000000000000000001e240000000000000000000000000000000000000000000000000000000000001e240", hex.EncodeToString(b))
 }
+
+// TestABIParamMatchesGoEthereum compares the packer with go-ethereum packing native Go values.
+func TestABIParamMatchesGoEthereum(t *testing.T) {
+	param, err := LoadFromJSON(`
+	[
+		{"bool": true},
+		{"string[]": ["TRX", "USDT"]},
+		{"bytes[]": ["0x0102", "030405"]},
+		{"uint256[][]": [["1", "2"], [3]]},
+		{"bytes3": "0xabcdef"},
+		{"int24": "-5"},
+		{"trcToken": 1000001},
+		{"uint64": "0xffffffffffffffff"},
+		{"address[]": ["TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"]}
+	]
+	`)
+	require.Nil(t, err)
+	b, err := GetPaddedParam(param)
+	require.Nil(t, err)
+
+	addr, err := ToEthAddress("TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ")
+	require.Nil(t, err)
+	var arguments eABI.Arguments
+	for _, typ := range []string{"bool", "string[]", "bytes[]", "uint256[][]", "bytes3", "int24", "uint256", "uint64", "address[]"} {
+		ty, err := eABI.NewType(typ, "", nil)
+		require.Nil(t, err)
+		arguments = append(arguments, eABI.Argument{Type: ty})
+	}
+	expected, err := arguments.Pack(
+		true,
+		[]string{"TRX", "USDT"},
+		[][]byte{{1, 2}, {3, 4, 5}},
+		[][]*big.Int{{big.NewInt(1), big.NewInt(2)}, {big.NewInt(3)}},
+		[3]byte{0xab, 0xcd, 0xef},
+		big.NewInt(-5),
+		big.NewInt(1000001),
+		uint64(math.MaxUint64),
+		[]common.Address{addr},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(b))
+}
+
+// TestABIParamTuple round-trips nested tuples given as JSON objects and arrays through go-ethereum.
+func TestABIParamTuple(t *testing.T) {
+	typ := "(address to,uint256 amount,(bool ok,bytes32[] proofs)[] items,string memo)"
+	param, err := LoadFromJSON(`
+	[
+		{"` + typ + `": {
+			"to": "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ",
+			"amount": "1000000",
+			"items": [
+				{"ok": true, "proofs": ["0x0000000000000000000000000000000000000000000000000000000000000001"]},
+				[false, []]
+			],
+			"memo": "hello"
+		}},
+		{"uint8": 7}
+	]
+	`)
+	require.Nil(t, err)
+	b, err := GetPaddedParam(param)
+	require.Nil(t, err)
+
+	ty, err := NewType(typ)
+	require.Nil(t, err)
+	assert.Equal(t, "(address,uint256,(bool,bytes32[])[],string)", ty.String())
+	uint8Ty, _ := eABI.NewType("uint8", "", nil)
+	arguments := eABI.Arguments{{Type: ty}, {Type: uint8Ty}}
+
+	values, err := arguments.Unpack(b)
+	require.Nil(t, err)
+	tuple := reflect.ValueOf(values[0])
+	assert.Equal(t, "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", ToTronAddress(tuple.Field(0).Interface().(common.Address)))
+	assert.Equal(t, big.NewInt(1000000), tuple.Field(1).Interface())
+	items := tuple.Field(2)
+	require.Equal(t, 2, items.Len())
+	assert.Equal(t, true, items.Index(0).Field(0).Interface())
+	assert.Equal(t, 1, items.Index(0).Field(1).Len())
+	assert.Equal(t, false, items.Index(1).Field(0).Interface())
+	assert.Equal(t, "hello", tuple.Field(3).Interface())
+	assert.Equal(t, uint8(7), values[1])
+
+	repacked, err := arguments.Pack(values...)
+	require.Nil(t, err)
+	assert.Equal(t, b, repacked)
+}
+
+// TestABIParamErrors checks that invalid values are rejected.
+func TestABIParamErrors(t *testing.T) {
+	for _, p := range []Param{
+		{"uint8": "256"},
+		{"uint256": "-1"},
+		{"int8": -129},
+		{"bytes4": "0x0102"},
+		{"uint256[2]": []string{"1"}},
+		{"(uint256 a,bool b)": map[string]any{"a": "1"}},
+		{"bool": "maybe"},
+	} {
+		_, err := GetPaddedParam([]Param{p})
+		assert.NotNil(t, err, "%v", p)
+	}
+}
@@ -0,0 +1,341 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TronAddressPrefix is the first byte of every TRON address.
+const TronAddressPrefix = 0x41
+
+// Argument is a decoded method argument.
+type Argument struct {
+	Name  string
+	Type  string
+	Value any
+}
+
+// MethodCall is a decoded contract call.
+type MethodCall struct {
+	Name      string
+	Signature string
+	Args      []Argument
+}
+
+// jsonEntry mirrors the Ethereum JSON ABI format.
+type jsonEntry struct {
+	Type            string      `json:"type"`
+	Name            string      `json:"name,omitempty"`
+	Inputs          []jsonParam `json:"inputs"`
+	Outputs         []jsonParam `json:"outputs"`
+	StateMutability string      `json:"stateMutability,omitempty"`
+	Anonymous       bool        `json:"anonymous,omitempty"`
+}
+
+// solidityEntry is an entry of the ABI JSON taken by the node, with the TRON
+// flags of older compilers.
+type solidityEntry struct {
+	jsonEntry
+	Constant bool `json:"constant,omitempty"`
+	Payable  bool `json:"payable,omitempty"`
+}
+
+type jsonParam struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed,omitempty"`
+}
+
+// ParseContractABI converts a TRON contract ABI into a go-ethereum ABI.
+// trcToken parameters are encoded as uint256, but keep their TRON name in the
+// signatures, so that selectors and event topics match the ones of the node.
+// Entries whose types cannot be represented, such as tuples without components,
+// are skipped.
+func ParseContractABI(contractABI *core.SmartContract_ABI) (*eABI.ABI, error) {
+	parsed := &eABI.ABI{
+		Methods: make(map[string]eABI.Method),
+		Events:  make(map[string]eABI.Event),
+		Errors:  make(map[string]eABI.Error),
+	}
+	for _, e := range contractABI.GetEntrys() {
+		entry, ok := convertEntry(e)
+		if !ok {
+			continue
+		}
+		// Parse each entry on its own so that one bad entry does not hide the others.
+		single, err := parseJSONEntries([]jsonEntry{entry})
+		if err != nil {
+			continue
+		}
+		params := e.GetInputs()
+		switch entry.Type {
+		case "constructor":
+			parsed.Constructor = single.Constructor
+		case "fallback":
+			if !parsed.HasFallback() {
+				parsed.Fallback = single.Fallback
+			}
+		case "receive":
+			if !parsed.HasReceive() {
+				parsed.Receive = single.Receive
+			}
+		case "function":
+			m := single.Methods[entry.Name]
+			m.Sig = tronSignature(m.RawName, m.Inputs, params)
+			m.ID = crypto.Keccak256([]byte(m.Sig))[:4]
+			m.Name = eABI.ResolveNameConflict(m.RawName, func(s string) bool { _, ok := parsed.Methods[s]; return ok })
+			parsed.Methods[m.Name] = m
+		case "event":
+			ev := single.Events[entry.Name]
+			ev.Sig = tronSignature(ev.RawName, ev.Inputs, params)
+			ev.ID = crypto.Keccak256Hash([]byte(ev.Sig))
+			ev.Name = eABI.ResolveNameConflict(ev.RawName, func(s string) bool { _, ok := parsed.Events[s]; return ok })
+			parsed.Events[ev.Name] = ev
+		case "error":
+			er := single.Errors[entry.Name]
+			er.Sig = tronSignature(er.Name, er.Inputs, params)
+			er.ID = crypto.Keccak256Hash([]byte(er.Sig))
+			parsed.Errors[er.Name] = er
+		}
+	}
+	return parsed, nil
+}
+
+// MarshalContractABI encodes a TRON contract ABI as the Solidity JSON ABI taken
+// by the deploy endpoint of the node, with lowercase types such as "function"
+// and "nonpayable". TRON param types such as trcToken are kept. Entries of
+// unknown type are skipped.
+func MarshalContractABI(contractABI *core.SmartContract_ABI) ([]byte, error) {
+	entries := make([]solidityEntry, 0, len(contractABI.GetEntrys()))
+	for _, e := range contractABI.GetEntrys() {
+		entry, ok := convertEntry(e)
+		if !ok {
+			continue
+		}
+		for i, p := range e.GetInputs() {
+			entry.Inputs[i].Type = p.GetType()
+		}
+		for i, p := range e.GetOutputs() {
+			entry.Outputs[i].Type = p.GetType()
+		}
+		entries = append(entries, solidityEntry{jsonEntry: entry, Constant: e.GetConstant(), Payable: e.GetPayable()})
+	}
+	return json.Marshal(entries)
+}
+
+// tronSignature returns the signature of a method, event or error with the
+// TRON types of its params: go-ethereum only knows trcToken as uint256.
+func tronSignature(name string, args eABI.Arguments, params []*core.SmartContract_ABI_Entry_Param) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = arg.Type.String()
+		if i < len(params) && strings.HasPrefix(params[i].GetType(), "trcToken") {
+			types[i] = "trcToken" + strings.TrimPrefix(types[i], "uint256")
+		}
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(types, ","))
+}
+
+// parseJSONEntries parses entries through the go-ethereum JSON ABI parser.
+func parseJSONEntries(entries []jsonEntry) (*eABI.ABI, error) {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ABI: %w", err)
+	}
+	parsed, err := eABI.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	return &parsed, nil
+}
+
+// convertEntry converts a TRON ABI entry into its Ethereum JSON form.
+func convertEntry(e *core.SmartContract_ABI_Entry) (jsonEntry, bool) {
+	entry := jsonEntry{
+		Name:      e.GetName(),
+		Inputs:    convertParams(e.GetInputs()),
+		Outputs:   convertParams(e.GetOutputs()),
+		Anonymous: e.GetAnonymous(),
+	}
+
+	switch e.GetType() {
+	case core.SmartContract_ABI_Entry_Constructor:
+		entry.Type = "constructor"
+	case core.SmartContract_ABI_Entry_Function:
+		entry.Type = "function"
+	case core.SmartContract_ABI_Entry_Event:
+		entry.Type = "event"
+		return entry, true
+	case core.SmartContract_ABI_Entry_Fallback:
+		entry.Type = "fallback"
+	case core.SmartContract_ABI_Entry_Receive:
+		entry.Type = "receive"
+	case core.SmartContract_ABI_Entry_Error:
+		entry.Type = "error"
+		return entry, true
+	default:
+		return entry, false
+	}
+
+	switch e.GetStateMutability() {
+	case core.SmartContract_ABI_Entry_Pure:
+		entry.StateMutability = "pure"
+	case core.SmartContract_ABI_Entry_View:
+		entry.StateMutability = "view"
+	case core.SmartContract_ABI_Entry_Payable:
+		entry.StateMutability = "payable"
+	case core.SmartContract_ABI_Entry_Nonpayable:
+		entry.StateMutability = "nonpayable"
+	default:
+		switch {
+		case e.GetPayable():
+			entry.StateMutability = "payable"
+		case e.GetConstant():
+			entry.StateMutability = "view"
+		default:
+			entry.StateMutability = "nonpayable"
+		}
+	}
+	return entry, true
+}
+
+// convertParams converts TRON ABI params, mapping trcToken to uint256 for the encoding.
+func convertParams(params []*core.SmartContract_ABI_Entry_Param) []jsonParam {
+	out := make([]jsonParam, 0, len(params))
+	for _, p := range params {
+		out = append(out, jsonParam{
+			Name:    p.GetName(),
+			Type:    strings.ReplaceAll(p.GetType(), "trcToken", "uint256"),
+			Indexed: p.GetIndexed(),
+		})
+	}
+	return out
+}
+
+// DecodeCall decodes call data (selector and arguments) using the contract ABI.
+// Address arguments are returned as base58 TRON addresses.
+func DecodeCall(contractABI *core.SmartContract_ABI, data []byte) (*MethodCall, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("call data too short: %d bytes", len(data))
+	}
+	parsed, err := ParseContractABI(contractABI)
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack arguments of %s: %w", method.Sig, err)
+	}
+
+	call := &MethodCall{Name: method.RawName, Signature: method.Sig}
+	for i, input := range method.Inputs {
+		call.Args = append(call.Args, Argument{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: ToTronValue(values[i]),
+		})
+	}
+	return call, nil
+}
+
+// ToTronAddress converts a 20-byte Ethereum-style address into a base58 TRON address.
+func ToTronAddress(addr common.Address) string {
+	return base58.EncodeCheck(append([]byte{TronAddressPrefix}, addr.Bytes()...))
+}
+
+// ToEthAddress converts a base58 TRON address into a 20-byte Ethereum-style address.
+func ToEthAddress(addr string) (common.Address, error) {
+	return convertToAddress(addr)
+}
+
+// ToTronValue converts decoded ABI values so that addresses are base58 TRON
+// addresses, including addresses inside arrays and tuples. Address arrays become
+// []string, other arrays holding addresses []any, and tuples holding addresses
+// map[string]any keyed by component name. Values without addresses are returned as is.
+func ToTronValue(v any) any {
+	if v == nil || !hasAddress(reflect.TypeOf(v)) {
+		return v
+	}
+	return toTronValue(reflect.ValueOf(v))
+}
+
+var addressType = reflect.TypeOf(common.Address{})
+
+// hasAddress reports whether values of type t hold addresses.
+func hasAddress(t reflect.Type) bool {
+	switch {
+	case t == addressType:
+		return true
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Pointer:
+		return hasAddress(t.Elem())
+	case t.Kind() == reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasAddress(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toTronValue(v reflect.Value) any {
+	if !hasAddress(v.Type()) {
+		return v.Interface()
+	}
+	if v.Type() == addressType {
+		return ToTronAddress(v.Interface().(common.Address))
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if v.Type().Elem() == addressType {
+			out := make([]string, v.Len())
+			for i := range out {
+				out[i] = ToTronAddress(v.Index(i).Interface().(common.Address))
+			}
+			return out
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = toTronValue(v.Index(i))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return toTronValue(v.Elem())
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			name := f.Name
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" {
+				name = tag
+			}
+			out[name] = toTronValue(v.Field(i))
+		}
+		return out
+	}
+	return v.Interface()
+}
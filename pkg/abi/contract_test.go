@@ -0,0 +1,120 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/dszi/go-tron/pb/core"
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var trcTokenABI = &core.SmartContract_ABI{Entrys: []*core.SmartContract_ABI_Entry{
+	{
+		Type: core.SmartContract_ABI_Entry_Function,
+		Name: "transferTokenTo",
+		Inputs: []*core.SmartContract_ABI_Entry_Param{
+			{Name: "to", Type: "address"},
+			{Name: "id", Type: "trcToken"},
+			{Name: "amount", Type: "uint256"},
+		},
+		StateMutability: core.SmartContract_ABI_Entry_Payable,
+	},
+	{
+		Type: core.SmartContract_ABI_Entry_Function,
+		Name: "transferTokenTo",
+		Inputs: []*core.SmartContract_ABI_Entry_Param{
+			{Name: "to", Type: "address"},
+			{Name: "ids", Type: "trcToken[]"},
+		},
+		StateMutability: core.SmartContract_ABI_Entry_Payable,
+	},
+	{
+		Type: core.SmartContract_ABI_Entry_Event,
+		Name: "Deposit",
+		Inputs: []*core.SmartContract_ABI_Entry_Param{
+			{Name: "from", Type: "address", Indexed: true},
+			{Name: "id", Type: "trcToken"},
+			{Name: "amount", Type: "uint256"},
+		},
+	},
+}}
+
+// TestParseContractABITrcToken checks that trcToken params keep their TRON name in selectors.
+func TestParseContractABITrcToken(t *testing.T) {
+	parsed, err := ParseContractABI(trcTokenABI)
+	require.Nil(t, err)
+
+	method, _, err := ResolveMethod(parsed, "transferTokenTo(address,trcToken,uint256)", []any{"TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", 1000001, 5})
+	require.Nil(t, err)
+	// keccak256("transferTokenTo(address,trcToken,uint256)")[:4]
+	assert.Equal(t, "d4d64226", hex.EncodeToString(method.ID))
+
+	data, err := PackMethod(parsed, "transferTokenTo", "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", 1000001, 5)
+	require.Nil(t, err)
+	expected, err := Pack("transferTokenTo(address,trcToken,uint256)", []Param{
+		{"address": "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"}, {"trcToken": "1000001"}, {"uint256": "5"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	call, err := DecodeCall(trcTokenABI, data)
+	require.Nil(t, err)
+	assert.Equal(t, "transferTokenTo(address,trcToken,uint256)", call.Signature)
+	assert.Equal(t, "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", call.Args[0].Value)
+	assert.Equal(t, big.NewInt(1000001), call.Args[1].Value)
+
+	data, err = PackMethod(parsed, "transferTokenTo", "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", []any{1000001})
+	require.Nil(t, err)
+	call, err = DecodeCall(trcTokenABI, data)
+	require.Nil(t, err)
+	assert.Equal(t, "transferTokenTo(address,trcToken[])", call.Signature)
+
+	// keccak256("Deposit(address,trcToken,uint256)")
+	assert.Equal(t, "64b2e2f7", hex.EncodeToString(parsed.Events["Deposit"].ID.Bytes()[:4]))
+}
+
+// TestToTronValueNested checks that addresses inside tuples and fixed arrays are converted.
+func TestToTronValueNested(t *testing.T) {
+	addr, err := ToEthAddress("TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ")
+	require.Nil(t, err)
+	tuple, err := eABI.NewType("tuple", "", []eABI.ArgumentMarshaling{
+		{Name: "owner", Type: "address"},
+		{Name: "spenders", Type: "address[2]"},
+		{Name: "amount", Type: "uint256"},
+	})
+	require.Nil(t, err)
+	pairs, err := eABI.NewType("address[2][]", "", nil)
+	require.Nil(t, err)
+	args := eABI.Arguments{{Type: tuple}, {Type: pairs}}
+
+	data, err := args.Pack(
+		struct {
+			Owner    [20]byte
+			Spenders [2][20]byte
+			Amount   *big.Int
+		}{addr, [2][20]byte{addr, addr}, big.NewInt(7)},
+		[][2][20]byte{{addr, addr}},
+	)
+	require.Nil(t, err)
+	values, err := args.Unpack(data)
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]any{
+		"owner":    "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ",
+		"spenders": []string{"TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"},
+		"amount":   big.NewInt(7),
+	}, ToTronValue(values[0]))
+	assert.Equal(t, []any{[]string{"TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"}}, ToTronValue(values[1]))
+	assert.Equal(t, big.NewInt(7), ToTronValue(big.NewInt(7)))
+}
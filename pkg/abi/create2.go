@@ -0,0 +1,50 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"fmt"
+
+	"github.com/dszi/go-tron/common/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+// Create2Address computes the base58 address of a contract deployed with CREATE2
+// by the factory at a base58 address. The TVM hashes the 21-byte factory address,
+// starting with the 0x41 prefix where the EVM uses 0xff, with the salt and the
+// Keccak-256 hash of the init code (creation bytecode and constructor arguments).
+func Create2Address(factory string, salt [32]byte, initCode []byte) (string, error) {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(initCode)
+	var codeHash [32]byte
+	copy(codeHash[:], hasher.Sum(nil))
+	return Create2AddressFromHash(factory, salt, codeHash)
+}
+
+// Create2AddressFromHash is like Create2Address with the Keccak-256 hash of the init code.
+func Create2AddressFromHash(factory string, salt [32]byte, initCodeHash [32]byte) (string, error) {
+	factoryAddr, err := ToEthAddress(factory)
+	if err != nil {
+		return "", fmt.Errorf("invalid factory address: %w", err)
+	}
+
+	addr := create2(TronAddressPrefix, factoryAddr.Bytes(), salt, initCodeHash)
+	return base58.EncodeCheck(append([]byte{TronAddressPrefix}, addr...)), nil
+}
+
+// create2 returns the 20-byte address derived from the prefix, the 20-byte
+// factory address, the salt and the init code hash.
+func create2(prefix byte, factory []byte, salt [32]byte, initCodeHash [32]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte{prefix})
+	hasher.Write(factory)
+	hasher.Write(salt[:])
+	hasher.Write(initCodeHash[:])
+	return hasher.Sum(nil)[12:]
+}
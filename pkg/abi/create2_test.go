@@ -0,0 +1,59 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreate2Vectors checks the derivation with the 0xff prefix of the EVM
+// against the examples of EIP-1014; the TVM only changes the prefix.
+func TestCreate2Vectors(t *testing.T) {
+	for _, v := range []struct {
+		factory, salt, initCode, address string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x00", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00", "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0xdeadbeef", "0x70f2b2914A2a4b783FaEFb75f459A580616Fcb5e"},
+		{"0x00000000000000000000000000000000deadbeef", "0x00000000000000000000000000000000000000000000000000000000cafebabe", "0xdeadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x", "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0"},
+	} {
+		salt := common.BytesToHash(common.FromHex(v.salt))
+		codeHash := crypto.Keccak256Hash(common.FromHex(v.initCode))
+		addr := create2(0xff, common.HexToAddress(v.factory).Bytes(), salt, codeHash)
+		assert.Equal(t, common.HexToAddress(v.address), common.BytesToAddress(addr), v.address)
+	}
+}
+
+func TestCreate2Address(t *testing.T) {
+	factory := "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"
+	factoryAddr, err := ToEthAddress(factory)
+	require.Nil(t, err)
+	var salt [32]byte
+	salt[31] = 1
+	initCode := common.FromHex("0x6080604052348015600f57600080fd5b50")
+
+	addr, err := Create2Address(factory, salt, initCode)
+	require.Nil(t, err)
+	fromHash, err := Create2AddressFromHash(factory, salt, crypto.Keccak256Hash(initCode))
+	require.Nil(t, err)
+	assert.Equal(t, addr, fromHash)
+
+	// The EVM prefix 0xff gives a different address.
+	assert.NotEqual(t, ToTronAddress(crypto.CreateAddress2(factoryAddr, salt, crypto.Keccak256(initCode))), addr)
+
+	_, err = Create2Address("invalid", salt, initCode)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,125 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// ResolveMethod finds the method called with the given Go arguments and converts
+// the arguments to the types of its inputs. The name may be a plain method name,
+// in which case overloads are resolved from the arguments, or a full signature
+// such as "transfer(address,uint256)". Arguments are converted as by GetPaddedParam,
+// so addresses may be base58 strings and integers may be strings or any Go integer.
+// When several overloads accept the arguments, the one matching the most argument
+// types exactly is chosen.
+func ResolveMethod(contractABI *eABI.ABI, name string, args []any) (*eABI.Method, []any, error) {
+	var candidates []eABI.Method
+	for _, m := range contractABI.Methods {
+		if m.RawName == name || m.Sig == name {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("method %s not found in ABI", name)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Sig < candidates[j].Sig })
+
+	var (
+		best      *eABI.Method
+		bestArgs  []any
+		bestScore = -1
+		ambiguous []string
+		mismatch  []string
+	)
+	for i := range candidates {
+		m := &candidates[i]
+		if len(m.Inputs) != len(args) {
+			mismatch = append(mismatch, fmt.Sprintf("%s: expected %d arguments but got %d", m.Sig, len(m.Inputs), len(args)))
+			continue
+		}
+		values, score, err := convertArguments(m.Inputs, args)
+		if err != nil {
+			mismatch = append(mismatch, fmt.Sprintf("%s: %v", m.Sig, err))
+			continue
+		}
+		switch {
+		case score > bestScore:
+			best, bestArgs, bestScore, ambiguous = m, values, score, []string{m.Sig}
+		case score == bestScore:
+			ambiguous = append(ambiguous, m.Sig)
+		}
+	}
+
+	if best == nil {
+		return nil, nil, fmt.Errorf("no overload of %s accepts the arguments: %s", name, strings.Join(mismatch, "; "))
+	}
+	if len(ambiguous) > 1 {
+		return nil, nil, fmt.Errorf("ambiguous call to %s, matching %s; use the full signature", name, strings.Join(ambiguous, ", "))
+	}
+	return best, bestArgs, nil
+}
+
+// PackMethod encodes a call of a method with Go arguments, resolved with ResolveMethod.
+func PackMethod(contractABI *eABI.ABI, name string, args ...any) ([]byte, error) {
+	method, values, err := ResolveMethod(contractABI, name, args)
+	if err != nil {
+		return nil, err
+	}
+	data, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", method.Sig, err)
+	}
+	return append(append([]byte{}, method.ID...), data...), nil
+}
+
+// convertArguments converts the arguments to the input types. The score counts
+// the arguments whose Go type already matched the input type.
+func convertArguments(inputs eABI.Arguments, args []any) ([]any, int, error) {
+	values := make([]any, len(args))
+	score := 0
+	for i, input := range inputs {
+		if args[i] != nil && reflect.TypeOf(args[i]) == input.Type.GetType() {
+			score++
+		}
+		v, err := convertValue(input.Type, args[i])
+		if err != nil {
+			name := input.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, 0, fmt.Errorf("argument %s (%s): %w", name, input.Type, err)
+		}
+		values[i] = v
+	}
+	return values, score, nil
+}
+
+// PackConstructor encodes constructor arguments, to be appended to the contract bytecode.
+// Arguments are converted as by ResolveMethod.
+func PackConstructor(contractABI *eABI.ABI, args ...any) ([]byte, error) {
+	inputs := contractABI.Constructor.Inputs
+	if len(inputs) != len(args) {
+		return nil, fmt.Errorf("constructor expects %d arguments but got %d", len(inputs), len(args))
+	}
+	values, _, err := convertArguments(inputs, args)
+	if err != nil {
+		return nil, fmt.Errorf("constructor: %w", err)
+	}
+	data, err := inputs.Pack(values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode constructor arguments: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,64 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const overloadedABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"type":"bool"}]},
+	{"type":"function","name":"set","inputs":[{"name":"value","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"set","inputs":[{"name":"value","type":"uint64"}],"outputs":[]},
+	{"type":"function","name":"set","inputs":[{"name":"value","type":"string"}],"outputs":[]},
+	{"type":"function","name":"set","inputs":[{"name":"key","type":"string"},{"name":"value","type":"uint256"}],"outputs":[]}
+]`
+
+// TestResolveMethod checks overload resolution and argument type checking.
+func TestResolveMethod(t *testing.T) {
+	parsed, err := eABI.JSON(strings.NewReader(overloadedABI))
+	require.Nil(t, err)
+
+	data, err := PackMethod(&parsed, "transfer", "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ", 1000)
+	require.Nil(t, err)
+	expected, err := Pack("transfer(address,uint256)", []Param{{"address": "TRGhNNfnmgLegT4zHNjEqDSADjgmnHvubJ"}, {"uint256": "1000"}})
+	require.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	method, _, err := ResolveMethod(&parsed, "set", []any{big.NewInt(1)})
+	require.Nil(t, err)
+	assert.Equal(t, "set(uint256)", method.Sig)
+
+	method, _, err = ResolveMethod(&parsed, "set", []any{"name"})
+	require.Nil(t, err)
+	assert.Equal(t, "set(string)", method.Sig)
+
+	method, _, err = ResolveMethod(&parsed, "set", []any{"name", 5})
+	require.Nil(t, err)
+	assert.Equal(t, "set(string,uint256)", method.Sig)
+
+	_, _, err = ResolveMethod(&parsed, "set", []any{5})
+	assert.ErrorContains(t, err, "ambiguous")
+
+	method, _, err = ResolveMethod(&parsed, "set(uint256)", []any{5})
+	require.Nil(t, err)
+	assert.Equal(t, "set(uint256)", method.Sig)
+
+	_, _, err = ResolveMethod(&parsed, "transfer", []any{"not an address", 1})
+	assert.ErrorContains(t, err, "argument to (address)")
+
+	_, _, err = ResolveMethod(&parsed, "approve", nil)
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,163 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package abi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+var (
+	arraySuffixRegex = regexp.MustCompile(`^(\[[0-9]*\])*$`)
+	aliasTypeRegex   = regexp.MustCompile(`^(uint|int|byte|trcToken)((?:\[[0-9]*\])*)$`)
+)
+
+// typeAliases maps the shorthand and TRON-specific types to their canonical ABI types.
+var typeAliases = map[string]string{
+	"uint":     "uint256",
+	"int":      "int256",
+	"byte":     "bytes1",
+	"trcToken": "uint256",
+}
+
+// NewType parses a Solidity type into a go-ethereum ABI type.
+// Besides the elementary types it accepts trcToken, which is encoded as uint256,
+// and tuples written as "(address,uint256)" or "tuple(address to,uint256 value)[]".
+// Tuple component names are the keys of the JSON objects packed into the tuple;
+// unnamed components are called field0, field1, ...
+func NewType(s string) (eABI.Type, error) {
+	typ, components, err := parseTypeString(strings.TrimSpace(s))
+	if err != nil {
+		return eABI.Type{}, fmt.Errorf("invalid type %s: %w", s, err)
+	}
+	return eABI.NewType(typ, "", components)
+}
+
+// parseTypeString splits a type into its go-ethereum type string and tuple components.
+func parseTypeString(s string) (string, []eABI.ArgumentMarshaling, error) {
+	if strings.HasPrefix(s, "tuple(") {
+		s = s[len("tuple"):]
+	}
+	if !strings.HasPrefix(s, "(") {
+		if m := aliasTypeRegex.FindStringSubmatch(s); m != nil {
+			return typeAliases[m[1]] + m[2], nil, nil
+		}
+		return s, nil, nil
+	}
+
+	end := matchingParen(s)
+	if end < 0 {
+		return "", nil, fmt.Errorf("unbalanced parentheses")
+	}
+	suffix := s[end+1:]
+	if !arraySuffixRegex.MatchString(suffix) {
+		return "", nil, fmt.Errorf("unexpected %q after tuple", suffix)
+	}
+
+	var components []eABI.ArgumentMarshaling
+	for i, part := range splitComponents(s[1:end]) {
+		typ, name := splitTypeName(strings.TrimSpace(part))
+		if typ == "" {
+			return "", nil, fmt.Errorf("empty tuple component")
+		}
+		if name == "" {
+			name = fmt.Sprintf("field%d", i)
+		}
+		t, c, err := parseTypeString(typ)
+		if err != nil {
+			return "", nil, err
+		}
+		components = append(components, eABI.ArgumentMarshaling{Name: name, Type: t, Components: c})
+	}
+	return "tuple" + suffix, components, nil
+}
+
+// matchingParen returns the index of the parenthesis closing the one at s[0], or -1.
+func matchingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitComponents splits a tuple body at its top-level commas.
+func splitComponents(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// splitTypeName splits a tuple component such as "uint256[] amounts" into its type and name.
+// Data location keywords such as "memory" are ignored.
+func splitTypeName(s string) (string, string) {
+	var typ, rest string
+	if strings.HasPrefix(s, "(") || strings.HasPrefix(s, "tuple(") {
+		open := strings.IndexByte(s, '(')
+		end := matchingParen(s[open:])
+		if end < 0 {
+			return s, ""
+		}
+		end += open + 1
+		for end < len(s) && strings.IndexByte("[]0123456789", s[end]) >= 0 {
+			end++
+		}
+		typ, rest = s[:end], s[end:]
+	} else {
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			return "", ""
+		}
+		typ, rest = fields[0], strings.Join(fields[1:], " ")
+	}
+
+	name := ""
+	for _, f := range strings.Fields(rest) {
+		if !typeKeywords[f] {
+			name = f
+		}
+	}
+	return typ, name
+}
+
+// typeKeywords are modifiers that may appear between a type and its name.
+var typeKeywords = map[string]bool{
+	"memory":   true,
+	"calldata": true,
+	"storage":  true,
+	"indexed":  true,
+	"payable":  true,
+}
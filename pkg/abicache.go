@@ -0,0 +1,182 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	eABI "github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// ABICache fetches contract ABIs with GetContractABI and keeps them for later calls.
+// Its GetContractABI method can be used as an ABIResolver.
+type ABICache struct {
+	client TronClient
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*abiCacheEntry
+	clears  map[string]pendingClear
+}
+
+type abiCacheEntry struct {
+	raw    *core.SmartContract_ABI
+	parsed *eABI.ABI
+}
+
+// pendingClear is a ClearABIContract transaction not known to be confirmed yet.
+type pendingClear struct {
+	txid       string
+	expiration time.Time
+	checked    time.Time // last receipt lookup
+}
+
+// NewABICache creates an empty cache backed by the client.
+func NewABICache(client TronClient) *ABICache {
+	return &ABICache{
+		client:  client,
+		now:     time.Now,
+		entries: make(map[string]*abiCacheEntry),
+		clears:  make(map[string]pendingClear),
+	}
+}
+
+// ExpectClear drops the cached ABI of a contract once the ClearABIContract
+// transaction txid is confirmed. Until then the ABI on chain is unchanged and
+// stays cached. The expectation is forgotten when the transaction fails or
+// expires without being included in a block.
+func (c *ABICache) ExpectClear(contractAddress, txid string, expiration time.Time) {
+	c.mu.Lock()
+	c.clears[contractAddress] = pendingClear{txid: txid, expiration: expiration}
+	c.mu.Unlock()
+}
+
+// checkClear drops the cached ABI of a contract when its pending clear is
+// confirmed. The receipt is looked up at most once per block.
+func (c *ABICache) checkClear(contractAddress string) {
+	now := c.now()
+	c.mu.Lock()
+	clear, ok := c.clears[contractAddress]
+	if !ok || now.Sub(clear.checked) < BlockInterval {
+		c.mu.Unlock()
+		return
+	}
+	clear.checked = now
+	c.clears[contractAddress] = clear
+	c.mu.Unlock()
+
+	info, err := c.client.GetTransactionInfoByID(clear.txid)
+	included := err == nil && info.GetBlockNumber() > 0
+	if !included && now.Before(clear.expiration) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clears[contractAddress].txid != clear.txid {
+		return
+	}
+	delete(c.clears, contractAddress)
+	if included && info.GetResult() != core.TransactionInfo_FAILED {
+		delete(c.entries, contractAddress)
+	}
+}
+
+// entry returns the cached ABI of a contract, fetching it on first use.
+// Failures are not cached.
+func (c *ABICache) entry(contractAddress string) (*abiCacheEntry, error) {
+	c.checkClear(contractAddress)
+	c.mu.Lock()
+	e, ok := c.entries[contractAddress]
+	c.mu.Unlock()
+	if ok {
+		return e, nil
+	}
+
+	raw, err := c.client.GetContractABI(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.GetEntrys()) == 0 {
+		return nil, fmt.Errorf("contract %s has no ABI", contractAddress)
+	}
+	parsed, err := abi.ParseContractABI(raw)
+	if err != nil {
+		return nil, fmt.Errorf("contract %s: %w", contractAddress, err)
+	}
+
+	e = &abiCacheEntry{raw: raw, parsed: parsed}
+	c.mu.Lock()
+	c.entries[contractAddress] = e
+	c.mu.Unlock()
+	return e, nil
+}
+
+// GetContractABI returns the ABI of a contract as stored on chain.
+func (c *ABICache) GetContractABI(contractAddress string) (*core.SmartContract_ABI, error) {
+	e, err := c.entry(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return e.raw, nil
+}
+
+// Parsed returns the ABI of a contract in the go-ethereum form.
+func (c *ABICache) Parsed(contractAddress string) (*eABI.ABI, error) {
+	e, err := c.entry(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return e.parsed, nil
+}
+
+// Invalidate drops the cached ABI of a contract, e.g. after its ABI was cleared or updated.
+func (c *ABICache) Invalidate(contractAddress string) {
+	c.mu.Lock()
+	delete(c.entries, contractAddress)
+	c.mu.Unlock()
+}
+
+// Pack encodes a call of a contract method with Go arguments, resolving
+// overloads and checking the arguments against the contract ABI.
+// See abi.ResolveMethod for the accepted arguments.
+func (c *ABICache) Pack(contractAddress, method string, args ...any) ([]byte, error) {
+	parsed, err := c.Parsed(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return abi.PackMethod(parsed, method, args...)
+}
+
+// newTriggerMethodContract encodes a method call with the contract ABI and builds the trigger contract.
+func newTriggerMethodContract(abis *ABICache, from, contractAddress string, callValue int64, method string, args []any) (*core.TriggerSmartContract, error) {
+	fromDesc, err := base58.DecodeCheck(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	data, err := abis.Pack(contractAddress, method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode method call: %w", err)
+	}
+	return &core.TriggerSmartContract{
+		OwnerAddress:    fromDesc,
+		ContractAddress: contractDesc,
+		Data:            data,
+		CallValue:       callValue,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeABIClient struct {
+	TronClient
+	calls   int
+	lookups int
+	// receipts maps transaction IDs to their receipt.
+	receipts map[string]*core.TransactionInfo
+}
+
+func (f *fakeABIClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	f.lookups++
+	if info, ok := f.receipts[id]; ok {
+		return info, nil
+	}
+	return nil, fmt.Errorf("GetTransactionInfoByID: %w", ErrTransactionInfoNotFound)
+}
+
+func (f *fakeABIClient) GetContractABI(string) (*core.SmartContract_ABI, error) {
+	f.calls++
+	return trc20TransferABI, nil
+}
+
+func TestABICachePack(t *testing.T) {
+	contract, to := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9"
+	client := &fakeABIClient{}
+	cache := NewABICache(client)
+
+	data, err := cache.Pack(contract, "transfer", to, uint64(1000000))
+	require.Nil(t, err)
+	expected, err := abi.Pack("transfer(address,uint256)", []abi.Param{{"address": to}, {"uint256": "1000000"}})
+	require.Nil(t, err)
+	assert.Equal(t, expected, data)
+
+	_, err = cache.Pack(contract, "transfer", to)
+	assert.ErrorContains(t, err, "expected 2 arguments but got 1")
+	assert.Equal(t, 1, client.calls)
+
+	cache.Invalidate(contract)
+	_, err = cache.GetContractABI(contract)
+	require.Nil(t, err)
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestABICacheExpectClear(t *testing.T) {
+	contract := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	client := &fakeABIClient{receipts: make(map[string]*core.TransactionInfo)}
+	cache := NewABICache(client)
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+	fetch := func() {
+		_, err := cache.GetContractABI(contract)
+		require.Nil(t, err)
+	}
+
+	fetch()
+	cache.ExpectClear(contract, "aa", now.Add(time.Minute))
+	fetch()
+	assert.Equal(t, 1, client.calls, "the ABI is kept until the clear is confirmed")
+
+	// The receipt is looked up once per block.
+	client.receipts["aa"] = &core.TransactionInfo{BlockNumber: 10}
+	fetch()
+	assert.Equal(t, 1, client.lookups)
+	assert.Equal(t, 1, client.calls)
+	now = now.Add(BlockInterval)
+	fetch()
+	fetch()
+	assert.Equal(t, 2, client.lookups)
+	assert.Equal(t, 2, client.calls)
+
+	// A failed clear keeps the ABI.
+	cache.ExpectClear(contract, "bb", now.Add(time.Minute))
+	client.receipts["bb"] = &core.TransactionInfo{BlockNumber: 11, Result: core.TransactionInfo_FAILED}
+	fetch()
+	assert.Equal(t, 2, client.calls)
+
+	// A clear never broadcast is forgotten once expired.
+	cache.ExpectClear(contract, "cc", now.Add(time.Minute))
+	now = now.Add(2 * time.Minute)
+	fetch()
+	assert.Equal(t, 2, client.calls)
+	assert.Empty(t, cache.clears)
+}
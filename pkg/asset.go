@@ -23,6 +23,30 @@ func (g *GrpcClient) CreateAssetIssue(from, name, description, abbr, urlStr stri
 	precision int32, totalSupply, startTime, endTime, freeAssetNetLimit, publicFreeAssetNetLimit int64,
 	trxNum, icoNum, voteScore int32, frozenSupply map[string]string) (*api.TransactionExtention, error) {
 
+	contract, err := newAssetIssueContract(from, name, description, abbr, urlStr, precision, totalSupply,
+		startTime, endTime, freeAssetNetLimit, publicFreeAssetNetLimit, trxNum, icoNum, voteScore, frozenSupply)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.CreateAssetIssue2(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAssetIssue RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// newAssetIssueContract validates the token parameters and builds the issue contract.
+func newAssetIssueContract(from, name, description, abbr, urlStr string,
+	precision int32, totalSupply, startTime, endTime, freeAssetNetLimit, publicFreeAssetNetLimit int64,
+	trxNum, icoNum, voteScore int32, frozenSupply map[string]string) (*core.AssetIssueContract, error) {
+
 	contract := &core.AssetIssueContract{}
 	var err error
 
@@ -87,18 +111,7 @@ func (g *GrpcClient) CreateAssetIssue(from, name, description, abbr, urlStr stri
 		}
 		contract.FrozenSupply = append(contract.FrozenSupply, frozen)
 	}
-
-	ctx, cancel := g.getContext()
-	defer cancel()
-
-	tx, err := g.Client.CreateAssetIssue2(ctx, contract)
-	if err != nil {
-		return nil, fmt.Errorf("CreateAssetIssue RPC error: %w", err)
-	}
-	if err := validateTx(tx); err != nil {
-		return nil, err
-	}
-	return tx, nil
+	return contract, nil
 }
 
 // GetAssetIssueList queries the list of all issued tokens.
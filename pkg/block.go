@@ -43,6 +43,19 @@ func (g *GrpcClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
 	return result, nil
 }
 
+// GetBlockByLimitNext retrieves the blocks with numbers in [start, end).
+func (g *GrpcClient) GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	maxSizeOption := grpc.MaxCallRecvMsgSize(32 * 10e6)
+	result, err := g.Client.GetBlockByLimitNext2(ctx, &api.BlockLimit{StartNum: start, EndNum: end}, maxSizeOption)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByLimitNext: %w", err)
+	}
+	return result, nil
+}
+
 // GetBlockByID queries block information by block ID.
 func (g *GrpcClient) GetBlockByID(id string) (*core.Block, error) {
 	blockID := &api.BytesMessage{}
@@ -74,3 +87,39 @@ func (g *GrpcClient) GetNextMaintenanceTime() (*api.NumberMessage, error) {
 	}
 	return nm, nil
 }
+
+// GetChainParameters queries the network parameters set by committee proposals.
+func (g *GrpcClient) GetChainParameters() (*core.ChainParameters, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	params, err := g.Client.GetChainParameters(ctx, new(api.EmptyMessage))
+	if err != nil {
+		return nil, fmt.Errorf("GetChainParameters error: %w", err)
+	}
+	return params, nil
+}
+
+// GetBlockReference queries the head block reference used for TaPoS.
+func (g *GrpcClient) GetBlockReference() (*api.BlockReference, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	ref, err := g.Database.GetBlockReference(ctx, new(api.EmptyMessage))
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockReference error: %w", err)
+	}
+	return ref, nil
+}
+
+// GetDynamicProperties queries the dynamic chain properties, such as the last solidified block.
+func (g *GrpcClient) GetDynamicProperties() (*core.DynamicProperties, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	props, err := g.Database.GetDynamicProperties(ctx, new(api.EmptyMessage))
+	if err != nil {
+		return nil, fmt.Errorf("GetDynamicProperties error: %w", err)
+	}
+	return props, nil
+}
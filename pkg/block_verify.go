@@ -0,0 +1,248 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/txtrie"
+	"google.golang.org/protobuf/proto"
+)
+
+// Errors returned by BlockVerifier when a block fails verification.
+var (
+	ErrBlockIDMismatch     = errors.New("block ID does not match the header")
+	ErrWitnessMismatch     = errors.New("block signature does not match the witness address")
+	ErrUnknownWitness      = errors.New("block witness is not an active super representative")
+	ErrTxTrieRootMismatch  = errors.New("transaction trie root does not match the transactions")
+	ErrTransactionMismatch = errors.New("transaction ID does not match the transaction")
+)
+
+// BlockHash returns the sha256 hash of the block header, which the witness signs.
+func BlockHash(raw *core.BlockHeaderRaw) ([]byte, error) {
+	data, err := proto.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
+
+// BlockID returns the ID of a block: the header hash with its first 8 bytes
+// replaced by the big-endian block number.
+func BlockID(raw *core.BlockHeaderRaw) ([]byte, error) {
+	id, err := BlockHash(raw)
+	if err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint64(id, uint64(raw.GetNumber()))
+	return id, nil
+}
+
+// RecoverBlockWitness returns the address that signed the block header.
+func RecoverBlockWitness(header *core.BlockHeader) (string, error) {
+	hash, err := BlockHash(header.GetRawData())
+	if err != nil {
+		return "", err
+	}
+	return recoverAddress(hash, header.GetWitnessSignature())
+}
+
+// TxTrieRoot computes the Merkle root of the transactions of a block, as
+// recorded in the block header.
+func TxTrieRoot(txs []*core.Transaction) ([]byte, error) {
+	return txtrie.Root(txs)
+}
+
+// ProveTransaction finds the block of a transaction and returns the proof of
+// its inclusion with the block header to verify it against.
+func ProveTransaction(client TronClient, txid string) (*txtrie.Proof, *core.BlockHeader, error) {
+	id, err := hex.DecodeString(txid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ProveTransaction: invalid transaction ID: %w", err)
+	}
+	info, err := client.GetTransactionInfoByID(txid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.GetBlockNumber() == 0 {
+		return nil, nil, fmt.Errorf("ProveTransaction: transaction %s is not in a block yet", txid)
+	}
+	block, err := client.GetBlockByNum(info.GetBlockNumber())
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := txtrie.New(blockTransactions(block))
+	if err != nil {
+		return nil, nil, err
+	}
+	proof, err := tree.Prove(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, block.GetBlockHeader(), nil
+}
+
+// WitnessPermissionAddress returns the address that signs the blocks of a
+// witness account: the first key of its witness permission, or the account
+// address when it has none.
+func WitnessPermissionAddress(account *core.Account) string {
+	if keys := account.GetWitnessPermission().GetKeys(); len(keys) > 0 {
+		return base58.EncodeCheck(keys[0].GetAddress())
+	}
+	return base58.EncodeCheck(account.GetAddress())
+}
+
+// ActiveWitnesses returns the witnesses currently producing blocks, as reported
+// by a trusted node, with the address signing the blocks of each.
+func ActiveWitnesses(client TronClient) (map[string]string, error) {
+	list, err := client.ListWitnesses()
+	if err != nil {
+		return nil, err
+	}
+	signers := make(map[string]string)
+	for _, w := range list.GetWitnesses() {
+		if !w.GetIsJobs() {
+			continue
+		}
+		address := base58.EncodeCheck(w.GetAddress())
+		account, err := client.GetAccount(address)
+		if err != nil {
+			return nil, fmt.Errorf("witness %s: %w", address, err)
+		}
+		signers[address] = WitnessPermissionAddress(account)
+	}
+	return signers, nil
+}
+
+// VerifiedBlock is a block that passed verification.
+type VerifiedBlock struct {
+	ID      []byte
+	Number  int64
+	Witness string
+	// Signer is the address that signed the block: the witness or its witness
+	// permission key.
+	Signer  string
+	Signers [][]string // addresses recovered from each transaction's signatures
+}
+
+// BlockVerifier verifies blocks received from untrusted nodes against a set of
+// active witnesses.
+type BlockVerifier struct {
+	witnesses map[string]string // witness address to witness permission address
+}
+
+// NewBlockVerifier creates a verifier accepting blocks signed by the given witnesses.
+func NewBlockVerifier(witnesses []string) *BlockVerifier {
+	signers := make(map[string]string, len(witnesses))
+	for _, w := range witnesses {
+		signers[w] = w
+	}
+	return NewBlockVerifierWithSigners(signers)
+}
+
+// NewBlockVerifierWithSigners creates a verifier accepting blocks of the given
+// witnesses signed by the witness itself or by the address of its witness
+// permission, as java-tron does once multi-signature is allowed.
+func NewBlockVerifierWithSigners(signers map[string]string) *BlockVerifier {
+	v := &BlockVerifier{witnesses: make(map[string]string, len(signers))}
+	for w, signer := range signers {
+		v.witnesses[w] = signer
+	}
+	return v
+}
+
+// NewBlockVerifierFromClient creates a verifier accepting blocks of the
+// witnesses currently producing blocks, with their witness permission keys, as
+// reported by a trusted node.
+func NewBlockVerifierFromClient(client TronClient) (*BlockVerifier, error) {
+	signers, err := ActiveWitnesses(client)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlockVerifierWithSigners(signers), nil
+}
+
+// Verify checks the witness signature of a block against its header and the
+// active witnesses, checks the transaction trie root, and recovers the signers
+// of each transaction.
+func (v *BlockVerifier) Verify(block *core.Block) (*VerifiedBlock, error) {
+	header := block.GetBlockHeader()
+	raw := header.GetRawData()
+	if raw == nil {
+		return nil, fmt.Errorf("block has no header")
+	}
+	id, err := BlockID(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := RecoverBlockWitness(header)
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", raw.GetNumber(), err)
+	}
+	witness := base58.EncodeCheck(raw.GetWitnessAddress())
+	permission, ok := v.witnesses[witness]
+	if !ok {
+		return nil, fmt.Errorf("block %d: %w: %s", raw.GetNumber(), ErrUnknownWitness, witness)
+	}
+	if signer != witness && signer != permission {
+		return nil, fmt.Errorf("block %d: %w: signed by %s", raw.GetNumber(), ErrWitnessMismatch, signer)
+	}
+
+	root, err := TxTrieRoot(block.GetTransactions())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, raw.GetTxTrieRoot()) {
+		return nil, fmt.Errorf("block %d: %w", raw.GetNumber(), ErrTxTrieRootMismatch)
+	}
+
+	verified := &VerifiedBlock{ID: id, Number: raw.GetNumber(), Witness: witness, Signer: signer}
+	for i, tx := range block.GetTransactions() {
+		signers, err := RecoverTransactionSigners(tx)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: transaction %d: %w", raw.GetNumber(), i, err)
+		}
+		verified.Signers = append(verified.Signers, signers)
+	}
+	return verified, nil
+}
+
+// VerifyExtention verifies a block returned by the GetNowBlock and GetBlockByNum
+// queries, and checks the block and transaction IDs reported by the node.
+func (v *BlockVerifier) VerifyExtention(block *api.BlockExtention) (*VerifiedBlock, error) {
+	b := &core.Block{BlockHeader: block.GetBlockHeader()}
+	for i, tx := range block.GetTransactions() {
+		txid, err := transactionID(tx.GetTransaction().GetRawData())
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(txid, tx.GetTxid()) {
+			return nil, fmt.Errorf("transaction %d: %w", i, ErrTransactionMismatch)
+		}
+		b.Transactions = append(b.Transactions, tx.GetTransaction())
+	}
+	verified, err := v.Verify(b)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(verified.ID, block.GetBlockid()) {
+		return nil, fmt.Errorf("block %d: %w", verified.Number, ErrBlockIDMismatch)
+	}
+	return verified, nil
+}
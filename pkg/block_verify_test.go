@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// signedBlock builds a block of signed transactions produced by the witness key.
+func signedBlock(t *testing.T, witness *ecdsa.PrivateKey, signers ...*ecdsa.PrivateKey) *core.Block {
+	t.Helper()
+	block := &core.Block{}
+	for i, key := range signers {
+		tx := &core.Transaction{RawData: &core.TransactionRaw{RefBlockBytes: []byte{byte(i)}, Expiration: 1700000060000}}
+		require.Nil(t, SignTransaction(tx, key))
+		block.Transactions = append(block.Transactions, tx)
+	}
+	root, err := TxTrieRoot(block.Transactions)
+	require.Nil(t, err)
+	witnessAddr, _ := base58.DecodeCheck(PublicKeyToAddress(witness.PublicKey))
+	block.BlockHeader = &core.BlockHeader{RawData: &core.BlockHeaderRaw{
+		Number:         66_000_000,
+		Timestamp:      1700000001000,
+		ParentHash:     make([]byte, 32),
+		TxTrieRoot:     root,
+		WitnessAddress: witnessAddr,
+	}}
+	hash, err := BlockHash(block.BlockHeader.RawData)
+	require.Nil(t, err)
+	sig, err := crypto.Sign(hash, witness)
+	require.Nil(t, err)
+	sig[64] += 27
+	block.BlockHeader.WitnessSignature = sig
+	return block
+}
+
+// signHeader signs the block header with another key than the witness one.
+func signHeader(t *testing.T, block *core.Block, key *ecdsa.PrivateKey) {
+	hash, err := BlockHash(block.BlockHeader.RawData)
+	require.Nil(t, err)
+	sig, err := crypto.Sign(hash, key)
+	require.Nil(t, err)
+	block.BlockHeader.WitnessSignature = sig
+}
+
+func TestBlockID(t *testing.T) {
+	raw := &core.BlockHeaderRaw{Number: 0x0102030405, Timestamp: 1700000001000}
+	data, _ := proto.Marshal(raw)
+	hash := sha256.Sum256(data)
+
+	id, err := BlockID(raw)
+	require.Nil(t, err)
+	assert.Equal(t, "0000000102030405", hex.EncodeToString(id[:8]))
+	assert.Equal(t, hash[8:], id[8:])
+}
+
+func TestBlockVerifier(t *testing.T) {
+	witness, _ := crypto.GenerateKey()
+	alice, _ := crypto.GenerateKey()
+	bob, _ := crypto.GenerateKey()
+	verifier := NewBlockVerifier([]string{PublicKeyToAddress(witness.PublicKey)})
+
+	block := signedBlock(t, witness, alice, bob, alice)
+	verified, err := verifier.Verify(block)
+	require.Nil(t, err)
+	id, _ := BlockID(block.BlockHeader.RawData)
+	assert.Equal(t, id, verified.ID)
+	assert.Equal(t, int64(66_000_000), verified.Number)
+	assert.Equal(t, PublicKeyToAddress(witness.PublicKey), verified.Witness)
+	assert.Equal(t, [][]string{
+		{PublicKeyToAddress(alice.PublicKey)},
+		{PublicKeyToAddress(bob.PublicKey)},
+		{PublicKeyToAddress(alice.PublicKey)},
+	}, verified.Signers)
+
+	ext := &api.BlockExtention{BlockHeader: block.BlockHeader, Blockid: id}
+	for _, tx := range block.Transactions {
+		txid, _ := transactionID(tx.RawData)
+		ext.Transactions = append(ext.Transactions, &api.TransactionExtention{Transaction: tx, Txid: txid})
+	}
+	_, err = verifier.VerifyExtention(ext)
+	require.Nil(t, err)
+	ext.Blockid = make([]byte, 32)
+	_, err = verifier.VerifyExtention(ext)
+	assert.ErrorIs(t, err, ErrBlockIDMismatch)
+	ext.Transactions[1].Txid = make([]byte, 32)
+	_, err = verifier.VerifyExtention(ext)
+	assert.ErrorIs(t, err, ErrTransactionMismatch)
+
+	// A block signed by a candidate outside the active set.
+	other, _ := crypto.GenerateKey()
+	_, err = verifier.Verify(signedBlock(t, other, alice))
+	assert.ErrorIs(t, err, ErrUnknownWitness)
+
+	// A header claiming another witness than its signer.
+	forged := signedBlock(t, other, alice)
+	forged.BlockHeader.RawData.WitnessAddress, _ = base58.DecodeCheck(PublicKeyToAddress(witness.PublicKey))
+	_, err = verifier.Verify(forged)
+	assert.ErrorIs(t, err, ErrWitnessMismatch)
+
+	// A block of the witness signed by a key of its witness permission.
+	permission, _ := crypto.GenerateKey()
+	withKey := NewBlockVerifierWithSigners(map[string]string{
+		PublicKeyToAddress(witness.PublicKey): PublicKeyToAddress(permission.PublicKey),
+	})
+	delegated := signedBlock(t, witness, alice)
+	verified, err = withKey.Verify(delegated)
+	require.Nil(t, err)
+	assert.Equal(t, PublicKeyToAddress(witness.PublicKey), verified.Signer)
+	signHeader(t, delegated, permission)
+	verified, err = withKey.Verify(delegated)
+	require.Nil(t, err)
+	assert.Equal(t, PublicKeyToAddress(witness.PublicKey), verified.Witness)
+	assert.Equal(t, PublicKeyToAddress(permission.PublicKey), verified.Signer)
+	_, err = verifier.Verify(delegated)
+	assert.ErrorIs(t, err, ErrWitnessMismatch)
+	signHeader(t, delegated, other)
+	_, err = withKey.Verify(delegated)
+	assert.ErrorIs(t, err, ErrWitnessMismatch)
+
+	// A transaction dropped from the block.
+	block.Transactions = block.Transactions[:2]
+	_, err = verifier.Verify(block)
+	assert.ErrorIs(t, err, ErrTxTrieRootMismatch)
+}
+
+func TestNewBlockVerifierFromClient(t *testing.T) {
+	client := newFakeSRClient()
+	// During the turnover, the newly elected witness 27 still waits for the
+	// schedule that witness 26 is part of.
+	for _, w := range client.witnesses {
+		switch w.Address[20] {
+		case 26:
+			w.IsJobs = true
+			w.VoteCount = 0
+		case 27:
+			w.IsJobs = false
+			w.VoteCount = 2000
+		}
+	}
+	key := witnessAddress(200)
+	client.permissions = map[string][]byte{base58.EncodeCheck(witnessAddress(3)): key}
+
+	verifier, err := NewBlockVerifierFromClient(client)
+	require.Nil(t, err)
+	assert.Len(t, verifier.witnesses, SuperRepresentativeCount)
+	assert.Contains(t, verifier.witnesses, base58.EncodeCheck(witnessAddress(26)))
+	assert.NotContains(t, verifier.witnesses, base58.EncodeCheck(witnessAddress(27)))
+	assert.Equal(t, base58.EncodeCheck(key), verifier.witnesses[base58.EncodeCheck(witnessAddress(3))])
+	assert.Equal(t, base58.EncodeCheck(witnessAddress(4)), verifier.witnesses[base58.EncodeCheck(witnessAddress(4))])
+}
+
+// fakeBlockClient serves a single block by number and the receipts of its transactions.
+type fakeBlockClient struct {
+	TronClient
+	block *api.BlockExtention
+}
+
+func (f *fakeBlockClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	for _, tx := range f.block.Transactions {
+		if hex.EncodeToString(tx.Txid) == id {
+			return &core.TransactionInfo{Id: tx.Txid, BlockNumber: f.block.BlockHeader.RawData.Number}, nil
+		}
+	}
+	return &core.TransactionInfo{}, nil
+}
+
+func (f *fakeBlockClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	if num != f.block.BlockHeader.RawData.Number {
+		return nil, assert.AnError
+	}
+	return f.block, nil
+}
+
+func TestProveTransaction(t *testing.T) {
+	witness, _ := crypto.GenerateKey()
+	alice, _ := crypto.GenerateKey()
+	block := signedBlock(t, witness, alice, alice, alice)
+	ext := &api.BlockExtention{BlockHeader: block.BlockHeader}
+	for _, tx := range block.Transactions {
+		txid, _ := transactionID(tx.RawData)
+		ext.Transactions = append(ext.Transactions, &api.TransactionExtention{Transaction: tx, Txid: txid})
+	}
+	client := &fakeBlockClient{block: ext}
+
+	txid := hex.EncodeToString(ext.Transactions[2].Txid)
+	proof, header, err := ProveTransaction(client, txid)
+	require.Nil(t, err)
+	assert.Equal(t, 2, proof.Index)
+	proven, err := proof.Verify(header)
+	require.Nil(t, err)
+	assert.Equal(t, txid, hex.EncodeToString(proven))
+
+	_, _, err = ProveTransaction(client, hex.EncodeToString(make([]byte, 32)))
+	assert.ErrorContains(t, err, "not in a block yet")
+	_, _, err = ProveTransaction(client, "zz")
+	assert.ErrorContains(t, err, "invalid transaction ID")
+}
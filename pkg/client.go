@@ -11,6 +11,8 @@ package pkg
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/dszi/go-tron/pb/api"
@@ -24,15 +26,21 @@ type GrpcClient struct {
 	Address     string
 	Conn        *grpc.ClientConn
 	Client      api.WalletClient
+	Extension   api.WalletExtensionClient
+	Database    api.DatabaseClient
+	Monitor     api.MonitorClient
 	grpcTimeout time.Duration
 	opts        []grpc.DialOption
 	apiKey      string
+	abis        *ABICache
+	abisOnce    sync.Once
 }
 
-// Option defines a function type for configuring a GrpcClient.
+// Option defines a function type for configuring a GrpcClient. Options are
+// also HTTPOptions: the timeout and API key they set apply to the HTTP-based clients.
 type Option func(*GrpcClient)
 
-// WithTimeout sets the timeout for gRPC requests.
+// WithTimeout sets the timeout for requests.
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *GrpcClient) {
 		c.grpcTimeout = timeout
@@ -53,6 +61,59 @@ func WithDialOptions(opts ...grpc.DialOption) Option {
 	}
 }
 
+// HTTPOption configures the HTTP-based clients: HTTPClient, TronGridClient and JSONRPCClient.
+type HTTPOption interface {
+	applyHTTP(*httpOptions)
+}
+
+// httpOptions holds the settings of the HTTP-based clients.
+type httpOptions struct {
+	timeout    time.Duration
+	apiKey     string
+	httpClient *http.Client
+	solidity   bool
+}
+
+type httpOptionFunc func(*httpOptions)
+
+func (f httpOptionFunc) applyHTTP(o *httpOptions) {
+	f(o)
+}
+
+// applyHTTP runs the option on a GrpcClient holding the HTTP settings and keeps
+// the timeout and API key it sets.
+func (opt Option) applyHTTP(o *httpOptions) {
+	c := &GrpcClient{grpcTimeout: o.timeout, apiKey: o.apiKey}
+	opt(c)
+	o.timeout, o.apiKey = c.grpcTimeout, c.apiKey
+}
+
+// newHTTPOptions applies the options over the defaults.
+func newHTTPOptions(options []HTTPOption) *httpOptions {
+	o := &httpOptions{
+		timeout: 5 * time.Second, // default timeout
+	}
+	for _, opt := range options {
+		opt.applyHTTP(o)
+	}
+	return o
+}
+
+// WithHTTPClient sets the http.Client used by the HTTP-based clients.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return httpOptionFunc(func(o *httpOptions) {
+		o.httpClient = client
+	})
+}
+
+// WithSolidity routes queries of the HTTP client to the solidity node endpoints,
+// which only serve solidified data.
+func WithSolidity() HTTPOption {
+	return httpOptionFunc(func(o *httpOptions) {
+		o.solidity = true
+	})
+}
+
 // NewGrpcClient creates a new GrpcClient with the specified address and options.
 func NewGrpcClient(address string, options ...Option) TronClient {
 	client := &GrpcClient{
@@ -77,6 +138,9 @@ func (g *GrpcClient) Start() error {
 
 	g.Conn = conn
 	g.Client = api.NewWalletClient(conn)
+	g.Extension = api.NewWalletExtensionClient(conn)
+	g.Database = api.NewDatabaseClient(conn)
+	g.Monitor = api.NewMonitorClient(conn)
 	return nil
 }
 
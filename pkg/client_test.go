@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// setupGrpcClient connects to the node in GO_TRON_TEST_NODE (Nile by default)
+// and skips the test when it cannot be reached.
+func setupGrpcClient(t *testing.T) TronClient {
+	t.Helper()
+
+	address := os.Getenv("GO_TRON_TEST_NODE")
+	if address == "" {
+		address = "grpc.nile.trongrid.io:50051"
+	}
+
+	conn, err := net.DialTimeout("tcp", address, 3*time.Second)
+	if err != nil {
+		t.Skipf("node %s not reachable: %v", address, err)
+	}
+	_ = conn.Close()
+
+	client := NewGrpcClient(address,
+		WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	)
+	if err := client.Start(); err != nil {
+		t.Fatalf("failed to start gRPC client: %v", err)
+	}
+	return client
+}
+
+func TestClientOptions(t *testing.T) {
+	// Options written against GrpcClient keep working.
+	custom := Option(func(c *GrpcClient) { c.apiKey = "custom" })
+	client := NewGrpcClient("localhost:50051", WithTimeout(time.Second), custom).(*GrpcClient)
+	assert.Equal(t, time.Second, client.grpcTimeout)
+	assert.Equal(t, "custom", client.apiKey)
+
+	h := NewHTTPClient("http://localhost:8090", WithTimeout(time.Second), custom, WithSolidity()).(*HTTPClient)
+	assert.Equal(t, time.Second, h.timeout)
+	assert.Equal(t, "custom", h.apiKey)
+	assert.True(t, h.solidity)
+}
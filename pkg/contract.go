@@ -12,6 +12,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/dszi/go-tron/common/base58"
 	hex "github.com/dszi/go-tron/common/hexutil"
@@ -22,9 +23,30 @@ import (
 )
 
 // DeployContract deploys a contract and returns the transaction result.
-func (g *GrpcClient) DeployContract(from, contractName string, abi *core.SmartContract_ABI, codeStr string, feeLimit, curPercent, oeLimit int64) (*api.TransactionExtention, error) {
-	var err error
+// The options encode constructor arguments and set the call value; the address
+// of the new contract is given by GenerateContractAddress.
+func (g *GrpcClient) DeployContract(from, contractName string, abi *core.SmartContract_ABI, codeStr string, feeLimit, curPercent, oeLimit int64, options ...DeployOption) (*api.TransactionExtention, error) {
+	ct, err := newCreateSmartContract(from, contractName, abi, codeStr, curPercent, oeLimit, options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
 
+	tx, err := g.Client.DeployContract(ctx, ct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy contract: %w", err)
+	}
+	if feeLimit > 0 {
+		tx.Transaction.RawData.FeeLimit = feeLimit
+		g.UpdateHash(tx)
+	}
+	return tx, err
+}
+
+// newCreateSmartContract validates the deployment parameters and builds the contract.
+func newCreateSmartContract(from, contractName string, abi *core.SmartContract_ABI, codeStr string, curPercent, oeLimit int64, options []DeployOption) (*core.CreateSmartContract, error) {
 	fromDesc, err := base58.DecodeCheck(from)
 	if err != nil {
 		return nil, fmt.Errorf("invalid sender address: %w", err)
@@ -52,23 +74,69 @@ func (g *GrpcClient) DeployContract(from, contractName string, abi *core.SmartCo
 			Bytecode:                   bc,
 		},
 	}
+	if err := applyDeployOptions(ct, options); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+// TriggerContract executes a contract function.
+func (g *GrpcClient) TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error) {
+	ct, err := newTriggerSmartContract(from, contractAddress, method, jsonString, tAmount, tTokenID, tTokenAmount)
+	if err != nil {
+		return nil, err
+	}
+	return g.triggerContract(ct, feeLimit)
+}
+
+// TriggerMethod executes a contract method by name with Go arguments, encoded
+// with the contract ABI, which is fetched once and cached.
+func (g *GrpcClient) TriggerMethod(from, contractAddress string, feeLimit, callValue int64, method string, args ...any) (*api.TransactionExtention, error) {
+	ct, err := newTriggerMethodContract(g.contractABIs(), from, contractAddress, callValue, method, args)
+	if err != nil {
+		return nil, err
+	}
+	return g.triggerContract(ct, feeLimit)
+}
+
+// TriggerContractData executes a contract with call data encoded by the caller.
+func (g *GrpcClient) TriggerContractData(from, contractAddress string, data []byte, feeLimit, callValue int64) (*api.TransactionExtention, error) {
+	ct, err := newTriggerDataContract(from, contractAddress, data, callValue)
+	if err != nil {
+		return nil, err
+	}
+	return g.triggerContract(ct, feeLimit)
+}
+
+// TriggerConstantContract calls a contract without creating a transaction on chain,
+// returning the result in ConstantResult.
+func (g *GrpcClient) TriggerConstantContract(from, contractAddress string, data []byte) (*api.TransactionExtention, error) {
+	ct, err := newTriggerDataContract(from, contractAddress, data, 0)
+	if err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := g.getContext()
 	defer cancel()
 
-	tx, err := g.Client.DeployContract(ctx, ct)
+	tx, err := g.Client.TriggerConstantContract(ctx, ct)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deploy contract: %w", err)
+		return nil, fmt.Errorf("failed to call contract: %w", err)
 	}
-	if feeLimit > 0 {
-		tx.Transaction.RawData.FeeLimit = feeLimit
-		g.UpdateHash(tx)
+	if tx.GetResult().GetCode() > 0 {
+		return nil, fmt.Errorf("contract call failed: %s", string(tx.Result.Message))
 	}
-	return tx, err
+	return tx, nil
 }
 
-// TriggerContract executes a contract function.
-func (g *GrpcClient) TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error) {
+// contractABIs returns the ABI cache of the client.
+func (g *GrpcClient) contractABIs() *ABICache {
+	g.abisOnce.Do(func() { g.abis = NewABICache(g) })
+	return g.abis
+}
+
+// newTriggerSmartContract encodes the method call and builds the trigger contract.
+func newTriggerSmartContract(from, contractAddress, method, jsonString string, tAmount int64, tTokenID string, tTokenAmount int64) (*core.TriggerSmartContract, error) {
 	fromDesc, err := base58.DecodeCheck(from)
 	if err != nil {
 		return nil, fmt.Errorf("invalid sender address: %w", err)
@@ -103,8 +171,25 @@ func (g *GrpcClient) TriggerContract(from, contractAddress, method, jsonString s
 			return nil, fmt.Errorf("invalid token ID: %w", err)
 		}
 	}
+	return ct, nil
+}
 
-	return g.triggerContract(ct, feeLimit)
+// newTriggerDataContract builds a trigger contract with encoded call data.
+func newTriggerDataContract(from, contractAddress string, data []byte, callValue int64) (*core.TriggerSmartContract, error) {
+	fromDesc, err := base58.DecodeCheck(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+	return &core.TriggerSmartContract{
+		OwnerAddress:    fromDesc,
+		ContractAddress: contractDesc,
+		Data:            data,
+		CallValue:       callValue,
+	}, nil
 }
 
 // triggerContract sends a smart contract execution transaction.
@@ -129,20 +214,28 @@ func (g *GrpcClient) triggerContract(ct *core.TriggerSmartContract, feeLimit int
 
 // UpdateHash updates the transaction hash after local modifications.
 func (g *GrpcClient) UpdateHash(tx *api.TransactionExtention) error {
-	rawData, err := proto.Marshal(tx.Transaction.GetRawData())
+	hash, err := transactionID(tx.Transaction.GetRawData())
 	if err != nil {
 		return err
 	}
+	tx.Txid = hash
+	return nil
+}
+
+// transactionID computes the transaction ID, the sha256 hash of the raw data.
+func transactionID(raw *core.TransactionRaw) ([]byte, error) {
+	rawData, err := proto.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
 
 	h256h := sha256.New()
 	h256h.Write(rawData)
-	hash := h256h.Sum(nil)
-	tx.Txid = hash
-	return nil
+	return h256h.Sum(nil), nil
 }
 
-// GetContractABI retrieves the ABI of a deployed contract.
-func (g *GrpcClient) GetContractABI(contractAddress string) (*core.SmartContract_ABI, error) {
+// GetContract retrieves a deployed contract, including its bytecode and ABI.
+func (g *GrpcClient) GetContract(contractAddress string) (*core.SmartContract, error) {
 	contractDesc, err := base58.DecodeCheck(contractAddress)
 	if err != nil {
 		return nil, fmt.Errorf("invalid contract address: %w", err)
@@ -155,6 +248,15 @@ func (g *GrpcClient) GetContractABI(contractAddress string) (*core.SmartContract
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve contract: %w", err)
 	}
+	return sm, nil
+}
+
+// GetContractABI retrieves the ABI of a deployed contract.
+func (g *GrpcClient) GetContractABI(contractAddress string) (*core.SmartContract_ABI, error) {
+	sm, err := g.GetContract(contractAddress)
+	if err != nil {
+		return nil, err
+	}
 
 	if sm == nil {
 		return nil, fmt.Errorf("contract ABI not found")
@@ -162,3 +264,136 @@ func (g *GrpcClient) GetContractABI(contractAddress string) (*core.SmartContract
 
 	return sm.Abi, nil
 }
+
+// GetContractInfo retrieves a deployed contract with its runtime code and energy state.
+func (g *GrpcClient) GetContractInfo(contractAddress string) (*core.SmartContractDataWrapper, error) {
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	info, err := g.Client.GetContractInfo(ctx, GetMessageBytes(contractDesc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve contract info: %w", err)
+	}
+	return info, nil
+}
+
+// UpdateSetting updates the percentage of energy paid by the callers of a contract.
+func (g *GrpcClient) UpdateSetting(from, contractAddress string, consumeUserResourcePercent int64) (*api.TransactionExtention, error) {
+	ct, err := newUpdateSettingContract(from, contractAddress, consumeUserResourcePercent)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.UpdateSetting(ctx, ct)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateSetting RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// UpdateEnergyLimit updates the maximum energy the contract owner provides per call.
+func (g *GrpcClient) UpdateEnergyLimit(from, contractAddress string, originEnergyLimit int64) (*api.TransactionExtention, error) {
+	ct, err := newUpdateEnergyLimitContract(from, contractAddress, originEnergyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.UpdateEnergyLimit(ctx, ct)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateEnergyLimit RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ClearContractABI removes the ABI of a contract. The cached ABI of the contract
+// is dropped once the transaction is confirmed; signing does not change its ID.
+func (g *GrpcClient) ClearContractABI(from, contractAddress string) (*api.TransactionExtention, error) {
+	ct, err := newClearABIContract(from, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.ClearContractABI(ctx, ct)
+	if err != nil {
+		return nil, fmt.Errorf("ClearContractABI RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	g.contractABIs().ExpectClear(contractAddress, fmt.Sprintf("%x", tx.GetTxid()), time.UnixMilli(tx.GetTransaction().GetRawData().GetExpiration()))
+	return tx, nil
+}
+
+// contractOwnerAddresses decodes the owner and contract addresses of a contract administration call.
+func contractOwnerAddresses(from, contractAddress string) ([]byte, []byte, error) {
+	fromDesc, err := base58.DecodeCheck(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid owner address: %w", err)
+	}
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+	return fromDesc, contractDesc, nil
+}
+
+// newUpdateSettingContract validates the percentage and builds the contract.
+func newUpdateSettingContract(from, contractAddress string, consumeUserResourcePercent int64) (*core.UpdateSettingContract, error) {
+	if consumeUserResourcePercent < 0 || consumeUserResourcePercent > 100 {
+		return nil, fmt.Errorf("consume_user_resource_percent should be between 0 and 100")
+	}
+	owner, contract, err := contractOwnerAddresses(from, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &core.UpdateSettingContract{
+		OwnerAddress:               owner,
+		ContractAddress:            contract,
+		ConsumeUserResourcePercent: consumeUserResourcePercent,
+	}, nil
+}
+
+// newUpdateEnergyLimitContract validates the limit and builds the contract.
+func newUpdateEnergyLimitContract(from, contractAddress string, originEnergyLimit int64) (*core.UpdateEnergyLimitContract, error) {
+	if originEnergyLimit <= 0 {
+		return nil, fmt.Errorf("origin_energy_limit must be greater than 0")
+	}
+	owner, contract, err := contractOwnerAddresses(from, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &core.UpdateEnergyLimitContract{
+		OwnerAddress:      owner,
+		ContractAddress:   contract,
+		OriginEnergyLimit: originEnergyLimit,
+	}, nil
+}
+
+// newClearABIContract builds the contract clearing a contract ABI.
+func newClearABIContract(from, contractAddress string) (*core.ClearABIContract, error) {
+	owner, contract, err := contractOwnerAddresses(from, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &core.ClearABIContract{OwnerAddress: owner, ContractAddress: contract}, nil
+}
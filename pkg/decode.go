@@ -0,0 +1,698 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TransferValue is a decoded TransferContract.
+type TransferValue struct {
+	Owner  string
+	To     string
+	Amount int64
+}
+
+// TransferAssetValue is a decoded TransferAssetContract.
+type TransferAssetValue struct {
+	Owner     string
+	To        string
+	AssetName string
+	Amount    int64
+}
+
+// TriggerSmartContractValue is a decoded TriggerSmartContract.
+type TriggerSmartContractValue struct {
+	Owner          string
+	Contract       string
+	CallValue      int64
+	Data           []byte
+	TokenID        int64
+	CallTokenValue int64
+}
+
+// CreateSmartContractValue is a decoded CreateSmartContract.
+type CreateSmartContractValue struct {
+	Owner                      string
+	Name                       string
+	CallValue                  int64
+	ConsumeUserResourcePercent int64
+	OriginEnergyLimit          int64
+	TokenID                    int64
+	CallTokenValue             int64
+}
+
+// AccountCreateValue is a decoded AccountCreateContract.
+type AccountCreateValue struct {
+	Owner   string
+	Account string
+}
+
+// FreezeBalanceV2Value is a decoded FreezeBalanceV2Contract.
+type FreezeBalanceV2Value struct {
+	Owner    string
+	Resource core.ResourceCode
+	Amount   int64
+}
+
+// UnfreezeBalanceV2Value is a decoded UnfreezeBalanceV2Contract.
+type UnfreezeBalanceV2Value struct {
+	Owner    string
+	Resource core.ResourceCode
+	Amount   int64
+}
+
+// DelegateResourceValue is a decoded DelegateResourceContract.
+type DelegateResourceValue struct {
+	Owner      string
+	Receiver   string
+	Resource   core.ResourceCode
+	Balance    int64
+	Lock       bool
+	LockPeriod int64
+}
+
+// UnDelegateResourceValue is a decoded UnDelegateResourceContract.
+type UnDelegateResourceValue struct {
+	Owner    string
+	Receiver string
+	Resource core.ResourceCode
+	Balance  int64
+}
+
+// VoteWitnessValue is a decoded VoteWitnessContract. Votes maps witness addresses to vote counts.
+type VoteWitnessValue struct {
+	Owner string
+	Votes map[string]int64
+}
+
+// WithdrawBalanceValue is a decoded WithdrawBalanceContract.
+type WithdrawBalanceValue struct {
+	Owner string
+}
+
+// VoteAssetValue is a decoded VoteAssetContract.
+type VoteAssetValue struct {
+	Owner   string
+	Votes   []string
+	Support bool
+	Count   int32
+}
+
+// WitnessCreateValue is a decoded WitnessCreateContract.
+type WitnessCreateValue struct {
+	Owner string
+	URL   string
+}
+
+// WitnessUpdateValue is a decoded WitnessUpdateContract.
+type WitnessUpdateValue struct {
+	Owner string
+	URL   string
+}
+
+// AssetFrozenSupply is a part of a TRC-10 supply frozen at issuance.
+type AssetFrozenSupply struct {
+	Amount int64
+	Days   int64
+}
+
+// AssetIssueValue is a decoded AssetIssueContract.
+type AssetIssueValue struct {
+	Owner                   string
+	ID                      string
+	Name                    string
+	Abbr                    string
+	TotalSupply             int64
+	FrozenSupply            []AssetFrozenSupply
+	TrxNum                  int32
+	Num                     int32
+	Precision               int32
+	StartTime               time.Time
+	EndTime                 time.Time
+	Description             string
+	URL                     string
+	FreeAssetNetLimit       int64
+	PublicFreeAssetNetLimit int64
+}
+
+// ParticipateAssetIssueValue is a decoded ParticipateAssetIssueContract.
+type ParticipateAssetIssueValue struct {
+	Owner     string
+	To        string
+	AssetName string
+	Amount    int64
+}
+
+// UpdateAssetValue is a decoded UpdateAssetContract.
+type UpdateAssetValue struct {
+	Owner          string
+	Description    string
+	URL            string
+	NewLimit       int64
+	NewPublicLimit int64
+}
+
+// UnfreezeAssetValue is a decoded UnfreezeAssetContract.
+type UnfreezeAssetValue struct {
+	Owner string
+}
+
+// AccountUpdateValue is a decoded AccountUpdateContract.
+type AccountUpdateValue struct {
+	Owner       string
+	AccountName string
+}
+
+// SetAccountIDValue is a decoded SetAccountIdContract.
+type SetAccountIDValue struct {
+	Owner     string
+	AccountID string
+}
+
+// PermissionValue is a decoded account permission. Keys maps addresses to weights.
+type PermissionValue struct {
+	Type       core.Permission_PermissionType
+	ID         int32
+	Name       string
+	Threshold  int64
+	Operations []byte
+	Keys       map[string]int64
+}
+
+// AccountPermissionUpdateValue is a decoded AccountPermissionUpdateContract.
+// WitnessPermission is nil when the account is not a witness.
+type AccountPermissionUpdateValue struct {
+	Owner             string
+	OwnerPermission   *PermissionValue
+	WitnessPermission *PermissionValue
+	ActivePermissions []*PermissionValue
+}
+
+// FreezeBalanceValue is a decoded FreezeBalanceContract (Stake 1.0).
+type FreezeBalanceValue struct {
+	Owner    string
+	Receiver string
+	Resource core.ResourceCode
+	Amount   int64
+	Duration int64
+}
+
+// UnfreezeBalanceValue is a decoded UnfreezeBalanceContract (Stake 1.0).
+type UnfreezeBalanceValue struct {
+	Owner    string
+	Receiver string
+	Resource core.ResourceCode
+}
+
+// WithdrawExpireUnfreezeValue is a decoded WithdrawExpireUnfreezeContract.
+type WithdrawExpireUnfreezeValue struct {
+	Owner string
+}
+
+// CancelAllUnfreezeV2Value is a decoded CancelAllUnfreezeV2Contract.
+type CancelAllUnfreezeV2Value struct {
+	Owner string
+}
+
+// ProposalCreateValue is a decoded ProposalCreateContract. Parameters maps
+// chain parameter IDs to their proposed values.
+type ProposalCreateValue struct {
+	Owner      string
+	Parameters map[int64]int64
+}
+
+// ProposalApproveValue is a decoded ProposalApproveContract.
+type ProposalApproveValue struct {
+	Owner      string
+	ProposalID int64
+	Approve    bool
+}
+
+// ProposalDeleteValue is a decoded ProposalDeleteContract.
+type ProposalDeleteValue struct {
+	Owner      string
+	ProposalID int64
+}
+
+// UpdateBrokerageValue is a decoded UpdateBrokerageContract.
+type UpdateBrokerageValue struct {
+	Owner     string
+	Brokerage int32
+}
+
+// UpdateSettingValue is a decoded UpdateSettingContract.
+type UpdateSettingValue struct {
+	Owner                      string
+	Contract                   string
+	ConsumeUserResourcePercent int64
+}
+
+// UpdateEnergyLimitValue is a decoded UpdateEnergyLimitContract.
+type UpdateEnergyLimitValue struct {
+	Owner             string
+	Contract          string
+	OriginEnergyLimit int64
+}
+
+// ClearABIValue is a decoded ClearABIContract.
+type ClearABIValue struct {
+	Owner    string
+	Contract string
+}
+
+// ExchangeCreateValue is a decoded ExchangeCreateContract. Token IDs are "_" for TRX.
+type ExchangeCreateValue struct {
+	Owner              string
+	FirstTokenID       string
+	FirstTokenBalance  int64
+	SecondTokenID      string
+	SecondTokenBalance int64
+}
+
+// ExchangeInjectValue is a decoded ExchangeInjectContract.
+type ExchangeInjectValue struct {
+	Owner      string
+	ExchangeID int64
+	TokenID    string
+	Quant      int64
+}
+
+// ExchangeWithdrawValue is a decoded ExchangeWithdrawContract.
+type ExchangeWithdrawValue struct {
+	Owner      string
+	ExchangeID int64
+	TokenID    string
+	Quant      int64
+}
+
+// ExchangeTransactionValue is a decoded ExchangeTransactionContract.
+type ExchangeTransactionValue struct {
+	Owner      string
+	ExchangeID int64
+	TokenID    string
+	Quant      int64
+	Expected   int64
+}
+
+// MarketSellAssetValue is a decoded MarketSellAssetContract.
+type MarketSellAssetValue struct {
+	Owner             string
+	SellTokenID       string
+	SellTokenQuantity int64
+	BuyTokenID        string
+	BuyTokenQuantity  int64
+}
+
+// MarketCancelOrderValue is a decoded MarketCancelOrderContract.
+type MarketCancelOrderValue struct {
+	Owner   string
+	OrderID string
+}
+
+// ShieldedTransferValue is a decoded ShieldedTransferContract. The transparent
+// addresses are empty when the transfer starts or ends in the shielded pool.
+type ShieldedTransferValue struct {
+	TransparentFrom string
+	FromAmount      int64
+	TransparentTo   string
+	ToAmount        int64
+	Spends          int
+	Receives        int
+}
+
+// DecodedContract is a transaction contract resolved into its typed form.
+type DecodedContract struct {
+	Type  core.Transaction_Contract_ContractType
+	Owner string
+	// Message is the unmarshaled protobuf contract, e.g. *core.TransferContract.
+	Message proto.Message
+	// Value is the *Value type of this package matching the contract type, e.g.
+	// *TransferValue. CustomContract and GetContract have no message and cannot be decoded.
+	Value any
+	// Fields holds every field of the contract, with addresses as base58 strings
+	// and other binary fields hex encoded.
+	Fields map[string]any
+	// Call is the decoded method call of a TriggerSmartContract, when its ABI is known.
+	Call *abi.MethodCall
+}
+
+// DecodedTransaction is a transaction with its contracts resolved.
+type DecodedTransaction struct {
+	TxID       string
+	Timestamp  time.Time
+	Expiration time.Time
+	FeeLimit   int64
+	Memo       string
+	Contracts  []*DecodedContract
+}
+
+// ABIResolver returns the ABI of the contract at a base58 address.
+type ABIResolver func(contractAddress string) (*core.SmartContract_ABI, error)
+
+// TransactionDecoder decodes transactions, using an optional ABI resolver to decode contract calls.
+type TransactionDecoder struct {
+	resolver ABIResolver
+}
+
+// NewTransactionDecoder creates a decoder. The resolver may be nil, in which case
+// contract calls are not decoded; client.GetContractABI can be used as a resolver.
+func NewTransactionDecoder(resolver ABIResolver) *TransactionDecoder {
+	return &TransactionDecoder{resolver: resolver}
+}
+
+// Decode resolves all contracts of a transaction.
+func (d *TransactionDecoder) Decode(tx *core.Transaction) (*DecodedTransaction, error) {
+	raw := tx.GetRawData()
+	if raw == nil {
+		return nil, fmt.Errorf("Decode: transaction has no raw data")
+	}
+	txid, err := transactionID(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Decode: %w", err)
+	}
+
+	decoded := &DecodedTransaction{
+		TxID:       hex.EncodeToString(txid),
+		Timestamp:  time.UnixMilli(raw.GetTimestamp()),
+		Expiration: time.UnixMilli(raw.GetExpiration()),
+		FeeLimit:   raw.GetFeeLimit(),
+		Memo:       string(raw.GetData()),
+	}
+	for _, c := range raw.GetContract() {
+		dc, err := d.DecodeContract(c)
+		if err != nil {
+			return nil, err
+		}
+		decoded.Contracts = append(decoded.Contracts, dc)
+	}
+	return decoded, nil
+}
+
+// DecodeContract resolves a single transaction contract. Failing to decode the
+// method call of a TriggerSmartContract is not an error; Call is left nil.
+func (d *TransactionDecoder) DecodeContract(c *core.Transaction_Contract) (*DecodedContract, error) {
+	dc, err := DecodeContract(c)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := dc.Value.(*TriggerSmartContractValue); ok && d.resolver != nil && len(v.Data) >= 4 {
+		if contractABI, err := d.resolver(v.Contract); err == nil {
+			dc.Call, _ = abi.DecodeCall(contractABI, v.Data)
+		}
+	}
+	return dc, nil
+}
+
+// DecodeContract resolves a transaction contract into its typed form, without decoding method calls.
+func DecodeContract(c *core.Transaction_Contract) (*DecodedContract, error) {
+	if c.GetParameter() == nil {
+		return nil, fmt.Errorf("DecodeContract: %s has no parameter", c.GetType())
+	}
+	msg, err := c.GetParameter().UnmarshalNew()
+	if err != nil {
+		return nil, fmt.Errorf("DecodeContract: failed to unmarshal %s: %w", c.GetType(), err)
+	}
+
+	dc := &DecodedContract{
+		Type:    c.GetType(),
+		Message: msg,
+		Fields:  messageFields(msg.ProtoReflect()),
+	}
+	if owner, ok := dc.Fields["owner_address"].(string); ok {
+		dc.Owner = owner
+	}
+
+	switch m := msg.(type) {
+	case *core.TransferContract:
+		dc.Value = &TransferValue{Owner: dc.Owner, To: encodeAddress(m.ToAddress), Amount: m.Amount}
+	case *core.TransferAssetContract:
+		dc.Value = &TransferAssetValue{Owner: dc.Owner, To: encodeAddress(m.ToAddress), AssetName: string(m.AssetName), Amount: m.Amount}
+	case *core.TriggerSmartContract:
+		dc.Value = &TriggerSmartContractValue{
+			Owner:          dc.Owner,
+			Contract:       encodeAddress(m.ContractAddress),
+			CallValue:      m.CallValue,
+			Data:           m.Data,
+			TokenID:        m.TokenId,
+			CallTokenValue: m.CallTokenValue,
+		}
+	case *core.CreateSmartContract:
+		dc.Value = &CreateSmartContractValue{
+			Owner:                      dc.Owner,
+			Name:                       m.GetNewContract().GetName(),
+			CallValue:                  m.GetNewContract().GetCallValue(),
+			ConsumeUserResourcePercent: m.GetNewContract().GetConsumeUserResourcePercent(),
+			OriginEnergyLimit:          m.GetNewContract().GetOriginEnergyLimit(),
+			TokenID:                    m.TokenId,
+			CallTokenValue:             m.CallTokenValue,
+		}
+	case *core.AccountCreateContract:
+		dc.Value = &AccountCreateValue{Owner: dc.Owner, Account: encodeAddress(m.AccountAddress)}
+	case *core.FreezeBalanceV2Contract:
+		dc.Value = &FreezeBalanceV2Value{Owner: dc.Owner, Resource: m.Resource, Amount: m.FrozenBalance}
+	case *core.UnfreezeBalanceV2Contract:
+		dc.Value = &UnfreezeBalanceV2Value{Owner: dc.Owner, Resource: m.Resource, Amount: m.UnfreezeBalance}
+	case *core.DelegateResourceContract:
+		dc.Value = &DelegateResourceValue{
+			Owner:      dc.Owner,
+			Receiver:   encodeAddress(m.ReceiverAddress),
+			Resource:   m.Resource,
+			Balance:    m.Balance,
+			Lock:       m.Lock,
+			LockPeriod: m.LockPeriod,
+		}
+	case *core.UnDelegateResourceContract:
+		dc.Value = &UnDelegateResourceValue{Owner: dc.Owner, Receiver: encodeAddress(m.ReceiverAddress), Resource: m.Resource, Balance: m.Balance}
+	case *core.VoteWitnessContract:
+		votes := make(map[string]int64, len(m.Votes))
+		for _, v := range m.Votes {
+			votes[encodeAddress(v.VoteAddress)] += v.VoteCount
+		}
+		dc.Value = &VoteWitnessValue{Owner: dc.Owner, Votes: votes}
+	case *core.WithdrawBalanceContract:
+		dc.Value = &WithdrawBalanceValue{Owner: dc.Owner}
+	case *core.VoteAssetContract:
+		votes := make([]string, len(m.VoteAddress))
+		for i, addr := range m.VoteAddress {
+			votes[i] = encodeAddress(addr)
+		}
+		dc.Value = &VoteAssetValue{Owner: dc.Owner, Votes: votes, Support: m.Support, Count: m.Count}
+	case *core.WitnessCreateContract:
+		dc.Value = &WitnessCreateValue{Owner: dc.Owner, URL: string(m.Url)}
+	case *core.WitnessUpdateContract:
+		dc.Value = &WitnessUpdateValue{Owner: dc.Owner, URL: string(m.UpdateUrl)}
+	case *core.AssetIssueContract:
+		v := &AssetIssueValue{
+			Owner:                   dc.Owner,
+			ID:                      m.Id,
+			Name:                    string(m.Name),
+			Abbr:                    string(m.Abbr),
+			TotalSupply:             m.TotalSupply,
+			TrxNum:                  m.TrxNum,
+			Num:                     m.Num,
+			Precision:               m.Precision,
+			StartTime:               time.UnixMilli(m.StartTime),
+			EndTime:                 time.UnixMilli(m.EndTime),
+			Description:             string(m.Description),
+			URL:                     string(m.Url),
+			FreeAssetNetLimit:       m.FreeAssetNetLimit,
+			PublicFreeAssetNetLimit: m.PublicFreeAssetNetLimit,
+		}
+		for _, f := range m.FrozenSupply {
+			v.FrozenSupply = append(v.FrozenSupply, AssetFrozenSupply{Amount: f.FrozenAmount, Days: f.FrozenDays})
+		}
+		dc.Value = v
+	case *core.ParticipateAssetIssueContract:
+		dc.Value = &ParticipateAssetIssueValue{Owner: dc.Owner, To: encodeAddress(m.ToAddress), AssetName: string(m.AssetName), Amount: m.Amount}
+	case *core.UpdateAssetContract:
+		dc.Value = &UpdateAssetValue{
+			Owner:          dc.Owner,
+			Description:    string(m.Description),
+			URL:            string(m.Url),
+			NewLimit:       m.NewLimit,
+			NewPublicLimit: m.NewPublicLimit,
+		}
+	case *core.UnfreezeAssetContract:
+		dc.Value = &UnfreezeAssetValue{Owner: dc.Owner}
+	case *core.AccountUpdateContract:
+		dc.Value = &AccountUpdateValue{Owner: dc.Owner, AccountName: string(m.AccountName)}
+	case *core.SetAccountIdContract:
+		dc.Value = &SetAccountIDValue{Owner: dc.Owner, AccountID: string(m.AccountId)}
+	case *core.AccountPermissionUpdateContract:
+		v := &AccountPermissionUpdateValue{Owner: dc.Owner, OwnerPermission: permissionValue(m.Owner), WitnessPermission: permissionValue(m.Witness)}
+		for _, p := range m.Actives {
+			v.ActivePermissions = append(v.ActivePermissions, permissionValue(p))
+		}
+		dc.Value = v
+	case *core.FreezeBalanceContract:
+		dc.Value = &FreezeBalanceValue{
+			Owner:    dc.Owner,
+			Receiver: encodeAddress(m.ReceiverAddress),
+			Resource: m.Resource,
+			Amount:   m.FrozenBalance,
+			Duration: m.FrozenDuration,
+		}
+	case *core.UnfreezeBalanceContract:
+		dc.Value = &UnfreezeBalanceValue{Owner: dc.Owner, Receiver: encodeAddress(m.ReceiverAddress), Resource: m.Resource}
+	case *core.WithdrawExpireUnfreezeContract:
+		dc.Value = &WithdrawExpireUnfreezeValue{Owner: dc.Owner}
+	case *core.CancelAllUnfreezeV2Contract:
+		dc.Value = &CancelAllUnfreezeV2Value{Owner: dc.Owner}
+	case *core.ProposalCreateContract:
+		dc.Value = &ProposalCreateValue{Owner: dc.Owner, Parameters: m.Parameters}
+	case *core.ProposalApproveContract:
+		dc.Value = &ProposalApproveValue{Owner: dc.Owner, ProposalID: m.ProposalId, Approve: m.IsAddApproval}
+	case *core.ProposalDeleteContract:
+		dc.Value = &ProposalDeleteValue{Owner: dc.Owner, ProposalID: m.ProposalId}
+	case *core.UpdateBrokerageContract:
+		dc.Value = &UpdateBrokerageValue{Owner: dc.Owner, Brokerage: m.Brokerage}
+	case *core.UpdateSettingContract:
+		dc.Value = &UpdateSettingValue{Owner: dc.Owner, Contract: encodeAddress(m.ContractAddress), ConsumeUserResourcePercent: m.ConsumeUserResourcePercent}
+	case *core.UpdateEnergyLimitContract:
+		dc.Value = &UpdateEnergyLimitValue{Owner: dc.Owner, Contract: encodeAddress(m.ContractAddress), OriginEnergyLimit: m.OriginEnergyLimit}
+	case *core.ClearABIContract:
+		dc.Value = &ClearABIValue{Owner: dc.Owner, Contract: encodeAddress(m.ContractAddress)}
+	case *core.ExchangeCreateContract:
+		dc.Value = &ExchangeCreateValue{
+			Owner:              dc.Owner,
+			FirstTokenID:       string(m.FirstTokenId),
+			FirstTokenBalance:  m.FirstTokenBalance,
+			SecondTokenID:      string(m.SecondTokenId),
+			SecondTokenBalance: m.SecondTokenBalance,
+		}
+	case *core.ExchangeInjectContract:
+		dc.Value = &ExchangeInjectValue{Owner: dc.Owner, ExchangeID: m.ExchangeId, TokenID: string(m.TokenId), Quant: m.Quant}
+	case *core.ExchangeWithdrawContract:
+		dc.Value = &ExchangeWithdrawValue{Owner: dc.Owner, ExchangeID: m.ExchangeId, TokenID: string(m.TokenId), Quant: m.Quant}
+	case *core.ExchangeTransactionContract:
+		dc.Value = &ExchangeTransactionValue{Owner: dc.Owner, ExchangeID: m.ExchangeId, TokenID: string(m.TokenId), Quant: m.Quant, Expected: m.Expected}
+	case *core.MarketSellAssetContract:
+		dc.Value = &MarketSellAssetValue{
+			Owner:             dc.Owner,
+			SellTokenID:       string(m.SellTokenId),
+			SellTokenQuantity: m.SellTokenQuantity,
+			BuyTokenID:        string(m.BuyTokenId),
+			BuyTokenQuantity:  m.BuyTokenQuantity,
+		}
+	case *core.MarketCancelOrderContract:
+		dc.Value = &MarketCancelOrderValue{Owner: dc.Owner, OrderID: hex.EncodeToString(m.OrderId)}
+	case *core.ShieldedTransferContract:
+		dc.Owner = encodeAddress(m.TransparentFromAddress)
+		dc.Value = &ShieldedTransferValue{
+			TransparentFrom: dc.Owner,
+			FromAmount:      m.FromAmount,
+			TransparentTo:   encodeAddress(m.TransparentToAddress),
+			ToAmount:        m.ToAmount,
+			Spends:          len(m.SpendDescription),
+			Receives:        len(m.ReceiveDescription),
+		}
+	}
+	return dc, nil
+}
+
+// permissionValue decodes an account permission, returning nil for nil.
+func permissionValue(p *core.Permission) *PermissionValue {
+	if p == nil {
+		return nil
+	}
+	keys := make(map[string]int64, len(p.Keys))
+	for _, k := range p.Keys {
+		keys[encodeAddress(k.Address)] = k.Weight
+	}
+	return &PermissionValue{
+		Type:       p.Type,
+		ID:         p.Id,
+		Name:       p.PermissionName,
+		Threshold:  p.Threshold,
+		Operations: p.Operations,
+		Keys:       keys,
+	}
+}
+
+// textFields are bytes fields holding human-readable text rather than binary data.
+var textFields = map[protoreflect.Name]bool{
+	"name":         true,
+	"abbr":         true,
+	"description":  true,
+	"url":          true,
+	"update_url":   true,
+	"account_name": true,
+	"account_id":   true,
+	"asset_name":   true,
+}
+
+// messageFields converts a message into a map keyed by proto field name.
+func messageFields(m protoreflect.Message) map[string]any {
+	fields := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]any, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				values[i] = fieldValue(fd, list.Get(i))
+			}
+			fields[string(fd.Name())] = values
+		case fd.IsMap():
+			values := make(map[string]any)
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				values[k.String()] = fieldValue(fd.MapValue(), mv)
+				return true
+			})
+			fields[string(fd.Name())] = values
+		default:
+			fields[string(fd.Name())] = fieldValue(fd, v)
+		}
+		return true
+	})
+	return fields
+}
+
+// fieldValue converts a single protobuf value into a readable Go value.
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageFields(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	case protoreflect.BytesKind:
+		b := v.Bytes()
+		switch {
+		case strings.HasSuffix(string(fd.Name()), "address") && len(b) == 21:
+			return encodeAddress(b)
+		case textFields[fd.Name()]:
+			return string(b)
+		default:
+			return hex.EncodeToString(b)
+		}
+	default:
+		return v.Interface()
+	}
+}
+
+// encodeAddress encodes a 21-byte address as base58, returning "" for empty input.
+func encodeAddress(addr []byte) string {
+	if len(addr) == 0 {
+		return ""
+	}
+	return base58.EncodeCheck(addr)
+}
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var trc20TransferABI = &core.SmartContract_ABI{Entrys: []*core.SmartContract_ABI_Entry{{
+	Type: core.SmartContract_ABI_Entry_Function,
+	Name: "transfer",
+	Inputs: []*core.SmartContract_ABI_Entry_Param{
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+	},
+	Outputs:         []*core.SmartContract_ABI_Entry_Param{{Type: "bool"}},
+	StateMutability: core.SmartContract_ABI_Entry_Nonpayable,
+}}}
+
+func TestTransactionDecoder(t *testing.T) {
+	owner, contract, to := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9"
+	ownerBytes, _ := base58.DecodeCheck(owner)
+	contractBytes, _ := base58.DecodeCheck(contract)
+
+	data, err := abi.Pack("transfer(address,uint256)", []abi.Param{{"address": to}, {"uint256": "1000000"}})
+	require.Nil(t, err)
+	trigger, err := anypb.New(&core.TriggerSmartContract{OwnerAddress: ownerBytes, ContractAddress: contractBytes, Data: data})
+	require.Nil(t, err)
+	vote, err := anypb.New(&core.VoteWitnessContract{
+		OwnerAddress: ownerBytes,
+		Votes:        []*core.VoteWitnessContract_Vote{{VoteAddress: contractBytes, VoteCount: 7}},
+	})
+	require.Nil(t, err)
+
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Data: []byte("hello"),
+		Contract: []*core.Transaction_Contract{
+			{Type: core.Transaction_Contract_TriggerSmartContract, Parameter: trigger},
+			{Type: core.Transaction_Contract_VoteWitnessContract, Parameter: vote},
+		},
+	}}
+
+	decoder := NewTransactionDecoder(func(addr string) (*core.SmartContract_ABI, error) {
+		assert.Equal(t, contract, addr)
+		return trc20TransferABI, nil
+	})
+	decoded, err := decoder.Decode(tx)
+	require.Nil(t, err)
+	assert.Equal(t, "hello", decoded.Memo)
+	require.Len(t, decoded.Contracts, 2)
+
+	call := decoded.Contracts[0]
+	assert.Equal(t, owner, call.Owner)
+	assert.Equal(t, contract, call.Value.(*TriggerSmartContractValue).Contract)
+	assert.Equal(t, contract, call.Fields["contract_address"])
+	require.NotNil(t, call.Call)
+	assert.Equal(t, "transfer(address,uint256)", call.Call.Signature)
+	assert.Equal(t, to, call.Call.Args[0].Value)
+	assert.Equal(t, big.NewInt(1000000), call.Call.Args[1].Value)
+
+	votes := decoded.Contracts[1].Value.(*VoteWitnessValue)
+	assert.Equal(t, map[string]int64{contract: 7}, votes.Votes)
+}
+
+// TestDecodeContractTypes checks that every contract type with a message decodes to a typed value.
+func TestDecodeContractTypes(t *testing.T) {
+	owner := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL"
+	ownerBytes, _ := base58.DecodeCheck(owner)
+	for number, name := range core.Transaction_Contract_ContractType_name {
+		mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName("protocol." + name))
+		if err != nil {
+			// CustomContract and GetContract have no message.
+			continue
+		}
+		msg := mt.New()
+		if fd := msg.Descriptor().Fields().ByName("owner_address"); fd != nil {
+			msg.Set(fd, protoreflect.ValueOfBytes(ownerBytes))
+		}
+		param, err := anypb.New(msg.Interface())
+		require.Nil(t, err)
+
+		dc, err := DecodeContract(&core.Transaction_Contract{Type: core.Transaction_Contract_ContractType(number), Parameter: param})
+		require.Nil(t, err, name)
+		assert.NotNil(t, dc.Value, name)
+		if name != "ShieldedTransferContract" {
+			assert.Equal(t, owner, dc.Owner, name)
+		}
+	}
+
+	exchange, err := anypb.New(&core.ExchangeTransactionContract{OwnerAddress: ownerBytes, ExchangeId: 9, TokenId: []byte("_"), Quant: 100, Expected: 90})
+	require.Nil(t, err)
+	dc, err := DecodeContract(&core.Transaction_Contract{Type: core.Transaction_Contract_ExchangeTransactionContract, Parameter: exchange})
+	require.Nil(t, err)
+	assert.Equal(t, &ExchangeTransactionValue{Owner: owner, ExchangeID: 9, TokenID: "_", Quant: 100, Expected: 90}, dc.Value)
+
+	permissions, err := anypb.New(&core.AccountPermissionUpdateContract{
+		OwnerAddress: ownerBytes,
+		Owner:        &core.Permission{PermissionName: "owner", Threshold: 2, Keys: []*core.Key{{Address: ownerBytes, Weight: 2}}},
+	})
+	require.Nil(t, err)
+	dc, err = DecodeContract(&core.Transaction_Contract{Type: core.Transaction_Contract_AccountPermissionUpdateContract, Parameter: permissions})
+	require.Nil(t, err)
+	update := dc.Value.(*AccountPermissionUpdateValue)
+	assert.Equal(t, map[string]int64{owner: 2}, update.OwnerPermission.Keys)
+	assert.Nil(t, update.WitnessPermission)
+}
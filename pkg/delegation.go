@@ -0,0 +1,261 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+)
+
+const (
+	// SunPerTrx is the number of sun in one TRX.
+	SunPerTrx = 1_000_000
+	// BlockInterval is the target time between two TRON blocks.
+	BlockInterval = 3 * time.Second
+)
+
+// EnergyLease describes an energy delegation made by a DelegationScheduler.
+type EnergyLease struct {
+	Receiver   string
+	Energy     int64 // energy requested when the lease was created
+	Balance    int64 // delegated balance in sun
+	Lock       bool
+	LockPeriod int64 // lock period in blocks
+	StartedAt  time.Time
+	ExpiresAt  time.Time
+	TxID       []byte
+
+	// ReclaimTxID is the undelegate transaction built by Reclaim, not known to
+	// be confirmed yet, and ReclaimExpiration its expiration.
+	ReclaimTxID       []byte
+	ReclaimExpiration time.Time
+}
+
+// Expired reports whether the lease has ended at the given time.
+func (l *EnergyLease) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// DelegationScheduler rents energy from a staking account to receivers and
+// reclaims it once the leases expire.
+type DelegationScheduler struct {
+	client TronClient
+	owner  string
+	now    func() time.Time
+
+	reclaim sync.Mutex // one Reclaim at a time
+	mu      sync.Mutex
+	leases  []*EnergyLease
+}
+
+// NewDelegationScheduler creates a scheduler delegating from the owner account.
+func NewDelegationScheduler(client TronClient, owner string) *DelegationScheduler {
+	return &DelegationScheduler{
+		client: client,
+		owner:  owner,
+		now:    time.Now,
+	}
+}
+
+// EnergyToSun converts an energy amount into the staked balance (in sun) needed to
+// produce it, using the network totals from an account resource message.
+// The result is rounded up to a whole TRX, the granularity of delegations.
+func EnergyToSun(energy int64, res *api.AccountResourceMessage) (int64, error) {
+	if energy <= 0 {
+		return 0, fmt.Errorf("energy must be greater than 0")
+	}
+	if res.GetTotalEnergyLimit() <= 0 || res.GetTotalEnergyWeight() <= 0 {
+		return 0, fmt.Errorf("invalid network energy totals: limit %d, weight %d",
+			res.GetTotalEnergyLimit(), res.GetTotalEnergyWeight())
+	}
+
+	// energy = trx * TotalEnergyLimit / TotalEnergyWeight, with the weight counted in TRX.
+	trx := new(big.Int).Mul(big.NewInt(energy), big.NewInt(res.GetTotalEnergyWeight()))
+	limit := big.NewInt(res.GetTotalEnergyLimit())
+	trx.Add(trx, new(big.Int).Sub(limit, big.NewInt(1)))
+	trx.Div(trx, limit)
+	if !trx.IsInt64() || trx.Int64() > (1<<63-1)/SunPerTrx {
+		return 0, fmt.Errorf("energy %d is out of range", energy)
+	}
+	return trx.Int64() * SunPerTrx, nil
+}
+
+// SunToEnergy converts a staked balance (in sun) into the energy it currently yields.
+func SunToEnergy(balance int64, res *api.AccountResourceMessage) int64 {
+	if res.GetTotalEnergyWeight() <= 0 {
+		return 0
+	}
+	energy := new(big.Int).Mul(big.NewInt(balance/SunPerTrx), big.NewInt(res.GetTotalEnergyLimit()))
+	energy.Div(energy, big.NewInt(res.GetTotalEnergyWeight()))
+	return energy.Int64()
+}
+
+// BalanceForEnergy queries the current network totals and returns the balance
+// (in sun) that must be delegated to provide the given energy.
+func (s *DelegationScheduler) BalanceForEnergy(energy int64) (int64, error) {
+	res, err := s.client.GetAccountResource(s.owner)
+	if err != nil {
+		return 0, fmt.Errorf("BalanceForEnergy: %w", err)
+	}
+	return EnergyToSun(energy, res)
+}
+
+// Delegate builds a transaction delegating enough staked TRX to provide the given
+// energy to the receiver for the duration, and starts tracking it as a lease.
+// A positive duration locks the delegation for the matching number of blocks.
+func (s *DelegationScheduler) Delegate(receiver string, energy int64, duration time.Duration) (*api.TransactionExtention, *EnergyLease, error) {
+	if duration <= 0 {
+		return nil, nil, fmt.Errorf("Delegate: duration must be greater than 0")
+	}
+	balance, err := s.BalanceForEnergy(energy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lockPeriod := int64((duration + BlockInterval - 1) / BlockInterval)
+	tx, err := s.client.DelegateResource(s.owner, receiver, core.ResourceCode_ENERGY, balance, true, lockPeriod)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, nil, fmt.Errorf("Delegate: %w", err)
+	}
+
+	now := s.now()
+	lease := &EnergyLease{
+		Receiver:   receiver,
+		Energy:     energy,
+		Balance:    balance,
+		Lock:       true,
+		LockPeriod: lockPeriod,
+		StartedAt:  now,
+		ExpiresAt:  now.Add(time.Duration(lockPeriod) * BlockInterval),
+		TxID:       tx.GetTxid(),
+	}
+
+	s.mu.Lock()
+	s.leases = append(s.leases, lease)
+	s.mu.Unlock()
+	return tx, lease, nil
+}
+
+// Track adds an existing delegation to the scheduler, e.g. after a restart.
+func (s *DelegationScheduler) Track(lease *EnergyLease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leases = append(s.leases, lease)
+}
+
+// Release stops tracking a lease without reclaiming it.
+func (s *DelegationScheduler) Release(lease *EnergyLease) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, l := range s.leases {
+		if l == lease {
+			s.leases = append(s.leases[:i], s.leases[i+1:]...)
+			return
+		}
+	}
+}
+
+// Leases returns a snapshot of the tracked leases.
+func (s *DelegationScheduler) Leases() []*EnergyLease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*EnergyLease(nil), s.leases...)
+}
+
+// Reclaim builds undelegate transactions for the expired leases, to be
+// broadcast by the caller. A lease stays tracked until its undelegate
+// transaction is confirmed; when the transaction fails or expires without
+// being included, the next call builds a new one. Leases whose transaction
+// could not be built or checked are retried by the next call; the first such
+// error is returned.
+func (s *DelegationScheduler) Reclaim() ([]*api.TransactionExtention, error) {
+	s.reclaim.Lock()
+	defer s.reclaim.Unlock()
+	now := s.now()
+
+	type candidate struct {
+		lease   *EnergyLease
+		pending []byte
+		expires time.Time
+	}
+	var candidates []candidate
+	s.mu.Lock()
+	for _, lease := range s.leases {
+		if lease.Expired(now) {
+			candidates = append(candidates, candidate{lease, lease.ReclaimTxID, lease.ReclaimExpiration})
+		}
+	}
+	s.mu.Unlock()
+
+	var (
+		txs       []*api.TransactionExtention
+		built     = make(map[*EnergyLease]*api.TransactionExtention)
+		confirmed = make(map[*EnergyLease]bool)
+		firstErr  error
+	)
+	fail := func(lease *EnergyLease, err error) {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("Reclaim: lease to %s: %w", lease.Receiver, err)
+		}
+	}
+	for _, c := range candidates {
+		if c.pending != nil {
+			info, err := s.client.GetTransactionInfoByID(fmt.Sprintf("%x", c.pending))
+			switch {
+			case err == nil && info.GetBlockNumber() > 0 && info.GetResult() != core.TransactionInfo_FAILED:
+				confirmed[c.lease] = true
+				continue
+			case err == nil && info.GetBlockNumber() > 0:
+				// The undelegation failed, build it again.
+			case err != nil && !errors.Is(err, ErrTransactionInfoNotFound):
+				fail(c.lease, err)
+				continue
+			case now.Before(c.expires):
+				continue
+			}
+		}
+
+		lease := c.lease
+		tx, err := s.client.UnDelegateResource(s.owner, lease.Receiver, core.ResourceCode_ENERGY, lease.Balance, lease.Lock)
+		if err == nil {
+			err = validateTx(tx)
+		}
+		if err != nil {
+			fail(lease, err)
+			continue
+		}
+		built[lease] = tx
+		txs = append(txs, tx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	active := s.leases[:0]
+	for _, lease := range s.leases {
+		if confirmed[lease] {
+			continue
+		}
+		if tx, ok := built[lease]; ok {
+			lease.ReclaimTxID = tx.GetTxid()
+			lease.ReclaimExpiration = time.UnixMilli(tx.GetTransaction().GetRawData().GetExpiration())
+		}
+		active = append(active, lease)
+	}
+	s.leases = active
+	return txs, firstErr
+}
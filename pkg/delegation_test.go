@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDelegationClient struct {
+	TronClient
+	resource    *api.AccountResourceMessage
+	delegated   []*core.DelegateResourceContract
+	undelegated []*core.UnDelegateResourceContract
+	receipts    map[string]*core.TransactionInfo
+	// during is called while a transaction is built.
+	during func()
+}
+
+func (f *fakeDelegationClient) GetAccountResource(string) (*api.AccountResourceMessage, error) {
+	return f.resource, nil
+}
+
+func (f *fakeDelegationClient) DelegateResource(from, to string, resource core.ResourceCode, balance int64, lock bool, lockPeriod int64) (*api.TransactionExtention, error) {
+	f.delegated = append(f.delegated, &core.DelegateResourceContract{Resource: resource, Balance: balance, Lock: lock, LockPeriod: lockPeriod})
+	return &api.TransactionExtention{Txid: []byte{1}, Result: &api.Return{Result: true}}, nil
+}
+
+func (f *fakeDelegationClient) UnDelegateResource(owner, receiver string, resource core.ResourceCode, balance int64, lock bool) (*api.TransactionExtention, error) {
+	if f.during != nil {
+		f.during()
+	}
+	f.undelegated = append(f.undelegated, &core.UnDelegateResourceContract{Resource: resource, Balance: balance})
+	return &api.TransactionExtention{
+		Txid:        []byte{2, byte(len(f.undelegated))},
+		Transaction: &core.Transaction{RawData: &core.TransactionRaw{Expiration: 1_700_003_600_000 + int64(len(f.undelegated))*60_000}},
+		Result:      &api.Return{Result: true},
+	}, nil
+}
+
+func (f *fakeDelegationClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	if info, ok := f.receipts[id]; ok {
+		return info, nil
+	}
+	return nil, fmt.Errorf("GetTransactionInfoByID: %w", ErrTransactionInfoNotFound)
+}
+
+func TestEnergyToSun(t *testing.T) {
+	res := &api.AccountResourceMessage{TotalEnergyLimit: 180_000_000_000, TotalEnergyWeight: 18_000_000_000}
+
+	sun, err := EnergyToSun(65_000, res)
+	require.Nil(t, err)
+	assert.Equal(t, int64(6_500*SunPerTrx), sun)
+
+	// Partial TRX are rounded up.
+	sun, err = EnergyToSun(65_001, res)
+	require.Nil(t, err)
+	assert.Equal(t, int64(6_501*SunPerTrx), sun)
+	assert.GreaterOrEqual(t, SunToEnergy(sun, res), int64(65_001))
+
+	_, err = EnergyToSun(1, &api.AccountResourceMessage{})
+	assert.NotNil(t, err)
+}
+
+func TestDelegationSchedulerReclaim(t *testing.T) {
+	client := &fakeDelegationClient{
+		resource: &api.AccountResourceMessage{TotalEnergyLimit: 100, TotalEnergyWeight: 10},
+		receipts: make(map[string]*core.TransactionInfo),
+	}
+	now := time.Unix(1_700_000_000, 0)
+	s := NewDelegationScheduler(client, "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL")
+	s.now = func() time.Time { return now }
+
+	_, lease, err := s.Delegate("TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", 100, time.Hour)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10*SunPerTrx), lease.Balance)
+	assert.Equal(t, int64(1200), client.delegated[0].LockPeriod)
+	assert.True(t, client.delegated[0].Lock)
+
+	txs, err := s.Reclaim()
+	require.Nil(t, err)
+	assert.Empty(t, txs)
+	assert.Len(t, s.Leases(), 1)
+
+	// The leases are not locked while the transactions are built.
+	client.during = func() { s.Leases() }
+	now = now.Add(time.Hour)
+	txs, err = s.Reclaim()
+	require.Nil(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, int64(10*SunPerTrx), client.undelegated[0].Balance)
+	require.Len(t, s.Leases(), 1)
+	assert.Equal(t, []byte{2, 1}, s.Leases()[0].ReclaimTxID)
+
+	// The lease is kept until the transaction is confirmed.
+	txs, err = s.Reclaim()
+	require.Nil(t, err)
+	assert.Empty(t, txs)
+	assert.Len(t, s.Leases(), 1)
+
+	// The transaction was not broadcast and expired: a new one is built.
+	now = now.Add(time.Minute)
+	txs, err = s.Reclaim()
+	require.Nil(t, err)
+	require.Len(t, txs, 1)
+	assert.Equal(t, []byte{2, 2}, s.Leases()[0].ReclaimTxID)
+
+	client.receipts["0202"] = &core.TransactionInfo{BlockNumber: 43}
+	txs, err = s.Reclaim()
+	require.Nil(t, err)
+	assert.Empty(t, txs)
+	assert.Empty(t, s.Leases())
+}
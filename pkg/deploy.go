@@ -0,0 +1,142 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"golang.org/x/crypto/sha3"
+)
+
+// deployOptions holds the optional settings of a contract deployment.
+type deployOptions struct {
+	args       []any
+	hasArgs    bool
+	callValue  int64
+	tokenID    string
+	tokenValue int64
+}
+
+// DeployOption configures a contract deployment.
+type DeployOption func(*deployOptions)
+
+// WithConstructorArgs encodes constructor arguments with the contract ABI and
+// appends them to the bytecode. Arguments are converted as by abi.ResolveMethod.
+func WithConstructorArgs(args ...any) DeployOption {
+	return func(o *deployOptions) {
+		o.args = args
+		o.hasArgs = true
+	}
+}
+
+// WithCallValue sends TRX, in sun, to a payable constructor.
+func WithCallValue(sun int64) DeployOption {
+	return func(o *deployOptions) {
+		o.callValue = sun
+	}
+}
+
+// WithTokenValue sends a TRC-10 token to a payable constructor.
+func WithTokenValue(tokenID string, amount int64) DeployOption {
+	return func(o *deployOptions) {
+		o.tokenID = tokenID
+		o.tokenValue = amount
+	}
+}
+
+// applyDeployOptions sets the constructor arguments and call values of a deployment.
+func applyDeployOptions(ct *core.CreateSmartContract, options []DeployOption) error {
+	o := new(deployOptions)
+	for _, opt := range options {
+		opt(o)
+	}
+
+	if o.hasArgs {
+		parsed, err := abi.ParseContractABI(ct.NewContract.GetAbi())
+		if err != nil {
+			return err
+		}
+		args, err := abi.PackConstructor(parsed, o.args...)
+		if err != nil {
+			return err
+		}
+		ct.NewContract.Bytecode = append(ct.NewContract.Bytecode, args...)
+	}
+
+	if o.callValue < 0 {
+		return fmt.Errorf("call value must not be negative")
+	}
+	ct.NewContract.CallValue = o.callValue
+
+	if o.tokenID != "" && o.tokenValue > 0 {
+		tokenID, err := strconv.ParseInt(o.tokenID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid token ID: %w", err)
+		}
+		ct.TokenId = tokenID
+		ct.CallTokenValue = o.tokenValue
+	}
+	return nil
+}
+
+// GenerateContractAddress computes the address of the contract created by a
+// CreateSmartContract transaction, as the node does: the last 20 bytes of the
+// Keccak-256 hash of the transaction ID followed by the owner address.
+// The fee limit and any other raw data must be final, as they change the transaction ID.
+func GenerateContractAddress(tx *core.Transaction) (string, error) {
+	contracts := tx.GetRawData().GetContract()
+	if len(contracts) == 0 || contracts[0].GetType() != core.Transaction_Contract_CreateSmartContract {
+		return "", fmt.Errorf("not a contract deployment transaction")
+	}
+	ct := new(core.CreateSmartContract)
+	if err := contracts[0].GetParameter().UnmarshalTo(ct); err != nil {
+		return "", fmt.Errorf("invalid deployment contract: %w", err)
+	}
+	txid, err := transactionID(tx.GetRawData())
+	if err != nil {
+		return "", err
+	}
+	return contractAddress(txid, ct.OwnerAddress), nil
+}
+
+// contractAddress derives a contract address from a transaction ID and the owner address.
+func contractAddress(txid, owner []byte) string {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(txid)
+	hasher.Write(owner)
+	hash := hasher.Sum(nil)
+	return base58.EncodeCheck(append([]byte{abi.TronAddressPrefix}, hash[12:]...))
+}
+
+// waitDeployedInterval is the polling interval of WaitDeployed.
+var waitDeployedInterval = BlockInterval
+
+// WaitDeployed polls the node until the contract at contractAddress has code,
+// or the timeout expires. It returns the deployed contract.
+func WaitDeployed(client TronClient, contractAddress string, timeout time.Duration) (*core.SmartContract, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		sc, err := client.GetContract(contractAddress)
+		if err == nil && len(sc.GetBytecode()) > 0 {
+			return sc, nil
+		}
+		if time.Now().Add(waitDeployedInterval).After(deadline) {
+			if err != nil {
+				return nil, fmt.Errorf("WaitDeployed: contract %s not deployed: %w", contractAddress, err)
+			}
+			return nil, fmt.Errorf("WaitDeployed: contract %s not deployed after %s", contractAddress, timeout)
+		}
+		time.Sleep(waitDeployedInterval)
+	}
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var tokenConstructorABI = &core.SmartContract_ABI{Entrys: []*core.SmartContract_ABI_Entry{{
+	Type: core.SmartContract_ABI_Entry_Constructor,
+	Inputs: []*core.SmartContract_ABI_Entry_Param{
+		{Name: "name", Type: "string"},
+		{Name: "supply", Type: "uint256"},
+	},
+	StateMutability: core.SmartContract_ABI_Entry_Payable,
+}}}
+
+func TestDeployContractAddress(t *testing.T) {
+	owner := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL"
+	ownerBytes, _ := base58.DecodeCheck(owner)
+
+	ct, err := newCreateSmartContract(owner, "Token", tokenConstructorABI, "6080", 100, 10_000_000, []DeployOption{
+		WithConstructorArgs("Token", 1_000_000),
+		WithCallValue(5),
+		WithTokenValue("1000001", 7),
+	})
+	require.Nil(t, err)
+	assert.Len(t, ct.NewContract.Bytecode, 2+4*32)
+	assert.Equal(t, []byte{0x60, 0x80}, ct.NewContract.Bytecode[:2])
+	assert.Equal(t, int64(5), ct.NewContract.CallValue)
+	assert.Equal(t, int64(1000001), ct.TokenId)
+	assert.Equal(t, int64(7), ct.CallTokenValue)
+
+	_, err = newCreateSmartContract(owner, "Token", tokenConstructorABI, "6080", 100, 10_000_000, []DeployOption{WithConstructorArgs("Token")})
+	assert.ErrorContains(t, err, "constructor expects 2 arguments")
+
+	param, err := anypb.New(ct)
+	require.Nil(t, err)
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Contract: []*core.Transaction_Contract{{Type: core.Transaction_Contract_CreateSmartContract, Parameter: param}},
+		FeeLimit: 100_000_000,
+	}}
+	addr, err := GenerateContractAddress(tx)
+	require.Nil(t, err)
+
+	txid, err := transactionID(tx.RawData)
+	require.Nil(t, err)
+	hash := crypto.Keccak256(append(txid, ownerBytes...))
+	assert.Equal(t, base58.EncodeCheck(append([]byte{0x41}, hash[12:]...)), addr)
+
+	tx.RawData.FeeLimit++
+	other, err := GenerateContractAddress(tx)
+	require.Nil(t, err)
+	assert.NotEqual(t, addr, other)
+}
+
+type fakeDeployClient struct {
+	TronClient
+	calls int
+}
+
+func (f *fakeDeployClient) GetContract(string) (*core.SmartContract, error) {
+	f.calls++
+	if f.calls < 3 {
+		return &core.SmartContract{}, nil
+	}
+	return &core.SmartContract{Bytecode: []byte{0x60, 0x80}}, nil
+}
+
+func TestWaitDeployed(t *testing.T) {
+	defer func(interval time.Duration) { waitDeployedInterval = interval }(waitDeployedInterval)
+	waitDeployedInterval = time.Millisecond
+
+	client := &fakeDeployClient{}
+	sc, err := WaitDeployed(client, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", time.Second)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0x60, 0x80}, sc.Bytecode)
+	assert.Equal(t, 3, client.calls)
+
+	_, err = WaitDeployed(&fakeDeployClient{calls: -1000}, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", 10*time.Millisecond)
+	assert.ErrorContains(t, err, "not deployed")
+}
@@ -0,0 +1,49 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pkg/abi"
+)
+
+// FactoryDeployment describes a child contract deployed by a CREATE2 factory.
+type FactoryDeployment struct {
+	Factory string
+	// Method and Params are the factory call, as for TriggerContract, e.g.
+	// "deploy(bytes32)" with `[{"bytes32": "..."}]`.
+	Method string
+	Params string
+	// Salt and InitCode are the CREATE2 inputs used by the factory; InitCode is
+	// the creation bytecode of the child followed by its constructor arguments.
+	Salt     [32]byte
+	InitCode []byte
+}
+
+// Address returns the address the child contract will be deployed at.
+func (d *FactoryDeployment) Address() (string, error) {
+	return abi.Create2Address(d.Factory, d.Salt, d.InitCode)
+}
+
+// DeployViaFactory calls the factory with TriggerContract and returns the
+// transaction with the predicted address of the child contract. The child exists
+// once the transaction is confirmed; see WaitDeployed.
+func DeployViaFactory(client TronClient, from string, d *FactoryDeployment, feeLimit int64) (*api.TransactionExtention, string, error) {
+	addr, err := d.Address()
+	if err != nil {
+		return nil, "", fmt.Errorf("DeployViaFactory: %w", err)
+	}
+	tx, err := client.TriggerContract(from, d.Factory, d.Method, d.Params, feeLimit, 0, "", 0)
+	if err != nil {
+		return nil, "", fmt.Errorf("DeployViaFactory: %w", err)
+	}
+	return tx, addr, nil
+}
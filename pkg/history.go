@@ -0,0 +1,233 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrExtensionUnavailable is returned when the node does not enable the WalletExtension service.
+var ErrExtensionUnavailable = errors.New("wallet extension service is not enabled on this node")
+
+// GetTransactionsFromThis queries transactions sent by an account.
+func (g *GrpcClient) GetTransactionsFromThis(addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	req, err := getAccountPaginated(addr, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionsFromThis: %w", err)
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	result, err := g.Extension.GetTransactionsFromThis2(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionsFromThis RPC error: %w", extensionError(err))
+	}
+	return result, nil
+}
+
+// GetTransactionsToThis queries transactions received by an account.
+func (g *GrpcClient) GetTransactionsToThis(addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	req, err := getAccountPaginated(addr, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionsToThis: %w", err)
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	result, err := g.Extension.GetTransactionsToThis2(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionsToThis RPC error: %w", extensionError(err))
+	}
+	return result, nil
+}
+
+// getAccountPaginated builds an AccountPaginated request for a base58 address.
+func getAccountPaginated(addr string, offset, limit int64) (*api.AccountPaginated, error) {
+	addrBytes, err := base58.DecodeCheck(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address: %w", err)
+	}
+	return &api.AccountPaginated{
+		Account: &core.Account{Address: addrBytes},
+		Offset:  offset,
+		Limit:   limit,
+	}, nil
+}
+
+// extensionError maps an unimplemented service error to ErrExtensionUnavailable.
+func extensionError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return ErrExtensionUnavailable
+	}
+	return err
+}
+
+// HistoryDirection tells whether a history entry was sent or received by the account.
+type HistoryDirection int
+
+const (
+	// Outgoing transactions are sent by the account.
+	Outgoing HistoryDirection = iota
+	// Incoming transactions are received by the account.
+	Incoming
+)
+
+// String returns the name of the direction.
+func (d HistoryDirection) String() string {
+	if d == Incoming {
+		return "in"
+	}
+	return "out"
+}
+
+// HistoryEntry is a transaction of an account's history with its transfer details decoded.
+type HistoryEntry struct {
+	Tx           *api.TransactionExtention
+	Type         core.Transaction_Contract_ContractType
+	Direction    HistoryDirection
+	Counterparty string // base58 address, empty when the contract has none
+	Amount       int64  // amount in sun, or in token units for asset transfers
+	AssetName    string // set for TRC-10 transfers
+}
+
+// AccountHistory iterates over an account's transactions page by page.
+//
+//	h := pkg.NewAccountHistory(client, addr, pkg.Incoming, 50)
+//	for h.Next() {
+//		entry := h.Entry()
+//		...
+//	}
+//	if err := h.Err(); err != nil {
+//		...
+//	}
+type AccountHistory struct {
+	client    TronClient
+	address   string
+	direction HistoryDirection
+	pageSize  int64
+
+	offset int64
+	page   []*api.TransactionExtention
+	entry  *HistoryEntry
+	done   bool
+	err    error
+}
+
+// NewAccountHistory creates an iterator over the transactions of an account in the given direction.
+// A pageSize of 0 or less uses a page size of 50.
+func NewAccountHistory(client TronClient, addr string, direction HistoryDirection, pageSize int64) *AccountHistory {
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	return &AccountHistory{
+		client:    client,
+		address:   addr,
+		direction: direction,
+		pageSize:  pageSize,
+	}
+}
+
+// Next advances to the next entry, fetching a new page when needed.
+// It returns false when the history is exhausted or an error occurred.
+func (h *AccountHistory) Next() bool {
+	for len(h.page) == 0 {
+		if h.done || h.err != nil {
+			return false
+		}
+		h.fetch()
+	}
+
+	tx := h.page[0]
+	h.page = h.page[1:]
+	h.entry = decodeHistoryEntry(tx, h.direction)
+	return true
+}
+
+// Entry returns the current entry.
+func (h *AccountHistory) Entry() *HistoryEntry {
+	return h.entry
+}
+
+// Err returns the error that stopped the iteration, if any.
+// It matches ErrExtensionUnavailable when the node lacks the extension service.
+func (h *AccountHistory) Err() error {
+	return h.err
+}
+
+// fetch loads the next page of transactions.
+func (h *AccountHistory) fetch() {
+	var (
+		list *api.TransactionListExtention
+		err  error
+	)
+	if h.direction == Incoming {
+		list, err = h.client.GetTransactionsToThis(h.address, h.offset, h.pageSize)
+	} else {
+		list, err = h.client.GetTransactionsFromThis(h.address, h.offset, h.pageSize)
+	}
+	if err != nil {
+		h.err = err
+		return
+	}
+
+	h.page = list.GetTransaction()
+	h.offset += int64(len(h.page))
+	if int64(len(h.page)) < h.pageSize {
+		h.done = true
+	}
+}
+
+// decodeHistoryEntry extracts the counterparty and amount from the first contract of a transaction.
+func decodeHistoryEntry(tx *api.TransactionExtention, direction HistoryDirection) *HistoryEntry {
+	entry := &HistoryEntry{Tx: tx, Direction: direction}
+
+	contracts := tx.GetTransaction().GetRawData().GetContract()
+	if len(contracts) == 0 {
+		return entry
+	}
+	entry.Type = contracts[0].GetType()
+
+	dc, err := DecodeContract(contracts[0])
+	if err != nil {
+		return entry
+	}
+
+	var other string
+	switch v := dc.Value.(type) {
+	case *TransferValue:
+		other, entry.Amount = v.To, v.Amount
+	case *TransferAssetValue:
+		other, entry.Amount, entry.AssetName = v.To, v.Amount, v.AssetName
+	case *TriggerSmartContractValue:
+		other, entry.Amount = v.Contract, v.CallValue
+	case *DelegateResourceValue:
+		other, entry.Amount = v.Receiver, v.Balance
+	case *UnDelegateResourceValue:
+		other, entry.Amount = v.Receiver, v.Balance
+	case *FreezeBalanceV2Value:
+		entry.Amount = v.Amount
+	case *UnfreezeBalanceV2Value:
+		entry.Amount = v.Amount
+	}
+
+	entry.Counterparty = other
+	if direction == Incoming && other != "" {
+		entry.Counterparty = dc.Owner
+	}
+	return entry
+}
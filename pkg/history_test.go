@@ -0,0 +1,69 @@
+package pkg
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type fakeHistoryClient struct {
+	TronClient
+	txs []*api.TransactionExtention
+	err error
+}
+
+func (f *fakeHistoryClient) GetTransactionsToThis(addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	end := min(offset+limit, int64(len(f.txs)))
+	return &api.TransactionListExtention{Transaction: f.txs[offset:end]}, nil
+}
+
+func newTransferTx(t *testing.T, from, to string, amount int64) *api.TransactionExtention {
+	owner, err := base58.DecodeCheck(from)
+	require.Nil(t, err)
+	receiver, err := base58.DecodeCheck(to)
+	require.Nil(t, err)
+
+	param, err := anypb.New(&core.TransferContract{OwnerAddress: owner, ToAddress: receiver, Amount: amount})
+	require.Nil(t, err)
+	return &api.TransactionExtention{
+		Transaction: &core.Transaction{RawData: &core.TransactionRaw{
+			Contract: []*core.Transaction_Contract{{Type: core.Transaction_Contract_TransferContract, Parameter: param}},
+		}},
+	}
+}
+
+func TestAccountHistory(t *testing.T) {
+	from, to := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL", "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9"
+	client := &fakeHistoryClient{}
+	for i := 1; i <= 5; i++ {
+		client.txs = append(client.txs, newTransferTx(t, from, to, int64(i)))
+	}
+
+	h := NewAccountHistory(client, to, Incoming, 2)
+	var amounts []int64
+	for h.Next() {
+		entry := h.Entry()
+		assert.Equal(t, from, entry.Counterparty)
+		assert.Equal(t, Incoming, entry.Direction)
+		amounts = append(amounts, entry.Amount)
+	}
+	require.Nil(t, h.Err())
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, amounts)
+}
+
+func TestAccountHistoryUnavailable(t *testing.T) {
+	client := &fakeHistoryClient{err: fmt.Errorf("GetTransactionsToThis RPC error: %w", ErrExtensionUnavailable)}
+
+	h := NewAccountHistory(client, "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", Incoming, 0)
+	assert.False(t, h.Next())
+	assert.ErrorIs(t, h.Err(), ErrExtensionUnavailable)
+}
@@ -0,0 +1,1362 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	hex "github.com/dszi/go-tron/common/hexutil"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"google.golang.org/protobuf/proto"
+)
+
+// solidityPaths lists the queries that are also served under /walletsolidity.
+var solidityPaths = map[string]bool{
+	"getaccount":                 true,
+	"listwitnesses":              true,
+	"getassetissuelist":          true,
+	"getpaginatedassetissuelist": true,
+	"getassetissuebyname":        true,
+	"getassetissuebyid":          true,
+	"getnowblock":                true,
+	"getblockbynum":              true,
+	"getblockbyid":               true,
+	"gettransactionbyid":         true,
+	"gettransactioninfobyid":     true,
+	"getReward":                  true,
+	"getBrokerage":               true,
+	"getmarketorderbyaccount":    true,
+	"getmarketorderbyid":         true,
+	"getmarketpricebypair":       true,
+	"getmarketorderlistbypair":   true,
+	"getmarketpairlist":          true,
+	"getburntrx":                 true,
+	"getbandwidthprices":         true,
+	"getenergyprices":            true,
+}
+
+// HTTPClient implements TronClient over the full node HTTP API (/wallet/* and
+// /walletsolidity/*), for environments where the gRPC port is not reachable.
+type HTTPClient struct {
+	BaseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	apiKey     string
+	solidity   bool
+	abis       *ABICache
+	abisOnce   sync.Once
+}
+
+// NewHTTPClient creates a new HTTPClient for the node at baseURL, e.g. "https://api.trongrid.io".
+// WithTimeout, WithAPIKey, WithHTTPClient and WithSolidity apply; WithDialOptions is ignored.
+func NewHTTPClient(baseURL string, options ...HTTPOption) TronClient {
+	o := newHTTPOptions(options)
+	client := &HTTPClient{
+		BaseURL:    baseURL,
+		httpClient: o.httpClient,
+		timeout:    o.timeout,
+		apiKey:     o.apiKey,
+		solidity:   o.solidity,
+	}
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+	return client
+}
+
+// Start validates the base URL.
+func (h *HTTPClient) Start() error {
+	if h.BaseURL == "" {
+		h.BaseURL = "https://api.trongrid.io"
+	}
+	u, err := url.Parse(h.BaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid HTTP node URL %q", h.BaseURL)
+	}
+	h.BaseURL = strings.TrimRight(h.BaseURL, "/")
+	return nil
+}
+
+// Stop closes idle connections.
+func (h *HTTPClient) Stop() {
+	h.httpClient.CloseIdleConnections()
+}
+
+// Reconnect restarts the client with an optional new URL.
+func (h *HTTPClient) Reconnect(url string) error {
+	h.Stop()
+	if url != "" {
+		h.BaseURL = url
+	}
+	return h.Start()
+}
+
+// getContext returns a context with the request timeout.
+// The API key is sent as the TRON-PRO-API-KEY header of each request.
+func (h *HTTPClient) getContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), h.timeout)
+}
+
+// do posts a JSON body to the node and returns the response body.
+// The body may be nil, a proto.Message or a map.
+func (h *HTTPClient) do(path string, body any) ([]byte, error) {
+	var (
+		payload any = map[string]any{}
+		err     error
+	)
+	switch b := body.(type) {
+	case nil:
+	case proto.Message:
+		if payload, err = toNodeJSON(b); err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	default:
+		payload = b
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	ctx, cancel := h.getContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", h.apiKey)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &httpStatusError{code: resp.StatusCode, body: string(respBody)}
+	}
+
+	var nodeErr struct {
+		Error string `json:"Error"`
+	}
+	if json.Unmarshal(respBody, &nodeErr) == nil && nodeErr.Error != "" {
+		return nil, fmt.Errorf("%s", nodeErr.Error)
+	}
+	return respBody, nil
+}
+
+// httpStatusError reports a non-200 HTTP response.
+type httpStatusError struct {
+	code int
+	body string
+}
+
+func (e *httpStatusError) Error() string {
+	if len(e.body) > 200 {
+		return fmt.Sprintf("HTTP %d: %s...", e.code, e.body[:200])
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.code, e.body)
+}
+
+// post calls a /wallet endpoint and decodes the response into out.
+func (h *HTTPClient) post(path string, body any, out proto.Message) error {
+	data, err := h.do(path, body)
+	if err != nil {
+		return err
+	}
+	return fromNodeJSON(data, out)
+}
+
+// queryPath returns the path of a query, on the solidity node when configured and supported.
+func (h *HTTPClient) queryPath(name string) string {
+	if h.solidity && solidityPaths[name] {
+		return "/walletsolidity/" + name
+	}
+	return "/wallet/" + name
+}
+
+// query calls a read-only endpoint and decodes the response into out.
+func (h *HTTPClient) query(name string, body any, out proto.Message) error {
+	return h.post(h.queryPath(name), body, out)
+}
+
+// queryNumber calls a read-only endpoint returning a single number under key.
+func (h *HTTPClient) queryNumber(name string, body any, key string) (int64, error) {
+	data, err := h.do(h.queryPath(name), body)
+	if err != nil {
+		return 0, err
+	}
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return 0, err
+	}
+	num, ok := obj[key].(json.Number)
+	if !ok {
+		return 0, nil
+	}
+	return num.Int64()
+}
+
+// postTransaction calls a transaction-building endpoint. The node answers either
+// with a transaction or with a transaction extension wrapping it.
+func (h *HTTPClient) postTransaction(path string, body any) (*api.TransactionExtention, error) {
+	data, err := h.do(path, body)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(api.TransactionExtention)
+	if _, ok := obj["transaction"]; ok || obj["result"] != nil {
+		if err := fromNodeJSON(data, tx); err != nil {
+			return nil, err
+		}
+	} else {
+		tx.Transaction = new(core.Transaction)
+		if err := fromNodeJSON(data, tx.Transaction); err != nil {
+			return nil, err
+		}
+		tx.Result = &api.Return{Result: true}
+	}
+	if tx.GetTransaction().GetRawData() != nil && len(tx.Txid) == 0 {
+		if tx.Txid, err = transactionID(tx.Transaction.RawData); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	if tx.GetTransaction().GetRawData() == nil {
+		return nil, fmt.Errorf("bad transaction")
+	}
+	return tx, nil
+}
+
+// getBlock fetches a block and converts it into a block extension.
+func (h *HTTPClient) getBlock(name string, body any) (*api.BlockExtention, error) {
+	data, err := h.do(h.queryPath(name), body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlock(data)
+}
+
+// decodeBlock converts a block returned by the node into a block extension.
+func decodeBlock(data []byte) (*api.BlockExtention, error) {
+	block := new(core.Block)
+	if err := fromNodeJSON(data, block); err != nil {
+		return nil, err
+	}
+	var id struct {
+		BlockID string `json:"blockID"`
+	}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, err
+	}
+	blockID, err := hex.FromHex(id.BlockID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block ID: %w", err)
+	}
+
+	ext := &api.BlockExtention{BlockHeader: block.BlockHeader, Blockid: blockID}
+	for _, tx := range block.Transactions {
+		txid, err := transactionID(tx.GetRawData())
+		if err != nil {
+			return nil, err
+		}
+		ext.Transactions = append(ext.Transactions, &api.TransactionExtention{Transaction: tx, Txid: txid})
+	}
+	return ext, nil
+}
+
+// addressBody builds a {"address": hex} request body from a base58 address.
+func addressBody(addr string) (map[string]any, error) {
+	b, err := base58.DecodeCheck(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address: %w", err)
+	}
+	return map[string]any{"address": fmt.Sprintf("%x", b)}, nil
+}
+
+// GetAccount retrieves account information by address.
+func (h *HTTPClient) GetAccount(addr string) (*core.Account, error) {
+	body, err := addressBody(addr)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccount: %w", err)
+	}
+	acc := new(core.Account)
+	if err := h.query("getaccount", body, acc); err != nil {
+		return nil, fmt.Errorf("GetAccount HTTP error: %w", err)
+	}
+	if fmt.Sprintf("%x", acc.Address) != body["address"] {
+		return nil, fmt.Errorf("account not found")
+	}
+	return acc, nil
+}
+
+// GetAccountBalance retrieves the account balance.
+func (h *HTTPClient) GetAccountBalance(addr string) (int64, error) {
+	acc, err := h.GetAccount(addr)
+	if err != nil {
+		return 0, err
+	}
+	return acc.Balance, nil
+}
+
+// GetAccountResource retrieves the account resource information.
+func (h *HTTPClient) GetAccountResource(addr string) (*api.AccountResourceMessage, error) {
+	body, err := addressBody(addr)
+	if err != nil {
+		return nil, fmt.Errorf("GetAccountResource: %w", err)
+	}
+	resource := new(api.AccountResourceMessage)
+	if err := h.query("getaccountresource", body, resource); err != nil {
+		return nil, fmt.Errorf("GetAccountResource HTTP error: %w", err)
+	}
+	return resource, nil
+}
+
+// CreateAccount creates a new account.
+func (h *HTTPClient) CreateAccount(from, addr string) (*api.TransactionExtention, error) {
+	contract := new(core.AccountCreateContract)
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("failed to decode from address: %w", err)
+	}
+	if contract.AccountAddress, err = base58.DecodeCheck(addr); err != nil {
+		return nil, fmt.Errorf("failed to decode target address: %w", err)
+	}
+	return h.postTransaction("/wallet/createaccount", contract)
+}
+
+// UpdateAccount updates the account name.
+func (h *HTTPClient) UpdateAccount(from, accountName string) (*api.TransactionExtention, error) {
+	contract := &core.AccountUpdateContract{AccountName: []byte(accountName)}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/updateaccount", contract)
+}
+
+// GetRewardInfo queries the unclaimed reward.
+func (h *HTTPClient) GetRewardInfo(addr string) (int64, error) {
+	body, err := addressBody(addr)
+	if err != nil {
+		return 0, err
+	}
+	reward, err := h.queryNumber("getReward", body, "reward")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reward info: %w", err)
+	}
+	return reward, nil
+}
+
+// CreateTransaction creates a TRX transfer transaction.
+func (h *HTTPClient) CreateTransaction(from, toAddress string, amount int64) (*api.TransactionExtention, error) {
+	contract := &core.TransferContract{Amount: amount}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("CreateTransaction: failed to decode from address: %w", err)
+	}
+	if contract.ToAddress, err = base58.DecodeCheck(toAddress); err != nil {
+		return nil, fmt.Errorf("CreateTransaction: failed to decode to address: %w", err)
+	}
+	tx, err := h.postTransaction("/wallet/createtransaction", contract)
+	if err != nil {
+		return nil, fmt.Errorf("CreateTransaction: %w", err)
+	}
+	return tx, nil
+}
+
+// BroadcastTransaction broadcasts a signed transaction to the network.
+// It returns an error if the broadcast result indicates failure.
+func (h *HTTPClient) BroadcastTransaction(tx *core.Transaction) (*api.Return, error) {
+	raw, err := proto.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("BroadcastTransaction: %w", err)
+	}
+
+	result := new(api.Return)
+	if err := h.post("/wallet/broadcasthex", map[string]any{"transaction": fmt.Sprintf("%x", raw)}, result); err != nil {
+		return nil, fmt.Errorf("BroadcastTransaction HTTP error: %w", err)
+	}
+	if !result.GetResult() {
+		return result, fmt.Errorf("BroadcastTransaction: result error: %s", result.GetMessage())
+	}
+	if result.GetCode() != api.Return_SUCCESS {
+		return result, fmt.Errorf("BroadcastTransaction: result error (%s): %s", result.GetCode(), result.GetMessage())
+	}
+	return result, nil
+}
+
+// idBody builds a {"value": hex} request body from a hex ID.
+func idBody(id string) (map[string]any, error) {
+	b, err := hex.FromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"value": fmt.Sprintf("%x", b)}, nil
+}
+
+// GetTransactionByID retrieves a transaction by its ID.
+func (h *HTTPClient) GetTransactionByID(id string) (*core.Transaction, error) {
+	body, err := idBody(id)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionByID: failed to decode id: %w", err)
+	}
+	tx := new(core.Transaction)
+	if err := h.query("gettransactionbyid", body, tx); err != nil {
+		return nil, fmt.Errorf("GetTransactionByID HTTP error: %w", err)
+	}
+	if proto.Size(tx) == 0 {
+		return nil, fmt.Errorf("GetTransactionByID: transaction info not found")
+	}
+	return tx, nil
+}
+
+// GetTransactionInfoByID queries transaction fee and block information by transaction ID.
+func (h *HTTPClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	body, err := idBody(id)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionInfoByID: failed to decode id: %w", err)
+	}
+	txi := new(core.TransactionInfo)
+	if err := h.query("gettransactioninfobyid", body, txi); err != nil {
+		return nil, fmt.Errorf("GetTransactionInfoByID HTTP error: %w", err)
+	}
+	if fmt.Sprintf("%x", txi.Id) != body["value"] {
+		return nil, fmt.Errorf("GetTransactionInfoByID: %w", ErrTransactionInfoNotFound)
+	}
+	return txi, nil
+}
+
+// GetTransactionFromPending retrieves a pending transaction by its ID.
+func (h *HTTPClient) GetTransactionFromPending(id string) (*core.Transaction, error) {
+	body, err := idBody(id)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransactionFromPending: failed to decode id: %w", err)
+	}
+	tx := new(core.Transaction)
+	if err := h.post("/wallet/gettransactionfrompending", body, tx); err != nil {
+		return nil, fmt.Errorf("GetTransactionFromPending: %w", err)
+	}
+	return tx, nil
+}
+
+// GetTransactionListFromPending retrieves the list of pending transaction IDs.
+func (h *HTTPClient) GetTransactionListFromPending() (*api.TransactionIdList, error) {
+	result := new(api.TransactionIdList)
+	if err := h.post("/wallet/gettransactionlistfrompending", nil, result); err != nil {
+		return nil, fmt.Errorf("GetTransactionListFromPending: %w", err)
+	}
+	return result, nil
+}
+
+// TotalTransaction retrieves the total number of transactions.
+func (h *HTTPClient) TotalTransaction() (*api.NumberMessage, error) {
+	num, err := h.queryNumber("totaltransaction", nil, "num")
+	if err != nil {
+		return nil, fmt.Errorf("TotalTransaction error: %w", err)
+	}
+	return GetMessageNumber(num), nil
+}
+
+// GetTransactionsFromThis queries transactions sent by an account.
+func (h *HTTPClient) GetTransactionsFromThis(addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	return h.getTransactionsPaginated("/walletextension/gettransactionsfromthis", addr, offset, limit)
+}
+
+// GetTransactionsToThis queries transactions received by an account.
+func (h *HTTPClient) GetTransactionsToThis(addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	return h.getTransactionsPaginated("/walletextension/gettransactionstothis", addr, offset, limit)
+}
+
+// getTransactionsPaginated calls a wallet extension endpoint, reporting
+// ErrExtensionUnavailable when the node does not serve it.
+func (h *HTTPClient) getTransactionsPaginated(path, addr string, offset, limit int64) (*api.TransactionListExtention, error) {
+	req, err := getAccountPaginated(addr, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := new(api.TransactionListExtention)
+	if err := h.post(path, req, result); err != nil {
+		if se, ok := err.(*httpStatusError); ok && se.code == http.StatusNotFound {
+			return nil, ErrExtensionUnavailable
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// FreezeBalance stakes TRX (deprecated, use FreezeBalanceV2 instead).
+func (h *HTTPClient) FreezeBalance(from, delegateTo string, resource core.ResourceCode, frozenBalance int64) (*api.TransactionExtention, error) {
+	contract := &core.FreezeBalanceContract{FrozenBalance: frozenBalance, FrozenDuration: 3, Resource: resource}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("FreezeBalance: failed to decode from address: %w", err)
+	}
+	if delegateTo != "" {
+		if contract.ReceiverAddress, err = base58.DecodeCheck(delegateTo); err != nil {
+			return nil, fmt.Errorf("FreezeBalance: failed to decode delegateTo address: %w", err)
+		}
+	}
+	return h.postTransaction("/wallet/freezebalance", contract)
+}
+
+// UnfreezeBalance unstakes TRX staked during Stake1.0.
+func (h *HTTPClient) UnfreezeBalance(from, delegateTo string, resource core.ResourceCode) (*api.TransactionExtention, error) {
+	contract := &core.UnfreezeBalanceContract{Resource: resource}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("UnfreezeBalance: failed to decode from address: %w", err)
+	}
+	if delegateTo != "" {
+		if contract.ReceiverAddress, err = base58.DecodeCheck(delegateTo); err != nil {
+			return nil, fmt.Errorf("UnfreezeBalance: failed to decode delegateTo address: %w", err)
+		}
+	}
+	return h.postTransaction("/wallet/unfreezebalance", contract)
+}
+
+// WithdrawBalance redeems block producing reward.
+func (h *HTTPClient) WithdrawBalance(from string) (*api.TransactionExtention, error) {
+	contract := &core.WithdrawBalanceContract{}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("WithdrawBalance: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/withdrawbalance", contract)
+}
+
+// UnfreezeAsset unstakes token balance.
+func (h *HTTPClient) UnfreezeAsset(from string) (*api.TransactionExtention, error) {
+	contract := &core.UnfreezeAssetContract{}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("UnfreezeAsset: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/unfreezeasset", contract)
+}
+
+// FreezeBalanceV2 stakes TRX for bandwidth or energy (Stake 2.0).
+func (h *HTTPClient) FreezeBalanceV2(from string, resource core.ResourceCode, frozenBalance int64) (*api.TransactionExtention, error) {
+	contract := &core.FreezeBalanceV2Contract{FrozenBalance: frozenBalance, Resource: resource}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("FreezeBalanceV2: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/freezebalancev2", contract)
+}
+
+// UnfreezeBalanceV2 unfreezes TRX (new version).
+func (h *HTTPClient) UnfreezeBalanceV2(from string, resource core.ResourceCode, unfreezeBalance int64) (*api.TransactionExtention, error) {
+	contract := &core.UnfreezeBalanceV2Contract{UnfreezeBalance: unfreezeBalance, Resource: resource}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("UnfreezeBalanceV2: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/unfreezebalancev2", contract)
+}
+
+// WithdrawExpireUnfreeze withdraws staked TRX after expiration.
+func (h *HTTPClient) WithdrawExpireUnfreeze(from string, timestamp int64) (*api.TransactionExtention, error) {
+	contract := &core.WithdrawExpireUnfreezeContract{}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("WithdrawExpireUnfreeze: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/withdrawexpireunfreeze", contract)
+}
+
+// DelegateResource delegates resources for staking.
+func (h *HTTPClient) DelegateResource(from, to string, resource core.ResourceCode, delegateBalance int64, lock bool, lockPeriod int64) (*api.TransactionExtention, error) {
+	contract := &core.DelegateResourceContract{Resource: resource, Balance: delegateBalance, Lock: lock, LockPeriod: lockPeriod}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("DelegateResource: failed to decode from address: %w", err)
+	}
+	if contract.ReceiverAddress, err = base58.DecodeCheck(to); err != nil {
+		return nil, fmt.Errorf("DelegateResource: failed to decode to address: %w", err)
+	}
+	return h.postTransaction("/wallet/delegateresource", contract)
+}
+
+// UnDelegateResource revokes delegated resources.
+func (h *HTTPClient) UnDelegateResource(owner, receiver string, resource core.ResourceCode, delegateBalance int64, lock bool) (*api.TransactionExtention, error) {
+	contract := &core.UnDelegateResourceContract{Resource: resource, Balance: delegateBalance}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(owner); err != nil {
+		return nil, fmt.Errorf("UnDelegateResource: failed to decode owner address: %w", err)
+	}
+	if contract.ReceiverAddress, err = base58.DecodeCheck(receiver); err != nil {
+		return nil, fmt.Errorf("UnDelegateResource: failed to decode receiver address: %w", err)
+	}
+	return h.postTransaction("/wallet/undelegateresource", contract)
+}
+
+// CancelAllUnfreezeV2 cancels all pending unfreeze operations.
+func (h *HTTPClient) CancelAllUnfreezeV2(from string) (*api.TransactionExtention, error) {
+	contract := &core.CancelAllUnfreezeV2Contract{}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("CancelAllUnfreezeV2: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/cancelallunfreezev2", contract)
+}
+
+// VoteWitnessAccount submits a vote for super representative candidates.
+func (h *HTTPClient) VoteWitnessAccount(from string, witnessMap map[string]int64) (*api.TransactionExtention, error) {
+	contract, err := newVoteWitnessContract(from, witnessMap)
+	if err != nil {
+		return nil, err
+	}
+	return h.postTransaction("/wallet/votewitnessaccount", contract)
+}
+
+// ListWitnesses queries the list of super representative candidates.
+func (h *HTTPClient) ListWitnesses() (*api.WitnessList, error) {
+	witnessList := new(api.WitnessList)
+	if err := h.query("listwitnesses", nil, witnessList); err != nil {
+		return nil, fmt.Errorf("ListWitnesses HTTP error: %w", err)
+	}
+	return witnessList, nil
+}
+
+// CreateWitness applies to become a super representative candidate.
+func (h *HTTPClient) CreateWitness(from, urlStr string) (*api.TransactionExtention, error) {
+	contract := &core.WitnessCreateContract{Url: []byte(urlStr)}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("CreateWitness: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/createwitness", contract)
+}
+
+// UpdateWitness updates the website URL of a super representative candidate.
+func (h *HTTPClient) UpdateWitness(from, urlStr string) (*api.TransactionExtention, error) {
+	contract := &core.WitnessUpdateContract{UpdateUrl: []byte(urlStr)}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("UpdateWitness: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/updatewitness", contract)
+}
+
+// GetBrokerageInfo queries the brokerage ratio of a witness, in percent.
+func (h *HTTPClient) GetBrokerageInfo(witness string) (float64, error) {
+	body, err := addressBody(witness)
+	if err != nil {
+		return 0, fmt.Errorf("GetBrokerageInfo: %w", err)
+	}
+	brokerage, err := h.queryNumber("getBrokerage", body, "brokerage")
+	if err != nil {
+		return 0, fmt.Errorf("GetBrokerageInfo HTTP error: %w", err)
+	}
+	return float64(brokerage), nil
+}
+
+// UpdateBrokerage updates the brokerage ratio.
+func (h *HTTPClient) UpdateBrokerage(from string, brokerage int32) (*api.TransactionExtention, error) {
+	contract := &core.UpdateBrokerageContract{Brokerage: brokerage}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("UpdateBrokerage: failed to decode from address: %w", err)
+	}
+	return h.postTransaction("/wallet/updateBrokerage", contract)
+}
+
+// CreateAssetIssue issues a token.
+func (h *HTTPClient) CreateAssetIssue(from, name, description, abbr, urlStr string,
+	precision int32, totalSupply, startTime, endTime, freeAssetNetLimit, publicFreeAssetNetLimit int64,
+	trxNum, icoNum, voteScore int32, frozenSupply map[string]string) (*api.TransactionExtention, error) {
+
+	contract, err := newAssetIssueContract(from, name, description, abbr, urlStr, precision, totalSupply,
+		startTime, endTime, freeAssetNetLimit, publicFreeAssetNetLimit, trxNum, icoNum, voteScore, frozenSupply)
+	if err != nil {
+		return nil, err
+	}
+	return h.postTransaction("/wallet/createassetissue", contract)
+}
+
+// GetAssetIssueList queries the list of all issued tokens.
+// If page is -1, returns the full list.
+func (h *HTTPClient) GetAssetIssueList(page int64, limit ...int64) (*api.AssetIssueList, error) {
+	result := new(api.AssetIssueList)
+	if page == -1 {
+		return result, h.query("getassetissuelist", nil, result)
+	}
+
+	useLimit := int64(10)
+	if len(limit) == 1 {
+		useLimit = limit[0]
+	}
+	return result, h.query("getpaginatedassetissuelist", GetPaginatedMessage(page*useLimit, useLimit), result)
+}
+
+// GetPaginatedAssetIssueList queries the list of all issued tokens.
+// If page is -1, returns the full list.
+func (h *HTTPClient) GetPaginatedAssetIssueList(page int64, limit ...int64) (*api.AssetIssueList, error) {
+	return h.GetAssetIssueList(page, limit...)
+}
+
+// GetAssetIssueByAccount queries tokens issued by a given account.
+func (h *HTTPClient) GetAssetIssueByAccount(address string) (*api.AssetIssueList, error) {
+	body, err := addressBody(address)
+	if err != nil {
+		return nil, fmt.Errorf("GetAssetIssueByAccount: %w", err)
+	}
+	result := new(api.AssetIssueList)
+	return result, h.post("/wallet/getassetissuebyaccount", body, result)
+}
+
+// GetAssetIssueByName queries token information by token name.
+func (h *HTTPClient) GetAssetIssueByName(name string) (*core.AssetIssueContract, error) {
+	result := new(core.AssetIssueContract)
+	return result, h.query("getassetissuebyname", GetMessageBytes([]byte(name)), result)
+}
+
+// GetAssetIssueById queries token information by id.
+func (h *HTTPClient) GetAssetIssueById(id string) (*core.AssetIssueContract, error) {
+	result := new(core.AssetIssueContract)
+	// The node reads the id as a plain string rather than hex bytes.
+	return result, h.query("getassetissuebyid", map[string]any{"value": id}, result)
+}
+
+// TransferAsset transfers tokens.
+func (h *HTTPClient) TransferAsset(from, toAddress, assetName string, amount int64) (*api.TransactionExtention, error) {
+	contract := &core.TransferAssetContract{AssetName: []byte(assetName), Amount: amount}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("TransferAsset: failed to decode from address: %w", err)
+	}
+	if contract.ToAddress, err = base58.DecodeCheck(toAddress); err != nil {
+		return nil, fmt.Errorf("TransferAsset: failed to decode to address: %w", err)
+	}
+	return h.postTransaction("/wallet/transferasset", contract)
+}
+
+// ParticipateAssetIssue participates in a token issuance.
+func (h *HTTPClient) ParticipateAssetIssue(from, issuerAddress, tokenID string, amount int64) (*api.TransactionExtention, error) {
+	contract := &core.ParticipateAssetIssueContract{AssetName: []byte(tokenID), Amount: amount}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("ParticipateAssetIssue: failed to decode from address: %w", err)
+	}
+	if contract.ToAddress, err = base58.DecodeCheck(issuerAddress); err != nil {
+		return nil, fmt.Errorf("ParticipateAssetIssue: failed to decode issuer address: %w", err)
+	}
+	return h.postTransaction("/wallet/participateassetissue", contract)
+}
+
+// UpdateAsset updates asset details such as description and limit.
+func (h *HTTPClient) UpdateAsset(from, description, urlStr string, newLimit, newPublicLimit int64) (*api.TransactionExtention, error) {
+	addr, err := base58.DecodeCheck(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+
+	contract := &core.UpdateAssetContract{
+		OwnerAddress:   addr,
+		Description:    []byte(description),
+		Url:            []byte(urlStr),
+		NewLimit:       newLimit,
+		NewPublicLimit: newPublicLimit,
+	}
+	return h.postTransaction("/wallet/updateasset", contract)
+}
+
+// GetNowBlock retrieves the current block information.
+func (h *HTTPClient) GetNowBlock() (*api.BlockExtention, error) {
+	block, err := h.getBlock("getnowblock", nil)
+	if err != nil {
+		return nil, fmt.Errorf("GetNowBlock: %w", err)
+	}
+	return block, nil
+}
+
+// GetBlockByNum retrieves a block by its height.
+func (h *HTTPClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	block, err := h.getBlock("getblockbynum", GetMessageNumber(num))
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByNum: %w", err)
+	}
+	return block, nil
+}
+
+// GetBlockByLimitNext retrieves the blocks with numbers in [start, end).
+func (h *HTTPClient) GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error) {
+	data, err := h.do("/wallet/getblockbylimitnext", map[string]any{"startNum": start, "endNum": end})
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByLimitNext: %w", err)
+	}
+	var list struct {
+		Block []json.RawMessage `json:"block"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("GetBlockByLimitNext: %w", err)
+	}
+	blocks := &api.BlockListExtention{}
+	for _, b := range list.Block {
+		block, err := decodeBlock(b)
+		if err != nil {
+			return nil, fmt.Errorf("GetBlockByLimitNext: %w", err)
+		}
+		blocks.Block = append(blocks.Block, block)
+	}
+	return blocks, nil
+}
+
+// GetBlockByID queries block information by block ID.
+func (h *HTTPClient) GetBlockByID(id string) (*core.Block, error) {
+	body, err := idBody(id)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByID: %w", err)
+	}
+	block := new(core.Block)
+	if err := h.query("getblockbyid", body, block); err != nil {
+		return nil, fmt.Errorf("GetBlockByID: %w", err)
+	}
+	return block, nil
+}
+
+// GetNextMaintenanceTime queries the next maintenance time.
+func (h *HTTPClient) GetNextMaintenanceTime() (*api.NumberMessage, error) {
+	num, err := h.queryNumber("getnextmaintenancetime", nil, "num")
+	if err != nil {
+		return nil, fmt.Errorf("GetNextMaintenanceTime error: %w", err)
+	}
+	return GetMessageNumber(num), nil
+}
+
+// GetChainParameters queries the network parameters set by committee proposals.
+func (h *HTTPClient) GetChainParameters() (*core.ChainParameters, error) {
+	params := new(core.ChainParameters)
+	if err := h.post("/wallet/getchainparameters", nil, params); err != nil {
+		return nil, fmt.Errorf("GetChainParameters error: %w", err)
+	}
+	return params, nil
+}
+
+// GetBlockReference derives the head block reference from the current block,
+// as the Database service has no HTTP endpoint.
+func (h *HTTPClient) GetBlockReference() (*api.BlockReference, error) {
+	block, err := h.GetNowBlock()
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockReference error: %w", err)
+	}
+	return &api.BlockReference{
+		BlockNum:  block.GetBlockHeader().GetRawData().GetNumber(),
+		BlockHash: block.GetBlockid(),
+	}, nil
+}
+
+// GetDynamicProperties derives the last solidified block from the node information.
+func (h *HTTPClient) GetDynamicProperties() (*core.DynamicProperties, error) {
+	info, err := h.GetNodeInfo()
+	if err != nil {
+		return nil, fmt.Errorf("GetDynamicProperties error: %w", err)
+	}
+	return &core.DynamicProperties{LastSolidityBlockNum: parseNodeBlockNum(info.GetSolidityBlock())}, nil
+}
+
+// GetMarketOrderByAccount queries market orders for the given account.
+func (h *HTTPClient) GetMarketOrderByAccount(addr string) (*core.MarketOrderList, error) {
+	b, err := base58.DecodeCheck(addr)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketOrderByAccount: failed to decode address: %w", err)
+	}
+	orderList := new(core.MarketOrderList)
+	if err := h.query("getmarketorderbyaccount", GetMessageBytes(b), orderList); err != nil {
+		return nil, fmt.Errorf("GetMarketOrderByAccount: HTTP error: %w", err)
+	}
+	return orderList, nil
+}
+
+// GetMarketPairList queries the list of all market pairs.
+func (h *HTTPClient) GetMarketPairList() (*core.MarketOrderPairList, error) {
+	result := new(core.MarketOrderPairList)
+	if err := h.query("getmarketpairlist", nil, result); err != nil {
+		return nil, fmt.Errorf("GetMarketPairList: %w", err)
+	}
+	return result, nil
+}
+
+// GetMarketOrderListByPair queries market order list by sell and buy token IDs.
+func (h *HTTPClient) GetMarketOrderListByPair(sellTokenId, buyTokenId string) (*core.MarketOrderList, error) {
+	req := &core.MarketOrderPair{SellTokenId: []byte(sellTokenId), BuyTokenId: []byte(buyTokenId)}
+	orderList := new(core.MarketOrderList)
+	if err := h.query("getmarketorderlistbypair", req, orderList); err != nil {
+		return nil, fmt.Errorf("GetMarketOrderListByPair: %w", err)
+	}
+	return orderList, nil
+}
+
+// GetMarketPriceByPair queries market price information by sell and buy token IDs.
+func (h *HTTPClient) GetMarketPriceByPair(sellTokenId, buyTokenId string) (*core.MarketPriceList, error) {
+	req := &core.MarketOrderPair{SellTokenId: []byte(sellTokenId), BuyTokenId: []byte(buyTokenId)}
+	priceList := new(core.MarketPriceList)
+	if err := h.query("getmarketpricebypair", req, priceList); err != nil {
+		return nil, fmt.Errorf("GetMarketPriceByPair: %w", err)
+	}
+	return priceList, nil
+}
+
+// GetMarketOrderById queries a market order by its ID.
+func (h *HTTPClient) GetMarketOrderById(id string) (*core.MarketOrder, error) {
+	body, err := idBody(id)
+	if err != nil {
+		return nil, fmt.Errorf("GetMarketOrderById: failed to decode id: %w", err)
+	}
+	order := new(core.MarketOrder)
+	if err := h.query("getmarketorderbyid", body, order); err != nil {
+		return nil, fmt.Errorf("GetMarketOrderById: HTTP error: %w", err)
+	}
+	return order, nil
+}
+
+// GetBurnTrx retrieves the total burned TRX.
+func (h *HTTPClient) GetBurnTrx() (*api.NumberMessage, error) {
+	num, err := h.queryNumber("getburntrx", nil, "burnTrxAmount")
+	if err != nil {
+		return nil, fmt.Errorf("GetBurnTrx: %w", err)
+	}
+	return GetMessageNumber(num), nil
+}
+
+// DeployContract deploys a contract and returns the transaction result.
+func (h *HTTPClient) DeployContract(from, contractName string, contractABI *core.SmartContract_ABI, codeStr string, feeLimit, curPercent, oeLimit int64, options ...DeployOption) (*api.TransactionExtention, error) {
+	ct, err := newCreateSmartContract(from, contractName, contractABI, codeStr, curPercent, oeLimit, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// The deploy endpoint takes a flattened contract with the ABI entries as a
+	// Solidity JSON string.
+	abiJSON, err := abi.MarshalContractABI(contractABI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ABI: %w", err)
+	}
+	body := map[string]any{
+		"owner_address":                 fmt.Sprintf("%x", ct.OwnerAddress),
+		"name":                          contractName,
+		"abi":                           string(abiJSON),
+		"bytecode":                      fmt.Sprintf("%x", ct.NewContract.Bytecode),
+		"consume_user_resource_percent": curPercent,
+		"origin_energy_limit":           oeLimit,
+		"call_value":                    ct.NewContract.CallValue,
+	}
+	if ct.TokenId != 0 {
+		body["token_id"] = ct.TokenId
+		body["call_token_value"] = ct.CallTokenValue
+	}
+	if feeLimit > 0 {
+		body["fee_limit"] = feeLimit
+	}
+
+	tx, err := h.postTransaction("/wallet/deploycontract", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deploy contract: %w", err)
+	}
+	return tx, nil
+}
+
+// TriggerContract executes a contract function.
+func (h *HTTPClient) TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error) {
+	ct, err := newTriggerSmartContract(from, contractAddress, method, jsonString, tAmount, tTokenID, tTokenAmount)
+	if err != nil {
+		return nil, err
+	}
+	return h.triggerContract(ct, feeLimit)
+}
+
+// TriggerMethod executes a contract method by name with Go arguments, encoded
+// with the contract ABI, which is fetched once and cached.
+func (h *HTTPClient) TriggerMethod(from, contractAddress string, feeLimit, callValue int64, method string, args ...any) (*api.TransactionExtention, error) {
+	ct, err := newTriggerMethodContract(h.contractABIs(), from, contractAddress, callValue, method, args)
+	if err != nil {
+		return nil, err
+	}
+	return h.triggerContract(ct, feeLimit)
+}
+
+// TriggerContractData executes a contract with call data encoded by the caller.
+func (h *HTTPClient) TriggerContractData(from, contractAddress string, data []byte, feeLimit, callValue int64) (*api.TransactionExtention, error) {
+	ct, err := newTriggerDataContract(from, contractAddress, data, callValue)
+	if err != nil {
+		return nil, err
+	}
+	return h.triggerContract(ct, feeLimit)
+}
+
+// TriggerConstantContract calls a contract without creating a transaction on chain,
+// returning the result in ConstantResult.
+func (h *HTTPClient) TriggerConstantContract(from, contractAddress string, data []byte) (*api.TransactionExtention, error) {
+	ct, err := newTriggerDataContract(from, contractAddress, data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(api.TransactionExtention)
+	if err := h.post("/wallet/triggerconstantcontract", ct, tx); err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+	if tx.GetResult().GetCode() > 0 {
+		return nil, fmt.Errorf("contract call failed: %s", string(tx.Result.Message))
+	}
+	return tx, nil
+}
+
+// contractABIs returns the ABI cache of the client.
+func (h *HTTPClient) contractABIs() *ABICache {
+	h.abisOnce.Do(func() { h.abis = NewABICache(h) })
+	return h.abis
+}
+
+// triggerContract sends a smart contract execution transaction.
+func (h *HTTPClient) triggerContract(ct *core.TriggerSmartContract, feeLimit int64) (*api.TransactionExtention, error) {
+	body, err := toNodeJSON(ct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode contract: %w", err)
+	}
+	if feeLimit > 0 {
+		body["fee_limit"] = feeLimit
+	}
+
+	tx, err := h.postTransaction("/wallet/triggersmartcontract", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trigger contract: %w", err)
+	}
+	return tx, nil
+}
+
+// GetContract retrieves a deployed contract, including its bytecode and ABI.
+func (h *HTTPClient) GetContract(contractAddress string) (*core.SmartContract, error) {
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	sm := new(core.SmartContract)
+	if err := h.post("/wallet/getcontract", GetMessageBytes(contractDesc), sm); err != nil {
+		return nil, fmt.Errorf("failed to retrieve contract: %w", err)
+	}
+	return sm, nil
+}
+
+// GetContractABI retrieves the ABI of a deployed contract.
+func (h *HTTPClient) GetContractABI(contractAddress string) (*core.SmartContract_ABI, error) {
+	sm, err := h.GetContract(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if sm.Abi == nil {
+		return nil, fmt.Errorf("contract ABI not found")
+	}
+	return sm.Abi, nil
+}
+
+// GetContractInfo retrieves a deployed contract with its runtime code and energy state.
+func (h *HTTPClient) GetContractInfo(contractAddress string) (*core.SmartContractDataWrapper, error) {
+	contractDesc, err := base58.DecodeCheck(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+
+	info := new(core.SmartContractDataWrapper)
+	if err := h.post("/wallet/getcontractinfo", GetMessageBytes(contractDesc), info); err != nil {
+		return nil, fmt.Errorf("failed to retrieve contract info: %w", err)
+	}
+	return info, nil
+}
+
+// UpdateSetting updates the percentage of energy paid by the callers of a contract.
+func (h *HTTPClient) UpdateSetting(from, contractAddress string, consumeUserResourcePercent int64) (*api.TransactionExtention, error) {
+	ct, err := newUpdateSettingContract(from, contractAddress, consumeUserResourcePercent)
+	if err != nil {
+		return nil, err
+	}
+	return h.postTransaction("/wallet/updatesetting", ct)
+}
+
+// UpdateEnergyLimit updates the maximum energy the contract owner provides per call.
+func (h *HTTPClient) UpdateEnergyLimit(from, contractAddress string, originEnergyLimit int64) (*api.TransactionExtention, error) {
+	ct, err := newUpdateEnergyLimitContract(from, contractAddress, originEnergyLimit)
+	if err != nil {
+		return nil, err
+	}
+	return h.postTransaction("/wallet/updateenergylimit", ct)
+}
+
+// ClearContractABI removes the ABI of a contract. The cached ABI of the contract
+// is dropped once the transaction is confirmed; signing does not change its ID.
+func (h *HTTPClient) ClearContractABI(from, contractAddress string) (*api.TransactionExtention, error) {
+	ct, err := newClearABIContract(from, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := h.postTransaction("/wallet/clearabi", ct)
+	if err != nil {
+		return nil, err
+	}
+	h.contractABIs().ExpectClear(contractAddress, fmt.Sprintf("%x", tx.GetTxid()), time.UnixMilli(tx.GetTransaction().GetRawData().GetExpiration()))
+	return tx, nil
+}
+
+// GetSpendingKey retrieves a spending key.
+func (h *HTTPClient) GetSpendingKey() (*api.BytesMessage, error) {
+	result := new(api.BytesMessage)
+	return result, h.post("/wallet/getspendingkey", nil, result)
+}
+
+// GetExpandedSpendingKey expands a hex-encoded spending key into ask, nsk and ovk.
+func (h *HTTPClient) GetExpandedSpendingKey(key string) (*api.ExpandedSpendingKeyMessage, error) {
+	sk, err := shieldedKeyBytes("spending key", key)
+	if err != nil {
+		return nil, err
+	}
+	result := new(api.ExpandedSpendingKeyMessage)
+	return result, h.post("/wallet/getexpandedspendingkey", &api.BytesMessage{Value: sk}, result)
+}
+
+// GetAkFromAsk retrieves `Ak` from a hex-encoded `Ask`.
+func (h *HTTPClient) GetAkFromAsk(ask string) (*api.BytesMessage, error) {
+	askBytes, err := shieldedKeyBytes("ask", ask)
+	if err != nil {
+		return nil, err
+	}
+	result := new(api.BytesMessage)
+	return result, h.post("/wallet/getakfromask", &api.BytesMessage{Value: askBytes}, result)
+}
+
+// GetNkFromNsk retrieves `Nk` from a hex-encoded `Nsk`.
+func (h *HTTPClient) GetNkFromNsk(nsk string) (*api.BytesMessage, error) {
+	nskBytes, err := shieldedKeyBytes("nsk", nsk)
+	if err != nil {
+		return nil, err
+	}
+	result := new(api.BytesMessage)
+	return result, h.post("/wallet/getnkfromnsk", &api.BytesMessage{Value: nskBytes}, result)
+}
+
+// GetIncomingViewingKey retrieves the incoming viewing key of hex-encoded `Ak` and `Nk`.
+func (h *HTTPClient) GetIncomingViewingKey(ak, nk string) (*api.IncomingViewingKeyMessage, error) {
+	vk, err := newViewingKeyMessage(ak, nk)
+	if err != nil {
+		return nil, err
+	}
+	result := new(api.IncomingViewingKeyMessage)
+	return result, h.post("/wallet/getincomingviewingkey", vk, result)
+}
+
+// GetDiversifier retrieves a diversifier message.
+func (h *HTTPClient) GetDiversifier() (*api.DiversifierMessage, error) {
+	result := new(api.DiversifierMessage)
+	return result, h.post("/wallet/getdiversifier", nil, result)
+}
+
+// GetRcm retrieves a random commitment.
+func (h *HTTPClient) GetRcm() (*api.BytesMessage, error) {
+	result := new(api.BytesMessage)
+	return result, h.post("/wallet/getrcm", nil, result)
+}
+
+// GetZenPaymentAddress retrieves the payment address of a hex-encoded incoming viewing key and diversifier.
+func (h *HTTPClient) GetZenPaymentAddress(ivk, d string) (*api.PaymentAddressMessage, error) {
+	msg, err := newIvkDiversifierMessage(ivk, d)
+	if err != nil {
+		return nil, err
+	}
+	// The node takes the incoming viewing key and diversifier as flat hex fields.
+	body := map[string]any{
+		"ivk": fmt.Sprintf("%x", msg.Ivk.Ivk),
+		"d":   fmt.Sprintf("%x", msg.D.D),
+	}
+	result := new(api.PaymentAddressMessage)
+	return result, h.post("/wallet/getzenpaymentaddress", body, result)
+}
+
+// CreateShieldedTransaction builds a shielded TRX transfer. Transfers from a
+// transparent address must still be signed by its owner.
+func (h *HTTPClient) CreateShieldedTransaction(params *api.PrivateParameters) (*api.TransactionExtention, error) {
+	return h.postTransaction("/wallet/createshieldedtransaction", params)
+}
+
+// GetMerkleTreeVoucherInfo retrieves the Merkle vouchers and paths of shielded notes, needed to spend them.
+func (h *HTTPClient) GetMerkleTreeVoucherInfo(points *core.OutputPointInfo) (*core.IncrementalMerkleVoucherInfo, error) {
+	result := new(core.IncrementalMerkleVoucherInfo)
+	return result, h.post("/wallet/getmerkletreevoucherinfo", points, result)
+}
+
+// ScanNoteByIvk scans a block range for the shielded TRX notes received with an incoming viewing key.
+func (h *HTTPClient) ScanNoteByIvk(params *api.IvkDecryptParameters) (*api.DecryptNotes, error) {
+	result := new(api.DecryptNotes)
+	return result, h.post("/wallet/scannotebyivk", params, result)
+}
+
+// ScanNoteByOvk scans a block range for the shielded TRX notes sent with an outgoing viewing key.
+func (h *HTTPClient) ScanNoteByOvk(params *api.OvkDecryptParameters) (*api.DecryptNotes, error) {
+	result := new(api.DecryptNotes)
+	return result, h.post("/wallet/scannotebyovk", params, result)
+}
+
+// IsSpend checks whether the nullifier of a shielded TRX note has been revealed, i.e. the note is spent.
+func (h *HTTPClient) IsSpend(params *api.NoteParameters) (*api.SpendResult, error) {
+	result := new(api.SpendResult)
+	return result, h.post("/wallet/isspend", params, result)
+}
+
+// GetNewShieldedAddress generates a new shielded address.
+func (h *HTTPClient) GetNewShieldedAddress() (*api.ShieldedAddressInfo, error) {
+	result := new(api.ShieldedAddressInfo)
+	return result, h.post("/wallet/getnewshieldedaddress", nil, result)
+}
+
+// CreateShieldedContractParameters builds the parameters of a shielded TRC-20 mint, transfer or burn,
+// signing the spends with the spend authorizing key.
+func (h *HTTPClient) CreateShieldedContractParameters(params *api.PrivateShieldedTRC20Parameters) (*api.ShieldedTRC20Parameters, error) {
+	result := new(api.ShieldedTRC20Parameters)
+	return result, h.post("/wallet/createshieldedcontractparameters", params, result)
+}
+
+// CreateShieldedContractParametersWithoutAsk builds the parameters of a shielded TRC-20 transaction
+// whose spends are signed separately.
+func (h *HTTPClient) CreateShieldedContractParametersWithoutAsk(params *api.PrivateShieldedTRC20ParametersWithoutAsk) (*api.ShieldedTRC20Parameters, error) {
+	result := new(api.ShieldedTRC20Parameters)
+	return result, h.post("/wallet/createshieldedcontractparameterswithoutask", params, result)
+}
+
+// ScanShieldedTRC20NotesByIvk scans a block range for the shielded TRC-20 notes received with an incoming viewing key.
+func (h *HTTPClient) ScanShieldedTRC20NotesByIvk(params *api.IvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error) {
+	result := new(api.DecryptNotesTRC20)
+	return result, h.post("/wallet/scanshieldedtrc20notesbyivk", params, result)
+}
+
+// ScanShieldedTRC20NotesByOvk scans a block range for the shielded TRC-20 notes sent with an outgoing viewing key.
+func (h *HTTPClient) ScanShieldedTRC20NotesByOvk(params *api.OvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error) {
+	result := new(api.DecryptNotesTRC20)
+	return result, h.post("/wallet/scanshieldedtrc20notesbyovk", params, result)
+}
+
+// IsShieldedTRC20ContractNoteSpent checks whether a shielded TRC-20 note has been spent.
+func (h *HTTPClient) IsShieldedTRC20ContractNoteSpent(params *api.NfTRC20Parameters) (*api.NullifierResult, error) {
+	result := new(api.NullifierResult)
+	return result, h.post("/wallet/isshieldedtrc20contractnotespent", params, result)
+}
+
+// GetTriggerInputForShieldedTRC20Contract encodes the contract call input of shielded TRC-20
+// parameters built without the spend authorizing key, once the spends are signed.
+func (h *HTTPClient) GetTriggerInputForShieldedTRC20Contract(params *api.ShieldedTRC20TriggerContractParameters) (*api.BytesMessage, error) {
+	result := new(api.BytesMessage)
+	return result, h.post("/wallet/gettriggerinputforshieldedtrc20contract", params, result)
+}
+
+// ListNodes queries the list of nodes connected to the API.
+func (h *HTTPClient) ListNodes() (*api.NodeList, error) {
+	nodeList := new(api.NodeList)
+	if err := h.post("/wallet/listnodes", nil, nodeList); err != nil {
+		return nil, fmt.Errorf("ListNodes error: %w", err)
+	}
+	return nodeList, nil
+}
+
+// GetPendingSize queries the size of the pending transaction pool.
+func (h *HTTPClient) GetPendingSize() (*api.NumberMessage, error) {
+	num, err := h.queryNumber("getpendingsize", nil, "pendingSize")
+	if err != nil {
+		return nil, fmt.Errorf("GetPendingSize: %w", err)
+	}
+	return GetMessageNumber(num), nil
+}
+
+// GetBandwidthPrices retrieves the current bandwidth prices.
+func (h *HTTPClient) GetBandwidthPrices() (*api.PricesResponseMessage, error) {
+	result := new(api.PricesResponseMessage)
+	if err := h.query("getbandwidthprices", nil, result); err != nil {
+		return nil, fmt.Errorf("GetBandwidthPrices: %w", err)
+	}
+	return result, nil
+}
+
+// GetEnergyPrices retrieves the current energy prices.
+func (h *HTTPClient) GetEnergyPrices() (*api.PricesResponseMessage, error) {
+	result := new(api.PricesResponseMessage)
+	if err := h.query("getenergyprices", nil, result); err != nil {
+		return nil, fmt.Errorf("GetEnergyPrices: %w", err)
+	}
+	return result, nil
+}
+
+// GetMemoFee retrieves the memo fee.
+func (h *HTTPClient) GetMemoFee() (*api.PricesResponseMessage, error) {
+	result := new(api.PricesResponseMessage)
+	if err := h.post("/wallet/getmemofee", nil, result); err != nil {
+		return nil, fmt.Errorf("GetMemoFee: %w", err)
+	}
+	return result, nil
+}
+
+// GetNodeInfo retrieves information about the connected node, its peers and machine.
+func (h *HTTPClient) GetNodeInfo() (*core.NodeInfo, error) {
+	result := new(core.NodeInfo)
+	if err := h.post("/wallet/getnodeinfo", nil, result); err != nil {
+		return nil, fmt.Errorf("GetNodeInfo: %w", err)
+	}
+	return result, nil
+}
+
+// GetStatsInfo retrieves the node metrics. The node must run with metrics enabled.
+func (h *HTTPClient) GetStatsInfo() (*core.MetricsInfo, error) {
+	result := new(core.MetricsInfo)
+	if err := h.post("/monitor/getstatsinfo", nil, result); err != nil {
+		return nil, fmt.Errorf("GetStatsInfo: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHTTPClient(t *testing.T) {
+	owner, to := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL", "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9"
+	ownerBytes, _ := base58.DecodeCheck(owner)
+	toBytes, _ := base58.DecodeCheck(to)
+
+	var broadcast []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("TRON-PRO-API-KEY"))
+		var req map[string]any
+		body, _ := io.ReadAll(r.Body)
+		require.Nil(t, json.Unmarshal(body, &req))
+
+		switch r.URL.Path {
+		case "/walletsolidity/getaccount":
+			assert.Equal(t, hex.EncodeToString(ownerBytes), req["address"])
+			_, _ = io.WriteString(w, `{"address":"`+hex.EncodeToString(ownerBytes)+`","balance":1500000000000,"account_name":"6d61696e"}`)
+		case "/wallet/createtransaction":
+			assert.Equal(t, hex.EncodeToString(toBytes), req["to_address"])
+			assert.Equal(t, float64(1000), req["amount"])
+			_, _ = io.WriteString(w, `{"visible":false,"txID":"00","raw_data":{"contract":[{"parameter":{"value":{"amount":1000,`+
+				`"owner_address":"`+hex.EncodeToString(ownerBytes)+`","to_address":"`+hex.EncodeToString(toBytes)+`"},`+
+				`"type_url":"type.googleapis.com/protocol.TransferContract"},"type":"TransferContract"}],`+
+				`"ref_block_bytes":"a1b2","ref_block_hash":"0102030405060708","expiration":1700000060000,"timestamp":1700000000000}}`)
+		case "/wallet/broadcasthex":
+			broadcast, _ = hex.DecodeString(req["transaction"].(string))
+			_, _ = io.WriteString(w, `{"result":false,"code":"SIGERROR","message":"`+hex.EncodeToString([]byte("bad sig"))+`"}`)
+		case "/wallet/getpendingsize":
+			_, _ = io.WriteString(w, `{"pendingSize":12}`)
+		case "/wallet/getchainparameters":
+			_, _ = io.WriteString(w, `{"chainParameter":[{"key":"getMaintenanceTimeInterval","value":21600000},{"key":"getAllowTvmCompatibleEvm"}]}`)
+		case "/wallet/getblockbylimitnext":
+			assert.Equal(t, float64(5), req["startNum"])
+			assert.Equal(t, float64(7), req["endNum"])
+			_, _ = io.WriteString(w, `{"block":[`+
+				`{"blockID":"0000000000000005aa","block_header":{"raw_data":{"number":5,"timestamp":1700000001000}}},`+
+				`{"blockID":"0000000000000006bb","block_header":{"raw_data":{"number":6,"timestamp":1700000004000}}}]}`)
+		case "/walletsolidity/gettransactioninfobyid":
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL, WithAPIKey("secret"), WithSolidity())
+	require.Nil(t, client.Start())
+	defer client.Stop()
+
+	acc, err := client.GetAccount(owner)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1500000000000), acc.Balance)
+	assert.Equal(t, "main", string(acc.AccountName))
+
+	tx, err := client.CreateTransaction(owner, to, 1000)
+	require.Nil(t, err)
+	raw := tx.Transaction.RawData
+	assert.Equal(t, []byte{0xa1, 0xb2}, raw.RefBlockBytes)
+	assert.Equal(t, int64(1700000060000), raw.Expiration)
+	transfer := new(core.TransferContract)
+	require.Nil(t, raw.Contract[0].Parameter.UnmarshalTo(transfer))
+	assert.Equal(t, toBytes, transfer.ToAddress)
+	txid, err := transactionID(raw)
+	require.Nil(t, err)
+	assert.Equal(t, txid, tx.Txid)
+
+	result, err := client.BroadcastTransaction(tx.Transaction)
+	require.NotNil(t, err)
+	assert.Equal(t, api.Return_SIGERROR, result.Code)
+	assert.Equal(t, "bad sig", string(result.Message))
+	assert.True(t, proto.Equal(tx.Transaction, func() *core.Transaction {
+		sent := new(core.Transaction)
+		require.Nil(t, proto.Unmarshal(broadcast, sent))
+		return sent
+	}()))
+
+	pending, err := client.GetPendingSize()
+	require.Nil(t, err)
+	assert.Equal(t, int64(12), pending.Num)
+
+	params, err := client.GetChainParameters()
+	require.Nil(t, err)
+	require.Len(t, params.ChainParameter, 2)
+	assert.Equal(t, "getMaintenanceTimeInterval", params.ChainParameter[0].Key)
+	assert.Equal(t, int64(21600000), params.ChainParameter[0].Value)
+
+	blocks, err := client.GetBlockByLimitNext(5, 7)
+	require.Nil(t, err)
+	require.Len(t, blocks.Block, 2)
+	assert.Equal(t, int64(6), blocks.Block[1].BlockHeader.RawData.Number)
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 6, 0xbb}, blocks.Block[1].Blockid)
+
+	_, err = client.GetTransactionInfoByID("0a0b")
+	assert.ErrorIs(t, err, ErrTransactionInfoNotFound)
+
+	_, err = client.GetTransactionsFromThis(owner, 0, 10)
+	assert.ErrorIs(t, err, ErrExtensionUnavailable)
+}
+
+func TestHTTPClientDeployContractABI(t *testing.T) {
+	owner := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL"
+	var sent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		body, _ := io.ReadAll(r.Body)
+		require.Nil(t, json.Unmarshal(body, &req))
+		require.Equal(t, "/wallet/deploycontract", r.URL.Path)
+		sent = req["abi"].(string)
+		_, _ = io.WriteString(w, `{"txID":"00","raw_data":{"contract":[],"expiration":1700000060000,"timestamp":1700000000000}}`)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(server.URL)
+	require.Nil(t, client.Start())
+	defer client.Stop()
+
+	contractABI := &core.SmartContract_ABI{Entrys: []*core.SmartContract_ABI_Entry{
+		{
+			Type:            core.SmartContract_ABI_Entry_Constructor,
+			Inputs:          []*core.SmartContract_ABI_Entry_Param{{Name: "supply", Type: "uint256"}},
+			StateMutability: core.SmartContract_ABI_Entry_Nonpayable,
+		},
+		{
+			Type:            core.SmartContract_ABI_Entry_Function,
+			Name:            "transfer",
+			Inputs:          []*core.SmartContract_ABI_Entry_Param{{Name: "to", Type: "address"}, {Name: "id", Type: "trcToken"}},
+			Outputs:         []*core.SmartContract_ABI_Entry_Param{{Type: "bool"}},
+			Payable:         true,
+			StateMutability: core.SmartContract_ABI_Entry_Payable,
+		},
+		{
+			Type:   core.SmartContract_ABI_Entry_Event,
+			Name:   "Deposit",
+			Inputs: []*core.SmartContract_ABI_Entry_Param{{Name: "from", Type: "address", Indexed: true}, {Name: "amount", Type: "uint256"}},
+		},
+	}}
+	_, err := client.DeployContract(owner, "Token", contractABI, "6080", 1000, 100, 1)
+	require.Nil(t, err)
+	assert.Equal(t, `[`+
+		`{"type":"constructor","inputs":[{"name":"supply","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"},`+
+		`{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"id","type":"trcToken"}],`+
+		`"outputs":[{"name":"","type":"bool"}],"stateMutability":"payable","payable":true},`+
+		`{"type":"event","name":"Deposit","inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256"}],"outputs":[]}]`, sent)
+}
@@ -0,0 +1,259 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// The java-tron HTTP API uses a JSON dialect close to protojson, except that
+// bytes are hex encoded, 64-bit integers are plain numbers and google.protobuf.Any
+// values are written as {"type_url": ..., "value": {...}}. The functions below
+// translate between the two so that the generated messages can be reused.
+
+// toNodeJSON encodes a message in the java-tron JSON format.
+func toNodeJSON(m proto.Message) (map[string]any, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := convertToNode(m.ProtoReflect().Descriptor(), obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// fromNodeJSON decodes a java-tron JSON document into a message, ignoring unknown fields.
+func fromNodeJSON(data []byte, m proto.Message) error {
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return err
+	}
+	if err := convertFromNode(m.ProtoReflect().Descriptor(), obj); err != nil {
+		return err
+	}
+	converted, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(converted, m)
+}
+
+// decodeJSONObject decodes a JSON object, keeping numbers exact.
+func decodeJSONObject(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	if obj == nil {
+		obj = make(map[string]any)
+	}
+	return obj, nil
+}
+
+// convertToNode rewrites a protojson object in place into the java-tron format.
+func convertToNode(desc protoreflect.MessageDescriptor, obj map[string]any) error {
+	for key, val := range obj {
+		fd := desc.Fields().ByName(protoreflect.Name(key))
+		if fd == nil {
+			continue
+		}
+		converted, err := convertFieldValue(fd, val, convertToNodeScalar, convertToNode)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+		obj[key] = converted
+	}
+	return nil
+}
+
+// convertFromNode rewrites a java-tron object in place into the protojson format.
+func convertFromNode(desc protoreflect.MessageDescriptor, obj map[string]any) error {
+	if desc.FullName() == "google.protobuf.Any" {
+		return convertAnyFromNode(obj)
+	}
+	if desc.FullName() == "protocol.Transaction" {
+		if err := convertRawDataHex(obj); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range obj {
+		fd := desc.Fields().ByName(protoreflect.Name(key))
+		if fd == nil {
+			fd = desc.Fields().ByJSONName(key)
+		}
+		if fd == nil {
+			continue
+		}
+		if fd.Name() == "raw_data" && desc.FullName() == "protocol.Transaction" {
+			if _, ok := obj["raw_data_hex"]; ok {
+				continue // already in protojson form
+			}
+		}
+		converted, err := convertFieldValue(fd, val, convertFromNodeScalar, convertFromNode)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", key, err)
+		}
+		obj[key] = converted
+	}
+	return nil
+}
+
+// convertFieldValue applies the scalar or message conversion to a field value, list or map.
+func convertFieldValue(fd protoreflect.FieldDescriptor, val any,
+	scalar func(protoreflect.FieldDescriptor, any) (any, error),
+	message func(protoreflect.MessageDescriptor, map[string]any) error) (any, error) {
+
+	convertOne := func(fd protoreflect.FieldDescriptor, v any) (any, error) {
+		if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return v, nil
+			}
+			return obj, message(fd.Message(), obj)
+		}
+		return scalar(fd, v)
+	}
+
+	switch {
+	case fd.IsList():
+		list, ok := val.([]any)
+		if !ok {
+			return val, nil
+		}
+		for i := range list {
+			v, err := convertOne(fd, list[i])
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	case fd.IsMap():
+		m, ok := val.(map[string]any)
+		if !ok {
+			return val, nil
+		}
+		for k := range m {
+			v, err := convertOne(fd.MapValue(), m[k])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	default:
+		return convertOne(fd, val)
+	}
+}
+
+// convertToNodeScalar converts base64 bytes to hex and 64-bit integer strings to numbers.
+func convertToNodeScalar(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, nil
+	}
+	switch fd.Kind() {
+	case protoreflect.BytesKind:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return hex.EncodeToString(b), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return json.Number(s), nil
+	}
+	return v, nil
+}
+
+// convertFromNodeScalar converts hex bytes to base64. Values that are not hex,
+// such as plain text names, are encoded as they are.
+func convertFromNodeScalar(fd protoreflect.FieldDescriptor, v any) (any, error) {
+	s, ok := v.(string)
+	if !ok || fd.Kind() != protoreflect.BytesKind {
+		return v, nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		b = []byte(s)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// convertAnyFromNode converts {"type_url": ..., "value": {...}} into the protojson Any form.
+func convertAnyFromNode(obj map[string]any) error {
+	typeURL, _ := obj["type_url"].(string)
+	value, _ := obj["value"].(map[string]any)
+	if typeURL == "" {
+		return nil
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL)
+	if err != nil {
+		return fmt.Errorf("unknown parameter type %s: %w", typeURL, err)
+	}
+	if value == nil {
+		value = make(map[string]any)
+	}
+	if err := convertFromNode(mt.Descriptor(), value); err != nil {
+		return err
+	}
+
+	delete(obj, "type_url")
+	delete(obj, "value")
+	for k, v := range value {
+		obj[k] = v
+	}
+	obj["@type"] = typeURL
+	return nil
+}
+
+// convertRawDataHex replaces the raw_data of a transaction with the exact data
+// from raw_data_hex when the node provides it, so that the transaction ID is preserved.
+func convertRawDataHex(obj map[string]any) error {
+	rawHex, ok := obj["raw_data_hex"].(string)
+	if !ok {
+		return nil
+	}
+	rawBytes, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return fmt.Errorf("invalid raw_data_hex: %w", err)
+	}
+	raw := new(core.TransactionRaw)
+	if err := proto.Unmarshal(rawBytes, raw); err != nil {
+		return fmt.Errorf("invalid raw_data_hex: %w", err)
+	}
+	data, err := protojson.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	rawObj, err := decodeJSONObject(data)
+	if err != nil {
+		return err
+	}
+	obj["raw_data"] = rawObj
+	return nil
+}
@@ -0,0 +1,102 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// dynamicEnergyFactorDecimal is the precision of the energy factor of a contract state.
+const dynamicEnergyFactorDecimal = 10_000
+
+// ContractReport summarizes the ownership, energy sharing settings and energy state of a contract.
+type ContractReport struct {
+	Address string
+	Name    string
+	Owner   string // base58 origin address, the only account allowed to change the settings
+	Version int32
+	// ConsumeUserResourcePercent is the share of energy, in percent, paid by callers;
+	// the owner pays the rest up to OriginEnergyLimit per call.
+	ConsumeUserResourcePercent int64
+	OriginEnergyLimit          int64
+	// CodeHash is the hex Keccak-256 hash of the runtime code.
+	CodeHash        string
+	RuntimeCodeSize int
+	ABIEntries      int
+	// EnergyUsage is the energy used by the contract in the current cycle, and
+	// EnergyFactor the dynamic energy penalty, in units of 1/10000.
+	EnergyUsage  int64
+	EnergyFactor int64
+	UpdateCycle  int64
+}
+
+// OwnerEnergyPercent returns the share of energy, in percent, paid by the owner.
+func (r *ContractReport) OwnerEnergyPercent() int64 {
+	return 100 - r.ConsumeUserResourcePercent
+}
+
+// EnergyMultiplier returns the factor applied to the energy cost of calls, 1 without penalty.
+func (r *ContractReport) EnergyMultiplier() float64 {
+	return 1 + float64(r.EnergyFactor)/dynamicEnergyFactorDecimal
+}
+
+// String formats the report, one property per line.
+func (r *ContractReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Contract:          %s\n", r.Address)
+	fmt.Fprintf(&b, "Name:              %s\n", r.Name)
+	fmt.Fprintf(&b, "Owner:             %s\n", r.Owner)
+	fmt.Fprintf(&b, "Caller energy:     %d%%\n", r.ConsumeUserResourcePercent)
+	fmt.Fprintf(&b, "Origin energy max: %d\n", r.OriginEnergyLimit)
+	fmt.Fprintf(&b, "Code hash:         %s\n", r.CodeHash)
+	fmt.Fprintf(&b, "Runtime code:      %d bytes\n", r.RuntimeCodeSize)
+	fmt.Fprintf(&b, "ABI entries:       %d\n", r.ABIEntries)
+	fmt.Fprintf(&b, "Energy usage:      %d\n", r.EnergyUsage)
+	fmt.Fprintf(&b, "Energy factor:     %d (x%.4f)\n", r.EnergyFactor, r.EnergyMultiplier())
+	fmt.Fprintf(&b, "Update cycle:      %d\n", r.UpdateCycle)
+	return b.String()
+}
+
+// InspectContract reports the settings and energy state of a contract from GetContractInfo.
+func InspectContract(client TronClient, contractAddress string) (*ContractReport, error) {
+	info, err := client.GetContractInfo(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("InspectContract: %w", err)
+	}
+	sc := info.GetSmartContract()
+	if sc == nil {
+		return nil, fmt.Errorf("InspectContract: contract %s not found", contractAddress)
+	}
+
+	report := &ContractReport{
+		Address:                    contractAddress,
+		Name:                       sc.GetName(),
+		Owner:                      encodeAddress(sc.GetOriginAddress()),
+		Version:                    sc.GetVersion(),
+		ConsumeUserResourcePercent: sc.GetConsumeUserResourcePercent(),
+		OriginEnergyLimit:          sc.GetOriginEnergyLimit(),
+		CodeHash:                   hex.EncodeToString(sc.GetCodeHash()),
+		RuntimeCodeSize:            len(info.GetRuntimecode()),
+		ABIEntries:                 len(sc.GetAbi().GetEntrys()),
+		EnergyUsage:                info.GetContractState().GetEnergyUsage(),
+		EnergyFactor:               info.GetContractState().GetEnergyFactor(),
+		UpdateCycle:                info.GetContractState().GetUpdateCycle(),
+	}
+	// Contracts deployed before code hashes were stored have none; hash the runtime code instead.
+	if report.CodeHash == "" && len(info.GetRuntimecode()) > 0 {
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write(info.GetRuntimecode())
+		report.CodeHash = hex.EncodeToString(hasher.Sum(nil))
+	}
+	return report, nil
+}
@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInspectClient struct {
+	TronClient
+	info *core.SmartContractDataWrapper
+}
+
+func (f *fakeInspectClient) GetContractInfo(string) (*core.SmartContractDataWrapper, error) {
+	return f.info, nil
+}
+
+func TestInspectContract(t *testing.T) {
+	owner := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL"
+	ownerBytes, _ := base58.DecodeCheck(owner)
+	runtime := []byte{0x60, 0x80, 0x60, 0x40}
+
+	client := &fakeInspectClient{info: &core.SmartContractDataWrapper{
+		SmartContract: &core.SmartContract{
+			OriginAddress:              ownerBytes,
+			Name:                       "Token",
+			ConsumeUserResourcePercent: 30,
+			OriginEnergyLimit:          10_000_000,
+			Abi:                        trc20TransferABI,
+		},
+		Runtimecode:   runtime,
+		ContractState: &core.ContractState{EnergyUsage: 1_234, EnergyFactor: 3_400, UpdateCycle: 7},
+	}}
+
+	report, err := InspectContract(client, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	require.Nil(t, err)
+	assert.Equal(t, owner, report.Owner)
+	assert.Equal(t, int64(70), report.OwnerEnergyPercent())
+	assert.Equal(t, 1, report.ABIEntries)
+	assert.Equal(t, crypto.Keccak256Hash(runtime).Hex()[2:], report.CodeHash)
+	assert.InDelta(t, 1.34, report.EnergyMultiplier(), 1e-9)
+	assert.Contains(t, report.String(), "Caller energy:     30%")
+
+	_, err = newUpdateSettingContract(owner, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", 101)
+	assert.NotNil(t, err)
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/dszi/go-tron/pb/core"
 )
 
-// TronClient provides an interface for interacting with the TRON blockchain via gRPC.
+// TronClient provides an interface for interacting with the TRON blockchain via gRPC or HTTP.
 // It includes methods for managing accounts, transactions, assets, resources, smart contracts, and network state.
 //
 // This interface abstracts the core functionality needed to interact with the TRON network.
@@ -36,12 +36,15 @@ type TronClient interface {
 	GetTransactionFromPending(id string) (*core.Transaction, error)
 	GetTransactionListFromPending() (*api.TransactionIdList, error)
 	TotalTransaction() (*api.NumberMessage, error)
+	GetTransactionsFromThis(addr string, offset, limit int64) (*api.TransactionListExtention, error)
+	GetTransactionsToThis(addr string, offset, limit int64) (*api.TransactionListExtention, error)
 
 	// Resource Management
 	FreezeBalance(from, delegateTo string, resource core.ResourceCode, frozenBalance int64) (*api.TransactionExtention, error)
 	UnfreezeBalance(from, delegateTo string, resource core.ResourceCode) (*api.TransactionExtention, error)
 	WithdrawBalance(from string) (*api.TransactionExtention, error)
 	UnfreezeAsset(from string) (*api.TransactionExtention, error)
+	FreezeBalanceV2(from string, resource core.ResourceCode, frozenBalance int64) (*api.TransactionExtention, error)
 	UnfreezeBalanceV2(from string, resource core.ResourceCode, unfreezeBalance int64) (*api.TransactionExtention, error)
 	WithdrawExpireUnfreeze(from string, timestamp int64) (*api.TransactionExtention, error)
 	DelegateResource(from, to string, resource core.ResourceCode, delegateBalance int64, lock bool, lockPeriod int64) (*api.TransactionExtention, error)
@@ -70,8 +73,12 @@ type TronClient interface {
 	// Block Management
 	GetNowBlock() (*api.BlockExtention, error)
 	GetBlockByNum(num int64) (*api.BlockExtention, error)
+	GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error)
 	GetBlockByID(id string) (*core.Block, error)
 	GetNextMaintenanceTime() (*api.NumberMessage, error)
+	GetBlockReference() (*api.BlockReference, error)
+	GetDynamicProperties() (*core.DynamicProperties, error)
+	GetChainParameters() (*core.ChainParameters, error)
 
 	// Market Management
 	GetMarketOrderByAccount(addr string) (*core.MarketOrderList, error)
@@ -82,18 +89,39 @@ type TronClient interface {
 	GetBurnTrx() (*api.NumberMessage, error)
 
 	// Contracts
-	DeployContract(from, contractName string, abi *core.SmartContract_ABI, codeStr string, feeLimit, curPercent, oeLimit int64) (*api.TransactionExtention, error)
+	DeployContract(from, contractName string, abi *core.SmartContract_ABI, codeStr string, feeLimit, curPercent, oeLimit int64, options ...DeployOption) (*api.TransactionExtention, error)
 	TriggerContract(from, contractAddress, method, jsonString string, feeLimit, tAmount int64, tTokenID string, tTokenAmount int64) (*api.TransactionExtention, error)
+	GetContract(contractAddress string) (*core.SmartContract, error)
+	GetContractABI(contractAddress string) (*core.SmartContract_ABI, error)
+	GetContractInfo(contractAddress string) (*core.SmartContractDataWrapper, error)
+	UpdateSetting(from, contractAddress string, consumeUserResourcePercent int64) (*api.TransactionExtention, error)
+	UpdateEnergyLimit(from, contractAddress string, originEnergyLimit int64) (*api.TransactionExtention, error)
+	ClearContractABI(from, contractAddress string) (*api.TransactionExtention, error)
+	TriggerMethod(from, contractAddress string, feeLimit, callValue int64, method string, args ...any) (*api.TransactionExtention, error)
+	TriggerContractData(from, contractAddress string, data []byte, feeLimit, callValue int64) (*api.TransactionExtention, error)
+	TriggerConstantContract(from, contractAddress string, data []byte) (*api.TransactionExtention, error)
 
 	// Shielded & Privacy
 	GetSpendingKey() (*api.BytesMessage, error)
 	GetExpandedSpendingKey(key string) (*api.ExpandedSpendingKeyMessage, error)
-	GetAkFromAsk(ak string) (*api.BytesMessage, error)
-	GetNkFromNsk(nk string) (*api.BytesMessage, error)
+	GetAkFromAsk(ask string) (*api.BytesMessage, error)
+	GetNkFromNsk(nsk string) (*api.BytesMessage, error)
 	GetIncomingViewingKey(ak, nk string) (*api.IncomingViewingKeyMessage, error)
 	GetDiversifier() (*api.DiversifierMessage, error)
 	GetRcm() (*api.BytesMessage, error)
+	GetZenPaymentAddress(ivk, d string) (*api.PaymentAddressMessage, error)
 	GetNewShieldedAddress() (*api.ShieldedAddressInfo, error)
+	CreateShieldedContractParameters(params *api.PrivateShieldedTRC20Parameters) (*api.ShieldedTRC20Parameters, error)
+	CreateShieldedContractParametersWithoutAsk(params *api.PrivateShieldedTRC20ParametersWithoutAsk) (*api.ShieldedTRC20Parameters, error)
+	ScanShieldedTRC20NotesByIvk(params *api.IvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error)
+	ScanShieldedTRC20NotesByOvk(params *api.OvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error)
+	IsShieldedTRC20ContractNoteSpent(params *api.NfTRC20Parameters) (*api.NullifierResult, error)
+	GetTriggerInputForShieldedTRC20Contract(params *api.ShieldedTRC20TriggerContractParameters) (*api.BytesMessage, error)
+	CreateShieldedTransaction(params *api.PrivateParameters) (*api.TransactionExtention, error)
+	GetMerkleTreeVoucherInfo(points *core.OutputPointInfo) (*core.IncrementalMerkleVoucherInfo, error)
+	ScanNoteByIvk(params *api.IvkDecryptParameters) (*api.DecryptNotes, error)
+	ScanNoteByOvk(params *api.OvkDecryptParameters) (*api.DecryptNotes, error)
+	IsSpend(params *api.NoteParameters) (*api.SpendResult, error)
 
 	// Network
 	ListNodes() (*api.NodeList, error)
@@ -101,6 +129,8 @@ type TronClient interface {
 	GetBandwidthPrices() (*api.PricesResponseMessage, error)
 	GetEnergyPrices() (*api.PricesResponseMessage, error)
 	GetMemoFee() (*api.PricesResponseMessage, error)
+	GetNodeInfo() (*core.NodeInfo, error)
+	GetStatsInfo() (*core.MetricsInfo, error)
 
 	ConnectionManager
 }
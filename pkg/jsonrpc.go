@@ -0,0 +1,374 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// JSONRPCError is an error object returned by the JSON-RPC endpoint.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// LogFilter selects event logs for GetLogs.
+type LogFilter struct {
+	// FromBlock and ToBlock bound the block range; nil means the latest block.
+	FromBlock *big.Int
+	ToBlock   *big.Int
+	// Addresses restricts the logs to the given base58 contract addresses.
+	Addresses []string
+	// Topics restricts the logs by position; an empty position matches any topic.
+	Topics [][]common.Hash
+}
+
+// Log is an event log with TRON addresses and transaction IDs.
+type Log struct {
+	Address     string // base58 contract address
+	Topics      []common.Hash
+	Data        []byte
+	BlockNumber uint64
+	BlockHash   string
+	TxID        string
+	TxIndex     uint64
+	LogIndex    uint64
+	Removed     bool
+}
+
+// Receipt is a transaction receipt as reported by eth_getTransactionReceipt.
+type Receipt struct {
+	TxID            string
+	BlockNumber     uint64
+	BlockHash       string
+	From            string // base58
+	To              string // base58, empty for contract creation
+	ContractAddress string // base58, set for contract creation
+	Status          uint64 // 1 for success, 0 for failure
+	GasUsed         uint64 // energy used by the transaction
+	Logs            []Log
+}
+
+// JSONRPCClient queries the Ethereum-compatible JSON-RPC endpoint (/jsonrpc) of a TRON node.
+// Addresses are exchanged as base58 TRON addresses and converted for the node.
+type JSONRPCClient struct {
+	URL        string
+	httpClient *http.Client
+	timeout    time.Duration
+	apiKey     string
+	id         atomic.Uint64
+}
+
+// NewJSONRPCClient creates a client for the JSON-RPC endpoint at url, e.g. "https://api.trongrid.io/jsonrpc".
+// WithTimeout, WithAPIKey and WithHTTPClient apply.
+func NewJSONRPCClient(url string, options ...HTTPOption) *JSONRPCClient {
+	o := newHTTPOptions(options)
+	client := &JSONRPCClient{
+		URL:        url,
+		httpClient: o.httpClient,
+		timeout:    o.timeout,
+		apiKey:     o.apiKey,
+	}
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+	return client
+}
+
+// call performs a JSON-RPC request and decodes its result into result.
+func (c *JSONRPCClient) call(method string, result any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	data, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      c.id.Add(1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode request: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %w", method, &httpStatusError{code: resp.StatusCode, body: string(body)})
+	}
+
+	var msg struct {
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if msg.Error != nil {
+		return fmt.Errorf("%s: %w", method, msg.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(msg.Result, result); err != nil {
+		return fmt.Errorf("%s: invalid result: %w", method, err)
+	}
+	return nil
+}
+
+// BlockNumber returns the number of the latest block.
+func (c *JSONRPCClient) BlockNumber() (uint64, error) {
+	var num hexutil.Uint64
+	if err := c.call("eth_blockNumber", &num); err != nil {
+		return 0, err
+	}
+	return uint64(num), nil
+}
+
+// Call executes a read-only contract call with eth_call on the latest block
+// and returns the raw output. from may be empty.
+func (c *JSONRPCClient) Call(from, contractAddress string, data []byte) ([]byte, error) {
+	to, err := abi.ToEthAddress(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Call: invalid contract address: %w", err)
+	}
+	msg := map[string]any{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+	if from != "" {
+		fromAddr, err := abi.ToEthAddress(from)
+		if err != nil {
+			return nil, fmt.Errorf("Call: invalid from address: %w", err)
+		}
+		msg["from"] = fromAddr
+	}
+
+	var out hexutil.Bytes
+	if err := c.call("eth_call", &out, msg, "latest"); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CallMethod packs a method call with abi.Pack, e.g. "balanceOf(address)", and executes it with Call.
+func (c *JSONRPCClient) CallMethod(from, contractAddress, method string, params []abi.Param) ([]byte, error) {
+	data, err := abi.Pack(method, params)
+	if err != nil {
+		return nil, fmt.Errorf("CallMethod: %w", err)
+	}
+	return c.Call(from, contractAddress, data)
+}
+
+// GetLogs returns the logs matching the filter with eth_getLogs.
+func (c *JSONRPCClient) GetLogs(filter LogFilter) ([]Log, error) {
+	arg, err := filterArg(filter)
+	if err != nil {
+		return nil, fmt.Errorf("GetLogs: %w", err)
+	}
+	var logs []rpcLog
+	if err := c.call("eth_getLogs", &logs, arg); err != nil {
+		return nil, err
+	}
+	return convertLogs(logs), nil
+}
+
+// GetLogsRange scans the block range of the filter in windows of at most batchSize
+// blocks, calling fn with the logs of each window in order. Nodes limit the range
+// of a single eth_getLogs query, so indexers should use this for long ranges.
+// Both FromBlock and ToBlock must be set.
+func (c *JSONRPCClient) GetLogsRange(filter LogFilter, batchSize uint64, fn func(logs []Log) error) error {
+	if filter.FromBlock == nil || filter.ToBlock == nil {
+		return fmt.Errorf("GetLogsRange: block range must be set")
+	}
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+	from, to := filter.FromBlock.Uint64(), filter.ToBlock.Uint64()
+	for start := from; start <= to; {
+		end := start + batchSize - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		window := filter
+		window.FromBlock = new(big.Int).SetUint64(start)
+		window.ToBlock = new(big.Int).SetUint64(end)
+		logs, err := c.GetLogs(window)
+		if err != nil {
+			return fmt.Errorf("GetLogsRange: blocks %d-%d: %w", start, end, err)
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+
+		if end == to {
+			break
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+// GetTransactionReceipt returns the receipt of a transaction, or nil if it is not yet known.
+func (c *JSONRPCClient) GetTransactionReceipt(txID string) (*Receipt, error) {
+	var r *rpcReceipt
+	if err := c.call("eth_getTransactionReceipt", &r, "0x"+strings.TrimPrefix(txID, "0x")); err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, nil
+	}
+
+	receipt := &Receipt{
+		TxID:        trimHash(r.TransactionHash),
+		BlockNumber: uint64(r.BlockNumber),
+		BlockHash:   trimHash(r.BlockHash),
+		From:        abi.ToTronAddress(r.From),
+		Status:      uint64(r.Status),
+		GasUsed:     uint64(r.GasUsed),
+		Logs:        convertLogs(r.Logs),
+	}
+	if r.To != nil {
+		receipt.To = abi.ToTronAddress(*r.To)
+	}
+	if r.ContractAddress != nil {
+		receipt.ContractAddress = abi.ToTronAddress(*r.ContractAddress)
+	}
+	return receipt, nil
+}
+
+// rpcLog is a log in the JSON-RPC format.
+type rpcLog struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64 `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64 `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+// rpcReceipt is a transaction receipt in the JSON-RPC format.
+type rpcReceipt struct {
+	TransactionHash common.Hash     `json:"transactionHash"`
+	BlockNumber     hexutil.Uint64  `json:"blockNumber"`
+	BlockHash       common.Hash     `json:"blockHash"`
+	From            common.Address  `json:"from"`
+	To              *common.Address `json:"to"`
+	ContractAddress *common.Address `json:"contractAddress"`
+	Status          hexutil.Uint64  `json:"status"`
+	GasUsed         hexutil.Uint64  `json:"gasUsed"`
+	Logs            []rpcLog        `json:"logs"`
+}
+
+// filterArg converts a LogFilter into the eth_getLogs filter object.
+func filterArg(filter LogFilter) (map[string]any, error) {
+	arg := map[string]any{
+		"fromBlock": blockArg(filter.FromBlock),
+		"toBlock":   blockArg(filter.ToBlock),
+	}
+	if len(filter.Addresses) > 0 {
+		addrs := make([]common.Address, len(filter.Addresses))
+		for i, a := range filter.Addresses {
+			addr, err := abi.ToEthAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %s: %w", a, err)
+			}
+			addrs[i] = addr
+		}
+		arg["address"] = addrs
+	}
+	if len(filter.Topics) > 0 {
+		topics := make([]any, len(filter.Topics))
+		for i, t := range filter.Topics {
+			switch len(t) {
+			case 0:
+				topics[i] = nil
+			case 1:
+				topics[i] = t[0]
+			default:
+				topics[i] = t
+			}
+		}
+		arg["topics"] = topics
+	}
+	return arg, nil
+}
+
+// blockArg encodes a block number, with nil meaning the latest block.
+func blockArg(num *big.Int) string {
+	if num == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(num)
+}
+
+// convertLogs converts JSON-RPC logs to TRON addresses and transaction IDs.
+func convertLogs(logs []rpcLog) []Log {
+	out := make([]Log, len(logs))
+	for i, l := range logs {
+		out[i] = Log{
+			Address:     abi.ToTronAddress(l.Address),
+			Topics:      l.Topics,
+			Data:        l.Data,
+			BlockNumber: uint64(l.BlockNumber),
+			BlockHash:   trimHash(l.BlockHash),
+			TxID:        trimHash(l.TransactionHash),
+			TxIndex:     uint64(l.TransactionIndex),
+			LogIndex:    uint64(l.LogIndex),
+			Removed:     l.Removed,
+		}
+	}
+	return out
+}
+
+// trimHash formats a hash as hex without the 0x prefix, as used for TRON IDs.
+func trimHash(h common.Hash) string {
+	return strings.TrimPrefix(h.Hex(), "0x")
+}
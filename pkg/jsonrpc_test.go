@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRPCClient(t *testing.T) {
+	contract := "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	contractAddr, err := abi.ToEthAddress(contract)
+	require.Nil(t, err)
+	transferTopic := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+
+	var ranges [][2]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result any
+		switch req.Method {
+		case "eth_call":
+			var msg map[string]string
+			require.Nil(t, json.Unmarshal(req.Params[0], &msg))
+			assert.Equal(t, contractAddr.Hex(), common.HexToAddress(msg["to"]).Hex())
+			assert.Equal(t, "0x70a08231", msg["data"][:10])
+			result = hexutil.Bytes(common.LeftPadBytes([]byte{0x2a}, 32))
+		case "eth_getLogs":
+			var filter struct {
+				FromBlock string           `json:"fromBlock"`
+				ToBlock   string           `json:"toBlock"`
+				Address   []common.Address `json:"address"`
+				Topics    []common.Hash    `json:"topics"`
+			}
+			require.Nil(t, json.Unmarshal(req.Params[0], &filter))
+			assert.Equal(t, []common.Address{contractAddr}, filter.Address)
+			assert.Equal(t, []common.Hash{transferTopic}, filter.Topics)
+			ranges = append(ranges, [2]string{filter.FromBlock, filter.ToBlock})
+			result = []map[string]any{{
+				"address":         contractAddr,
+				"topics":          []common.Hash{transferTopic},
+				"data":            "0x01",
+				"blockNumber":     filter.FromBlock,
+				"transactionHash": common.HexToHash("0xabcd"),
+				"logIndex":        "0x0",
+			}}
+		default:
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer server.Close()
+
+	client := NewJSONRPCClient(server.URL)
+
+	out, err := client.CallMethod("", contract, "balanceOf(address)", []abi.Param{{"address": contract}})
+	require.Nil(t, err)
+	assert.Equal(t, big.NewInt(42), new(big.Int).SetBytes(out))
+
+	var logs []Log
+	filter := LogFilter{
+		FromBlock: big.NewInt(100),
+		ToBlock:   big.NewInt(349),
+		Addresses: []string{contract},
+		Topics:    [][]common.Hash{{transferTopic}},
+	}
+	err = client.GetLogsRange(filter, 100, func(batch []Log) error {
+		logs = append(logs, batch...)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, [][2]string{{"0x64", "0xc7"}, {"0xc8", "0x12b"}, {"0x12c", "0x15d"}}, ranges)
+	require.Len(t, logs, 3)
+	assert.Equal(t, contract, logs[0].Address)
+	assert.Equal(t, uint64(200), logs[1].BlockNumber)
+	assert.Equal(t, common.HexToHash("0xabcd").Hex()[2:], logs[0].TxID)
+
+	_, err = client.GetTransactionReceipt("abcd")
+	var rpcErr *JSONRPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32601, rpcErr.Code)
+}
@@ -0,0 +1,458 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/txtrie"
+)
+
+// Errors returned by LightClient when a block does not fit the trusted chain.
+var (
+	ErrChainMismatch     = errors.New("block does not extend the trusted chain")
+	ErrScheduleViolation = errors.New("block was produced out of the witness schedule")
+	ErrScheduleUntrusted = errors.New("no trusted witness schedule for the maintenance period")
+)
+
+const (
+	// lightClientBatch is the number of blocks requested at once while syncing.
+	lightClientBatch = 100
+	// lightClientHistory is the number of recent headers kept by the light client.
+	lightClientHistory = 4096
+)
+
+// LightHeader is a block header accepted by the light client.
+type LightHeader struct {
+	Number    int64
+	ID        []byte
+	Timestamp time.Time
+	Witness   string
+	// Signer is the address that signed the block: the witness or its witness
+	// permission key.
+	Signer string
+	Header *core.BlockHeader
+}
+
+// WitnessSchedule holds the elected super representatives of a maintenance period.
+type WitnessSchedule struct {
+	Witnesses []string
+	// Signers maps the witnesses signing their blocks with a witness permission
+	// key to the address of that key.
+	Signers map[string]string
+	// NextMaintenance is the end of the period.
+	NextMaintenance time.Time
+}
+
+// ScheduleSource returns the witness schedule of the current maintenance period
+// from a source trusted by the caller.
+type ScheduleSource func() (*WitnessSchedule, error)
+
+// TrustedSchedule reads the witness schedule from a trusted node: its elected
+// super representatives with their witness permission keys, and its next
+// maintenance time. It must not be the node followed by the light client.
+func TrustedSchedule(trusted TronClient) ScheduleSource {
+	return func() (*WitnessSchedule, error) {
+		ranked, err := NewSRToolkit(trusted).Witnesses()
+		if err != nil {
+			return nil, err
+		}
+		next, err := trusted.GetNextMaintenanceTime()
+		if err != nil {
+			return nil, err
+		}
+		schedule := &WitnessSchedule{Signers: make(map[string]string), NextMaintenance: time.UnixMilli(next.GetNum())}
+		for _, w := range ranked {
+			if !w.SuperRepresentative() {
+				continue
+			}
+			account, err := trusted.GetAccount(w.Address)
+			if err != nil {
+				return nil, fmt.Errorf("witness %s: %w", w.Address, err)
+			}
+			schedule.Witnesses = append(schedule.Witnesses, w.Address)
+			if signer := WitnessPermissionAddress(account); signer != w.Address {
+				schedule.Signers[w.Address] = signer
+			}
+		}
+		return schedule, nil
+	}
+}
+
+// LightCheckpoint is the trusted starting point of a light client: a block and,
+// optionally, the witness schedule of its maintenance period.
+type LightCheckpoint struct {
+	Number   int64
+	ID       []byte
+	Schedule *WitnessSchedule
+}
+
+// LightClient follows the chain of block headers served by an untrusted node,
+// starting from a trusted checkpoint. Each header must extend the previous one,
+// be signed by an elected super representative, and respect the round-robin
+// schedule of the maintenance period. A block is solidified once more than two
+// thirds of the super representatives have produced blocks on top of it.
+//
+// A witness may sign its blocks with the key of its witness permission, given in
+// the Signers of the schedule.
+//
+// The elected witnesses never come from the followed node: they are given with
+// the checkpoint or read from a trusted ScheduleSource at each maintenance.
+// A source returns the current schedule, so the checkpoint should be in the
+// current maintenance period, and Sync should run at least once per period.
+type LightClient struct {
+	client TronClient
+	source ScheduleSource
+
+	mu        sync.RWMutex
+	headers   map[int64]*LightHeader
+	head      *LightHeader
+	solid     *LightHeader
+	witnesses []string          // elected super representatives
+	active    map[string]bool   // witnesses as a set
+	signers   map[string]string // witness to the address of its witness permission key
+	periodEnd time.Time         // next maintenance
+	renew     bool              // the period ended and its schedule is not known yet
+	slots     map[int64]string  // schedule position to witness, learned from the headers
+	latest    map[string]int64  // number of the last block produced by each witness
+}
+
+// NewLightClient creates a light client following the node from a trusted
+// checkpoint. The source gives the witness schedule of each maintenance period,
+// and of the checkpoint period when the checkpoint has none. Without a source,
+// Sync stops at each maintenance with ErrScheduleUntrusted until SetSchedule
+// is called.
+func NewLightClient(client TronClient, checkpoint LightCheckpoint, source ScheduleSource) (*LightClient, error) {
+	number, id := checkpoint.Number, checkpoint.ID
+	block, err := client.GetBlockByNum(number)
+	if err != nil {
+		return nil, err
+	}
+	raw := block.GetBlockHeader().GetRawData()
+	if raw == nil {
+		return nil, fmt.Errorf("NewLightClient: checkpoint block %d has no header", number)
+	}
+	blockID, err := BlockID(raw)
+	if err != nil {
+		return nil, err
+	}
+	if raw.GetNumber() != number || !bytes.Equal(blockID, id) {
+		return nil, fmt.Errorf("NewLightClient: checkpoint %d: %w", number, ErrBlockIDMismatch)
+	}
+
+	trusted := &LightHeader{
+		Number:    number,
+		ID:        blockID,
+		Timestamp: time.UnixMilli(raw.GetTimestamp()),
+		Witness:   base58.EncodeCheck(raw.GetWitnessAddress()),
+		Header:    block.GetBlockHeader(),
+	}
+	l := &LightClient{
+		client:  client,
+		source:  source,
+		headers: map[int64]*LightHeader{number: trusted},
+		head:    trusted,
+		solid:   trusted,
+		latest:  make(map[string]int64),
+		renew:   true,
+	}
+	switch {
+	case checkpoint.Schedule != nil:
+		err = l.SetSchedule(checkpoint.Schedule)
+	case source != nil:
+		err = l.renewSchedule()
+	default:
+		err = fmt.Errorf("NewLightClient: %w", ErrScheduleUntrusted)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Head returns the latest header accepted by the light client.
+func (l *LightClient) Head() *LightHeader {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.head
+}
+
+// Solidified returns the latest solidified header.
+func (l *LightClient) Solidified() *LightHeader {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.solid
+}
+
+// Header returns an accepted header among the recent ones kept by the light client.
+func (l *LightClient) Header(number int64) (*LightHeader, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	h, ok := l.headers[number]
+	return h, ok
+}
+
+// Witnesses returns the super representatives of the current maintenance period.
+func (l *LightClient) Witnesses() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]string(nil), l.witnesses...)
+}
+
+// VerifyTransaction checks a transaction inclusion proof against the solidified
+// header with the given number, and returns the ID of the proven transaction.
+func (l *LightClient) VerifyTransaction(proof *txtrie.Proof, number int64) ([]byte, error) {
+	l.mu.RLock()
+	h, ok := l.headers[number]
+	solid := l.solid.Number
+	l.mu.RUnlock()
+	if number > solid {
+		return nil, fmt.Errorf("block %d is not solidified yet (solidified %d)", number, solid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("block %d is not among the recent headers", number)
+	}
+	return proof.Verify(h.Header)
+}
+
+// Sync fetches and validates the headers up to the head of the node, and returns
+// the new head number. Unsolidified headers are dropped once if the node
+// switched to another fork.
+func (l *LightClient) Sync() (int64, error) {
+	l.mu.RLock()
+	renew := l.renew
+	l.mu.RUnlock()
+	if renew {
+		if err := l.renewSchedule(); err != nil {
+			return l.Head().Number, err
+		}
+	}
+
+	now, err := l.client.GetNowBlock()
+	if err != nil {
+		return 0, err
+	}
+	target := now.GetBlockHeader().GetRawData().GetNumber()
+
+	rolledBack := false
+	for {
+		start := l.Head().Number + 1
+		if start > target {
+			break
+		}
+		end := min(start+lightClientBatch, target+1)
+		list, err := l.client.GetBlockByLimitNext(start, end)
+		if err != nil {
+			return l.Head().Number, err
+		}
+		blocks := list.GetBlock()
+		if len(blocks) == 0 {
+			break
+		}
+		sort.Slice(blocks, func(i, j int) bool {
+			return blocks[i].GetBlockHeader().GetRawData().GetNumber() < blocks[j].GetBlockHeader().GetRawData().GetNumber()
+		})
+		for _, block := range blocks {
+			maintenance, err := l.apply(block)
+			if errors.Is(err, ErrChainMismatch) && !rolledBack && l.rollback() {
+				rolledBack = true
+				break
+			}
+			if err == nil && maintenance {
+				err = l.renewSchedule()
+			}
+			if err != nil {
+				return l.Head().Number, err
+			}
+		}
+	}
+	return l.Head().Number, nil
+}
+
+// apply validates a block against the trusted head and accepts its header. It
+// reports whether the block reached the maintenance time, which closes the
+// period: the next blocks follow the schedule of the new election.
+func (l *LightClient) apply(block *api.BlockExtention) (bool, error) {
+	header := block.GetBlockHeader()
+	raw := header.GetRawData()
+	if raw == nil {
+		return false, fmt.Errorf("block has no header")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	num := raw.GetNumber()
+	if l.renew {
+		return false, fmt.Errorf("block %d: %w", num, ErrScheduleUntrusted)
+	}
+	if num != l.head.Number+1 || !bytes.Equal(raw.GetParentHash(), l.head.ID) {
+		return false, fmt.Errorf("block %d: %w", num, ErrChainMismatch)
+	}
+	id, err := BlockID(raw)
+	if err != nil {
+		return false, err
+	}
+	if len(block.GetBlockid()) > 0 && !bytes.Equal(block.GetBlockid(), id) {
+		return false, fmt.Errorf("block %d: %w", num, ErrBlockIDMismatch)
+	}
+
+	interval := BlockInterval.Milliseconds()
+	ts := raw.GetTimestamp()
+	if elapsed := ts - l.head.Timestamp.UnixMilli(); elapsed <= 0 || elapsed%interval != 0 {
+		return false, fmt.Errorf("block %d: %w: timestamp %d is not a slot after the parent", num, ErrScheduleViolation, ts)
+	}
+
+	signer, err := RecoverBlockWitness(header)
+	if err != nil {
+		return false, fmt.Errorf("block %d: %w", num, err)
+	}
+	witness := base58.EncodeCheck(raw.GetWitnessAddress())
+	if signer != witness && signer != l.signers[witness] {
+		return false, fmt.Errorf("block %d: %w: signed by %s", num, ErrWitnessMismatch, signer)
+	}
+	if !l.active[witness] {
+		return false, fmt.Errorf("block %d: %w: %s", num, ErrUnknownWitness, witness)
+	}
+	slot := ts / interval % int64(len(l.witnesses))
+	if scheduled, ok := l.slots[slot]; ok && scheduled != witness {
+		return false, fmt.Errorf("block %d: %w: slot of %s signed by %s", num, ErrScheduleViolation, scheduled, witness)
+	}
+	for s, w := range l.slots {
+		if w == witness && s != slot {
+			return false, fmt.Errorf("block %d: %w: %s signed another slot", num, ErrScheduleViolation, witness)
+		}
+	}
+
+	root, err := TxTrieRoot(blockTransactions(block))
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(root, raw.GetTxTrieRoot()) {
+		return false, fmt.Errorf("block %d: %w", num, ErrTxTrieRootMismatch)
+	}
+
+	h := &LightHeader{Number: num, ID: id, Timestamp: time.UnixMilli(ts), Witness: witness, Signer: signer, Header: header}
+	l.slots[slot] = witness
+	l.headers[num] = h
+	delete(l.headers, num-lightClientHistory)
+	l.head = h
+	l.latest[witness] = num
+	l.updateSolid()
+	if !h.Timestamp.Before(l.periodEnd) {
+		l.renew = true
+	}
+	return l.renew, nil
+}
+
+// updateSolid advances the solidified header to the highest block at or below
+// the last block of more than two thirds of the witnesses.
+func (l *LightClient) updateSolid() {
+	need := len(l.witnesses)*2/3 + 1
+	nums := make([]int64, 0, len(l.witnesses))
+	for _, w := range l.witnesses {
+		nums = append(nums, l.latest[w])
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] > nums[j] })
+	if len(nums) < need || nums[need-1] <= l.solid.Number {
+		return
+	}
+	if h, ok := l.headers[nums[need-1]]; ok {
+		l.solid = h
+	}
+}
+
+// rollback drops the unsolidified headers, and reports whether any were dropped.
+func (l *LightClient) rollback() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.head.Number == l.solid.Number {
+		return false
+	}
+	for num := l.solid.Number + 1; num <= l.head.Number; num++ {
+		delete(l.headers, num)
+	}
+	l.head = l.solid
+	for w, num := range l.latest {
+		if num > l.solid.Number {
+			delete(l.latest, w)
+		}
+	}
+	return true
+}
+
+// renewSchedule loads the schedule of the current period from the trusted source.
+func (l *LightClient) renewSchedule() error {
+	if l.source == nil {
+		return ErrScheduleUntrusted
+	}
+	schedule, err := l.source()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrScheduleUntrusted, err)
+	}
+	return l.SetSchedule(schedule)
+}
+
+// SetSchedule sets the witness schedule of the maintenance period following the
+// head, from a source trusted by the caller, and starts learning the order of the
+// witnesses in the period. The period must end after the head.
+func (l *LightClient) SetSchedule(schedule *WitnessSchedule) error {
+	if len(schedule.Witnesses) == 0 {
+		return fmt.Errorf("%w: the schedule has no super representatives", ErrScheduleUntrusted)
+	}
+	active := make(map[string]bool, len(schedule.Witnesses))
+	for _, w := range schedule.Witnesses {
+		if _, err := base58.DecodeCheck(w); err != nil {
+			return fmt.Errorf("%w: invalid witness address %s", ErrScheduleUntrusted, w)
+		}
+		active[w] = true
+	}
+	signers := make(map[string]string, len(schedule.Signers))
+	for w, signer := range schedule.Signers {
+		if _, err := base58.DecodeCheck(signer); err != nil || !active[w] {
+			return fmt.Errorf("%w: invalid witness permission %s of %s", ErrScheduleUntrusted, signer, w)
+		}
+		signers[w] = signer
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !schedule.NextMaintenance.After(l.head.Timestamp) {
+		return fmt.Errorf("%w: the period ended at %s, before block %d", ErrScheduleUntrusted, schedule.NextMaintenance, l.head.Number)
+	}
+	l.witnesses = append([]string(nil), schedule.Witnesses...)
+	l.active = active
+	l.signers = signers
+	l.periodEnd = schedule.NextMaintenance
+	l.slots = make(map[int64]string, len(l.witnesses))
+	l.renew = false
+	for w := range l.latest {
+		if !active[w] {
+			delete(l.latest, w)
+		}
+	}
+	return nil
+}
+
+// blockTransactions returns the transactions of a block extension.
+func blockTransactions(block *api.BlockExtention) []*core.Transaction {
+	txs := make([]*core.Transaction, len(block.GetTransactions()))
+	for i, tx := range block.GetTransactions() {
+		txs[i] = tx.GetTransaction()
+	}
+	return txs
+}
@@ -0,0 +1,371 @@
+package pkg
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/txtrie"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainStart is the timestamp of block 0 of the fake chain, on a slot boundary.
+const chainStart = 1_700_000_001_000
+
+// fakeChain is a node serving a chain produced by 27 witnesses in round-robin order.
+type fakeChain struct {
+	TronClient
+	keys        []*ecdsa.PrivateKey
+	permissions map[*ecdsa.PrivateKey]*ecdsa.PrivateKey // witness permission keys
+	blocks      []*api.BlockExtention
+	maintenance int64 // timestamp of the first maintenance
+	loads       int
+}
+
+func newFakeChain(t *testing.T, length int) *fakeChain {
+	c := &fakeChain{maintenance: chainStart + 6*3600*1000, permissions: make(map[*ecdsa.PrivateKey]*ecdsa.PrivateKey)}
+	for i := 0; i < SuperRepresentativeCount; i++ {
+		key, err := crypto.GenerateKey()
+		require.Nil(t, err)
+		c.keys = append(c.keys, key)
+	}
+	c.blocks = []*api.BlockExtention{c.block(t, 0, nil, chainStart, c.scheduled(chainStart))}
+	c.extend(t, length)
+	return c
+}
+
+// scheduled returns the key of the witness producing at a timestamp.
+func (c *fakeChain) scheduled(ts int64) *ecdsa.PrivateKey {
+	return c.keys[ts/3000%SuperRepresentativeCount]
+}
+
+// extend appends n blocks produced on schedule.
+func (c *fakeChain) extend(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		parent := c.blocks[len(c.blocks)-1]
+		ts := parent.BlockHeader.RawData.Timestamp + 3000
+		c.blocks = append(c.blocks, c.block(t, int64(len(c.blocks)), parent.Blockid, ts, c.scheduled(ts)))
+	}
+}
+
+// block builds a block with one transaction, signed with the witness permission
+// key of the witness if it has one.
+func (c *fakeChain) block(t *testing.T, num int64, parent []byte, ts int64, key *ecdsa.PrivateKey, txs ...*core.Transaction) *api.BlockExtention {
+	if len(txs) == 0 {
+		txs = []*core.Transaction{{RawData: &core.TransactionRaw{Expiration: ts, Timestamp: num}}}
+	}
+	root, err := TxTrieRoot(txs)
+	require.Nil(t, err)
+	witness, _ := base58.DecodeCheck(PublicKeyToAddress(key.PublicKey))
+	raw := &core.BlockHeaderRaw{Number: num, ParentHash: parent, Timestamp: ts, TxTrieRoot: root, WitnessAddress: witness}
+	hash, err := BlockHash(raw)
+	require.Nil(t, err)
+	signer := key
+	if permission, ok := c.permissions[key]; ok {
+		signer = permission
+	}
+	sig, err := crypto.Sign(hash, signer)
+	require.Nil(t, err)
+	id, err := BlockID(raw)
+	require.Nil(t, err)
+
+	block := &api.BlockExtention{BlockHeader: &core.BlockHeader{RawData: raw, WitnessSignature: sig}, Blockid: id}
+	for _, tx := range txs {
+		txid, _ := transactionID(tx.RawData)
+		block.Transactions = append(block.Transactions, &api.TransactionExtention{Transaction: tx, Txid: txid})
+	}
+	return block
+}
+
+func (c *fakeChain) ListWitnesses() (*api.WitnessList, error) {
+	c.loads++
+	list := &api.WitnessList{}
+	for i, key := range c.keys {
+		addr, _ := base58.DecodeCheck(PublicKeyToAddress(key.PublicKey))
+		list.Witnesses = append(list.Witnesses, &core.Witness{Address: addr, VoteCount: int64(1000 - i)})
+	}
+	return list, nil
+}
+
+func (c *fakeChain) GetAccount(address string) (*core.Account, error) {
+	addr, _ := base58.DecodeCheck(address)
+	account := &core.Account{Address: addr}
+	for _, key := range c.keys {
+		if permission, ok := c.permissions[key]; ok && PublicKeyToAddress(key.PublicKey) == address {
+			signer, _ := base58.DecodeCheck(PublicKeyToAddress(permission.PublicKey))
+			account.WitnessPermission = &core.Permission{Keys: []*core.Key{{Address: signer, Weight: 1}}}
+		}
+	}
+	return account, nil
+}
+
+// schedule returns the witness schedule of the period of the head.
+func (c *fakeChain) schedule(t *testing.T) *WitnessSchedule {
+	next, err := c.GetNextMaintenanceTime()
+	require.Nil(t, err)
+	schedule := &WitnessSchedule{Signers: make(map[string]string), NextMaintenance: time.UnixMilli(next.GetNum())}
+	for _, key := range c.keys {
+		witness := PublicKeyToAddress(key.PublicKey)
+		schedule.Witnesses = append(schedule.Witnesses, witness)
+		if permission, ok := c.permissions[key]; ok {
+			schedule.Signers[witness] = PublicKeyToAddress(permission.PublicKey)
+		}
+	}
+	return schedule
+}
+
+func (c *fakeChain) GetNextMaintenanceTime() (*api.NumberMessage, error) {
+	next := c.maintenance
+	for head := c.blocks[len(c.blocks)-1].BlockHeader.RawData.Timestamp; next <= head; {
+		next += 6 * 3600 * 1000
+	}
+	return GetMessageNumber(next), nil
+}
+
+func (c *fakeChain) GetNowBlock() (*api.BlockExtention, error) {
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *fakeChain) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	return c.blocks[num], nil
+}
+
+func (c *fakeChain) GetBlockByLimitNext(start, end int64) (*api.BlockListExtention, error) {
+	end = min(end, int64(len(c.blocks)))
+	return &api.BlockListExtention{Block: c.blocks[start:end]}, nil
+}
+
+func TestLightClientSync(t *testing.T) {
+	chain := newFakeChain(t, 60)
+	light, err := NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+	assert.Len(t, light.Witnesses(), SuperRepresentativeCount)
+
+	head, err := light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(60), head)
+	assert.Equal(t, chain.blocks[60].Blockid, light.Head().ID)
+	assert.Equal(t, time.UnixMilli(chain.blocks[60].BlockHeader.RawData.Timestamp), light.Head().Timestamp)
+
+	// The last 27 blocks were produced by distinct witnesses: 19 of them
+	// produced block 42 or later.
+	assert.Equal(t, int64(42), light.Solidified().Number)
+
+	tree, err := txtrie.New(blockTransactions(chain.blocks[10]))
+	require.Nil(t, err)
+	proof, err := tree.ProveIndex(0)
+	require.Nil(t, err)
+	txid, err := light.VerifyTransaction(proof, 10)
+	require.Nil(t, err)
+	assert.Equal(t, chain.blocks[10].Transactions[0].Txid, txid)
+	_, err = light.VerifyTransaction(proof, 11)
+	assert.ErrorIs(t, err, txtrie.ErrInvalidProof)
+	_, err = light.VerifyTransaction(proof, 50)
+	assert.ErrorContains(t, err, "not solidified")
+
+	chain.extend(t, 5)
+	head, err = light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(65), head)
+	assert.Equal(t, int64(47), light.Solidified().Number)
+	assert.Equal(t, 1, chain.loads)
+}
+
+func TestLightClientCheckpoint(t *testing.T) {
+	chain := newFakeChain(t, 3)
+	_, err := NewLightClient(chain, LightCheckpoint{Number: 2, ID: chain.blocks[1].Blockid}, TrustedSchedule(chain))
+	assert.ErrorIs(t, err, ErrBlockIDMismatch)
+}
+
+func TestLightClientRejects(t *testing.T) {
+	chain := newFakeChain(t, 4)
+	light, err := NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+	_, err = light.Sync()
+	require.Nil(t, err)
+
+	parent := chain.blocks[4]
+	ts := parent.BlockHeader.RawData.Timestamp + 3000
+
+	// The producer of block 4 signs the next slot too.
+	chain.blocks = append(chain.blocks, chain.block(t, 5, parent.Blockid, ts, chain.scheduled(ts-3000)))
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrScheduleViolation)
+
+	// A candidate outside the elected witnesses.
+	outsider, _ := crypto.GenerateKey()
+	chain.blocks[5] = chain.block(t, 5, parent.Blockid, ts, outsider)
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrUnknownWitness)
+
+	// A block reusing the timestamp of its parent.
+	chain.blocks[5] = chain.block(t, 5, parent.Blockid, ts-3000, chain.scheduled(ts))
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrScheduleViolation)
+
+	// A block whose transactions do not match the header.
+	chain.blocks[5] = chain.block(t, 5, parent.Blockid, ts, chain.scheduled(ts))
+	chain.blocks[5].Transactions = nil
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrTxTrieRootMismatch)
+
+	// The chain continues on schedule.
+	chain.blocks = chain.blocks[:5]
+	chain.extend(t, 1)
+	head, err := light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(5), head)
+}
+
+func TestLightClientFork(t *testing.T) {
+	chain := newFakeChain(t, 60)
+	light, err := NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+	_, err = light.Sync()
+	require.Nil(t, err)
+	require.Equal(t, int64(42), light.Solidified().Number)
+
+	// The node switches to a fork from block 50, skipping a slot.
+	chain.blocks = chain.blocks[:50]
+	parent := chain.blocks[49]
+	ts := parent.BlockHeader.RawData.Timestamp + 6000
+	chain.blocks = append(chain.blocks, chain.block(t, 50, parent.Blockid, ts, chain.scheduled(ts)))
+	chain.extend(t, 20)
+
+	head, err := light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(70), head)
+	h, ok := light.Header(50)
+	require.True(t, ok)
+	assert.Equal(t, chain.blocks[50].Blockid, h.ID)
+
+	// A fork below the solidified block is rejected.
+	chain.blocks = chain.blocks[:40]
+	parent = chain.blocks[39]
+	ts = parent.BlockHeader.RawData.Timestamp + 6000
+	chain.blocks = append(chain.blocks, chain.block(t, 40, parent.Blockid, ts, chain.scheduled(ts)))
+	chain.extend(t, 40)
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrChainMismatch)
+}
+
+func TestLightClientMaintenance(t *testing.T) {
+	chain := newFakeChain(t, 0)
+	chain.maintenance = chainStart + 30*3000
+	light, err := NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+
+	// The witnesses keep their order, but the schedule is learned again after
+	// the block reaching the maintenance time.
+	chain.extend(t, 40)
+	head, err := light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(40), head)
+	assert.Equal(t, 2, chain.loads)
+}
+
+func TestLightClientUntrustedSchedule(t *testing.T) {
+	chain := newFakeChain(t, 0)
+	chain.maintenance = chainStart + 30*3000
+	checkpoint := LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid, Schedule: chain.schedule(t)}
+	light, err := NewLightClient(chain, checkpoint, nil)
+	require.Nil(t, err)
+	assert.Zero(t, chain.loads)
+
+	// Without a trusted source, the light client stops at the maintenance.
+	chain.extend(t, 40)
+	head, err := light.Sync()
+	assert.ErrorIs(t, err, ErrScheduleUntrusted)
+	assert.Equal(t, int64(30), head)
+	head, err = light.Sync()
+	assert.ErrorIs(t, err, ErrScheduleUntrusted)
+	assert.Equal(t, int64(30), head)
+
+	// The schedule of the ended period is refused.
+	assert.ErrorIs(t, light.SetSchedule(checkpoint.Schedule), ErrScheduleUntrusted)
+	require.Nil(t, light.SetSchedule(chain.schedule(t)))
+	head, err = light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(40), head)
+	assert.Zero(t, chain.loads)
+
+	_, err = NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, nil)
+	assert.ErrorIs(t, err, ErrScheduleUntrusted)
+}
+
+func TestLightClientLyingNode(t *testing.T) {
+	trusted := newFakeChain(t, 0)
+
+	// The node follows the checkpoint with blocks of its own witnesses, and
+	// lists them as the elected ones.
+	node := newFakeChain(t, 0)
+	node.blocks = []*api.BlockExtention{trusted.blocks[0]}
+	node.extend(t, 10)
+	list, err := node.ListWitnesses()
+	require.Nil(t, err)
+	require.Len(t, list.Witnesses, SuperRepresentativeCount)
+	node.loads = 0
+
+	light, err := NewLightClient(node, LightCheckpoint{Number: 0, ID: trusted.blocks[0].Blockid}, TrustedSchedule(trusted))
+	require.Nil(t, err)
+	head, err := light.Sync()
+	assert.ErrorIs(t, err, ErrUnknownWitness)
+	assert.Equal(t, int64(0), head)
+	assert.Zero(t, node.loads)
+	assert.Equal(t, 1, trusted.loads)
+
+	// The same with the schedule given in the checkpoint.
+	light, err = NewLightClient(node, LightCheckpoint{Number: 0, ID: trusted.blocks[0].Blockid, Schedule: trusted.schedule(t)}, nil)
+	require.Nil(t, err)
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrUnknownWitness)
+	assert.Zero(t, node.loads)
+}
+
+func TestLightClientWitnessPermission(t *testing.T) {
+	chain := newFakeChain(t, 0)
+	producer := chain.scheduled(chainStart + 3000)
+	permission, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	chain.permissions[producer] = permission
+	chain.extend(t, 30)
+
+	light, err := NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+	head, err := light.Sync()
+	require.Nil(t, err)
+	assert.Equal(t, int64(30), head)
+	h, ok := light.Header(1)
+	require.True(t, ok)
+	assert.Equal(t, PublicKeyToAddress(producer.PublicKey), h.Witness)
+	assert.Equal(t, PublicKeyToAddress(permission.PublicKey), h.Signer)
+
+	// The same blocks are refused when the schedule does not know the key.
+	schedule := chain.schedule(t)
+	schedule.Signers = nil
+	light, err = NewLightClient(chain, LightCheckpoint{Number: 0, ID: chain.blocks[0].Blockid, Schedule: schedule}, nil)
+	require.Nil(t, err)
+	head, err = light.Sync()
+	assert.ErrorIs(t, err, ErrWitnessMismatch)
+	assert.Equal(t, int64(0), head)
+
+	// Another witness signing with the permission key is refused too.
+	chain.blocks = chain.blocks[:30]
+	parent := chain.blocks[29]
+	ts := parent.BlockHeader.RawData.Timestamp + 3000
+	block := chain.block(t, 30, parent.Blockid, ts, chain.scheduled(ts))
+	hash, err := BlockHash(block.BlockHeader.RawData)
+	require.Nil(t, err)
+	block.BlockHeader.WitnessSignature, err = crypto.Sign(hash, permission)
+	require.Nil(t, err)
+	chain.blocks = append(chain.blocks, block)
+	light, err = NewLightClient(chain, LightCheckpoint{Number: 29, ID: parent.Blockid}, TrustedSchedule(chain))
+	require.Nil(t, err)
+	_, err = light.Sync()
+	assert.ErrorIs(t, err, ErrWitnessMismatch)
+}
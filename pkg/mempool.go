@@ -0,0 +1,271 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+)
+
+// DefaultReceiptGrace is how long a transaction that left the pool without a
+// receipt keeps being checked before it is reported dropped. Solidity nodes
+// serve the receipt once the block is solidified, 19 blocks after it.
+const DefaultReceiptGrace = 20 * BlockInterval
+
+// PendingTransaction is a transaction seen in the pending pool of the node.
+type PendingTransaction struct {
+	TxID        string
+	Transaction *core.Transaction
+	// Decoded is nil when the transaction could not be decoded.
+	Decoded   *DecodedTransaction
+	FirstSeen time.Time
+}
+
+// RemovedTransaction is a transaction that left the pending pool, either
+// included in a block or dropped by the node.
+type RemovedTransaction struct {
+	TxID        string
+	Included    bool
+	BlockNumber int64
+	// Pending is the time the transaction was seen in the pool.
+	Pending time.Duration
+}
+
+// MempoolUpdate holds the changes of the pending pool since the previous poll.
+type MempoolUpdate struct {
+	At      time.Time
+	Added   []*PendingTransaction
+	Removed []RemovedTransaction
+	// Size is the pool size reported by the node.
+	Size int64
+}
+
+// MempoolStats holds the pool size and the counters of the watcher.
+type MempoolStats struct {
+	PolledAt time.Time
+	Size     int64
+	Tracked  int
+	Seen     int64
+	Included int64
+	Dropped  int64
+}
+
+// MempoolWatcher follows the pending pool of a node: each poll lists the pending
+// transaction IDs, fetches and decodes the new ones and checks whether the
+// transactions that left the pool were included in a block.
+type MempoolWatcher struct {
+	client  TronClient
+	decoder *TransactionDecoder
+	now     func() time.Time
+	grace   time.Duration
+
+	poll    sync.Mutex
+	mu      sync.Mutex
+	tracked map[string]*PendingTransaction
+	left    map[string]time.Time // when tracked transactions left the pool
+	stats   MempoolStats
+}
+
+// NewMempoolWatcher creates a watcher for the client. The decoder may be nil,
+// in which case Decoded is never set.
+func NewMempoolWatcher(client TronClient, decoder *TransactionDecoder) *MempoolWatcher {
+	return &MempoolWatcher{
+		client:  client,
+		decoder: decoder,
+		now:     time.Now,
+		grace:   DefaultReceiptGrace,
+		tracked: make(map[string]*PendingTransaction),
+		left:    make(map[string]time.Time),
+	}
+}
+
+// SetReceiptGrace sets how long a transaction that left the pool without a
+// receipt keeps being checked before it is reported dropped, DefaultReceiptGrace
+// by default. A client querying the full node rather than a solidity node sees
+// the receipts as soon as the block is produced, and may use a shorter grace.
+func (m *MempoolWatcher) SetReceiptGrace(grace time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grace = grace
+}
+
+// Poll compares the pending pool with the previous poll. New transactions that
+// leave the pool before they are fetched are not reported. A transaction that
+// left the pool is reported included once its receipt is found, and dropped when
+// there is still no receipt after the receipt grace; until then, or while its
+// receipt cannot be queried, it stays tracked and is checked at each poll.
+func (m *MempoolWatcher) Poll() (*MempoolUpdate, error) {
+	m.poll.Lock()
+	defer m.poll.Unlock()
+
+	list, err := m.client.GetTransactionListFromPending()
+	if err != nil {
+		return nil, fmt.Errorf("Poll: %w", err)
+	}
+	size, err := m.client.GetPendingSize()
+	if err != nil {
+		return nil, fmt.Errorf("Poll: %w", err)
+	}
+
+	update := &MempoolUpdate{At: m.now(), Size: size.GetNum()}
+	pending := make(map[string]bool, len(list.GetTxId()))
+	var added []string
+	m.mu.Lock()
+	for _, id := range list.GetTxId() {
+		id = strings.ToLower(id)
+		pending[id] = true
+		if m.tracked[id] == nil {
+			added = append(added, id)
+		}
+	}
+	var removed []*PendingTransaction
+	left := make(map[string]time.Time)
+	for id, tx := range m.tracked {
+		if pending[id] {
+			delete(m.left, id) // back in the pool
+			continue
+		}
+		if _, ok := m.left[id]; !ok {
+			m.left[id] = update.At
+		}
+		left[id] = m.left[id]
+		removed = append(removed, tx)
+	}
+	grace := m.grace
+	m.mu.Unlock()
+
+	for _, id := range added {
+		tx, err := m.client.GetTransactionFromPending(id)
+		if err != nil || tx.GetRawData() == nil {
+			continue
+		}
+		ptx := &PendingTransaction{TxID: id, Transaction: tx, FirstSeen: update.At}
+		if m.decoder != nil {
+			ptx.Decoded, _ = m.decoder.Decode(tx)
+		}
+		update.Added = append(update.Added, ptx)
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].TxID < removed[j].TxID })
+	for _, tx := range removed {
+		rtx := RemovedTransaction{TxID: tx.TxID, Pending: left[tx.TxID].Sub(tx.FirstSeen)}
+		info, err := m.client.GetTransactionInfoByID(tx.TxID)
+		switch {
+		case err == nil:
+			rtx.BlockNumber = info.GetBlockNumber()
+			rtx.Included = rtx.BlockNumber > 0
+		case !errors.Is(err, ErrTransactionInfoNotFound):
+			continue
+		case update.At.Sub(left[tx.TxID]) < grace:
+			continue // the receipt may not be served yet
+		}
+		update.Removed = append(update.Removed, rtx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tx := range update.Added {
+		m.tracked[tx.TxID] = tx
+	}
+	for _, tx := range update.Removed {
+		delete(m.tracked, tx.TxID)
+		delete(m.left, tx.TxID)
+		if tx.Included {
+			m.stats.Included++
+		} else {
+			m.stats.Dropped++
+		}
+	}
+	m.stats.Seen += int64(len(update.Added))
+	m.stats.PolledAt = update.At
+	m.stats.Size = update.Size
+	m.stats.Tracked = len(m.tracked)
+	return update, nil
+}
+
+// Watch polls the pending pool at each interval and passes the updates with
+// changes to fn, until the context is done or a poll fails.
+func (m *MempoolWatcher) Watch(ctx context.Context, interval time.Duration, fn func(*MempoolUpdate)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		update, err := m.Poll()
+		if err != nil {
+			return err
+		}
+		if len(update.Added) > 0 || len(update.Removed) > 0 {
+			fn(update)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pending returns the tracked transactions by the time they were first seen.
+func (m *MempoolWatcher) Pending() []*PendingTransaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	txs := make([]*PendingTransaction, 0, len(m.tracked))
+	for _, tx := range m.tracked {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if !txs[i].FirstSeen.Equal(txs[j].FirstSeen) {
+			return txs[i].FirstSeen.Before(txs[j].FirstSeen)
+		}
+		return txs[i].TxID < txs[j].TxID
+	})
+	return txs
+}
+
+// Stats returns the pool size of the last poll and the counters of the watcher.
+func (m *MempoolWatcher) Stats() MempoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// ServeHTTP writes the stats of the last poll in Prometheus exposition format.
+func (m *MempoolWatcher) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	stats := m.Stats()
+	_ = stats.WritePrometheus(w)
+}
+
+// WritePrometheus writes the stats in Prometheus text exposition format.
+func (s *MempoolStats) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	metric := func(name, kind, help string, value float64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatFloat(value))
+	}
+
+	if !s.PolledAt.IsZero() {
+		metric("tron_mempool_last_poll_timestamp_seconds", "gauge", "Time of the last poll of the pending pool.", float64(s.PolledAt.Unix()))
+	}
+	metric("tron_mempool_size", "gauge", "Number of transactions in the pending pool of the node.", float64(s.Size))
+	metric("tron_mempool_tracked", "gauge", "Number of pending transactions tracked by the watcher.", float64(s.Tracked))
+	metric("tron_mempool_seen_total", "counter", "Pending transactions seen by the watcher.", float64(s.Seen))
+	metric("tron_mempool_included_total", "counter", "Pending transactions included in a block.", float64(s.Included))
+	metric("tron_mempool_dropped_total", "counter", "Pending transactions that left the pool without being included.", float64(s.Dropped))
+	return bw.Flush()
+}
@@ -0,0 +1,192 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeMempoolClient serves a pending pool and the receipts of the transactions that left it.
+type fakeMempoolClient struct {
+	TronClient
+	pending  map[string]*core.Transaction
+	included map[string]int64
+	offline  bool
+}
+
+func (f *fakeMempoolClient) GetTransactionListFromPending() (*api.TransactionIdList, error) {
+	list := new(api.TransactionIdList)
+	for id := range f.pending {
+		list.TxId = append(list.TxId, id)
+	}
+	sort.Strings(list.TxId)
+	return list, nil
+}
+
+func (f *fakeMempoolClient) GetTransactionFromPending(id string) (*core.Transaction, error) {
+	if tx, ok := f.pending[id]; ok {
+		return tx, nil
+	}
+	return &core.Transaction{}, nil
+}
+
+func (f *fakeMempoolClient) GetPendingSize() (*api.NumberMessage, error) {
+	return &api.NumberMessage{Num: int64(len(f.pending))}, nil
+}
+
+func (f *fakeMempoolClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	if f.offline {
+		return nil, fmt.Errorf("GetTransactionInfoByID RPC error: unavailable")
+	}
+	if num, ok := f.included[id]; ok {
+		txid, _ := hex.DecodeString(id)
+		return &core.TransactionInfo{Id: txid, BlockNumber: num}, nil
+	}
+	return nil, fmt.Errorf("GetTransactionInfoByID: %w", ErrTransactionInfoNotFound)
+}
+
+// pendingTransfer returns a TRX transfer and its ID.
+func pendingTransfer(t *testing.T, amount int64) (string, *core.Transaction) {
+	owner, _ := base58.DecodeCheck("TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL")
+	to, _ := base58.DecodeCheck("TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9")
+	transfer, err := anypb.New(&core.TransferContract{OwnerAddress: owner, ToAddress: to, Amount: amount})
+	require.Nil(t, err)
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Timestamp: 1_700_000_000_000 + amount,
+		Contract:  []*core.Transaction_Contract{{Type: core.Transaction_Contract_TransferContract, Parameter: transfer}},
+	}}
+	txid, err := transactionID(tx.RawData)
+	require.Nil(t, err)
+	return hex.EncodeToString(txid), tx
+}
+
+func TestMempoolWatcher(t *testing.T) {
+	a, txA := pendingTransfer(t, 1)
+	b, txB := pendingTransfer(t, 2)
+	c, txC := pendingTransfer(t, 3)
+	client := &fakeMempoolClient{
+		pending:  map[string]*core.Transaction{a: txA, b: txB},
+		included: make(map[string]int64),
+	}
+	w := NewMempoolWatcher(client, NewTransactionDecoder(nil))
+	start := time.Unix(1_700_000_000, 0)
+	now := start
+	w.now = func() time.Time { return now }
+
+	update, err := w.Poll()
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), update.Size)
+	require.Len(t, update.Added, 2)
+	assert.Empty(t, update.Removed)
+	assert.Equal(t, a, update.Added[0].TxID)
+	require.NotNil(t, update.Added[0].Decoded)
+	assert.Equal(t, int64(1), update.Added[0].Decoded.Contracts[0].Value.(*TransferValue).Amount)
+
+	// a is included, b leaves without a receipt and c arrives.
+	now = start.Add(6 * time.Second)
+	client.included[a] = 42
+	client.pending = map[string]*core.Transaction{c: txC}
+	update, err = w.Poll()
+	require.Nil(t, err)
+	require.Len(t, update.Added, 1)
+	assert.Equal(t, c, update.Added[0].TxID)
+	assert.Equal(t, []RemovedTransaction{
+		{TxID: a, Included: true, BlockNumber: 42, Pending: 6 * time.Second},
+	}, update.Removed)
+
+	// Receipts that cannot be queried are checked again at the next poll.
+	client.pending = map[string]*core.Transaction{}
+	client.offline = true
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, update.Removed)
+	require.Len(t, w.Pending(), 2)
+
+	client.offline = false
+	client.included[c] = 43
+	update, err = w.Poll()
+	require.Nil(t, err)
+	require.Len(t, update.Removed, 1)
+	assert.Equal(t, c, update.Removed[0].TxID)
+	assert.True(t, update.Removed[0].Included)
+
+	// b is dropped once the receipt grace has passed.
+	now = start.Add(6*time.Second + DefaultReceiptGrace)
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Equal(t, []RemovedTransaction{{TxID: b, Pending: 6 * time.Second}}, update.Removed)
+	assert.Empty(t, w.Pending())
+
+	stats := w.Stats()
+	assert.Equal(t, MempoolStats{PolledAt: now, Seen: 3, Included: 2, Dropped: 1}, stats)
+
+	var buf bytes.Buffer
+	require.Nil(t, stats.WritePrometheus(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "tron_mempool_size 0\n")
+	assert.Contains(t, out, "# TYPE tron_mempool_included_total counter\ntron_mempool_included_total 2\n")
+	assert.Contains(t, out, "tron_mempool_dropped_total 1\n")
+	assert.Contains(t, out, "tron_mempool_last_poll_timestamp_seconds 1700000066\n")
+}
+
+func TestMempoolWatcherLateReceipt(t *testing.T) {
+	a, txA := pendingTransfer(t, 1)
+	b, txB := pendingTransfer(t, 2)
+	client := &fakeMempoolClient{
+		pending:  map[string]*core.Transaction{a: txA, b: txB},
+		included: make(map[string]int64),
+	}
+	w := NewMempoolWatcher(client, nil)
+	start := time.Unix(1_700_000_000, 0)
+	now := start
+	w.now = func() time.Time { return now }
+	_, err := w.Poll()
+	require.Nil(t, err)
+
+	// Both leave the pool before the solidity node serves their receipt.
+	now = start.Add(3 * time.Second)
+	client.pending = map[string]*core.Transaction{}
+	update, err := w.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, update.Removed)
+	assert.Equal(t, 2, w.Stats().Tracked)
+
+	// The receipt of a appears one poll later.
+	now = start.Add(6 * time.Second)
+	client.included[a] = 42
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Equal(t, []RemovedTransaction{{TxID: a, Included: true, BlockNumber: 42, Pending: 3 * time.Second}}, update.Removed)
+
+	// b comes back to the pool, and leaves it again.
+	client.pending = map[string]*core.Transaction{b: txB}
+	now = start.Add(time.Minute)
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, update.Removed)
+	assert.Empty(t, update.Added)
+
+	client.pending = map[string]*core.Transaction{}
+	now = start.Add(time.Minute + 3*time.Second)
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Empty(t, update.Removed)
+
+	// A shorter grace for a client reading the full node.
+	w.SetReceiptGrace(3 * time.Second)
+	now = start.Add(time.Minute + 6*time.Second)
+	update, err = w.Poll()
+	require.Nil(t, err)
+	assert.Equal(t, []RemovedTransaction{{TxID: b, Pending: time.Minute + 3*time.Second}}, update.Removed)
+	assert.Equal(t, MempoolStats{PolledAt: now, Seen: 2, Included: 1, Dropped: 1}, w.Stats())
+}
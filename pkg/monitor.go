@@ -0,0 +1,246 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+)
+
+// WitnessHealth holds the block production counters of a witness.
+type WitnessHealth struct {
+	Address  string
+	URL      string
+	Active   bool
+	Produced int64
+	Missed   int64
+	// MissedSinceLast is the number of blocks missed since the previous collection.
+	MissedSinceLast int64
+}
+
+// NodeHealth is a snapshot of a node's state and the health signals derived from it.
+type NodeHealth struct {
+	CollectedAt time.Time
+	Version     string
+
+	HeadBlockNum  int64
+	SolidBlockNum int64
+	// HasReference is set when a reference node is configured, and ReferenceUp
+	// when it answered; the reference head and HeadLag are only set then.
+	HasReference bool
+	ReferenceUp  bool
+	// ReferenceHeadBlockNum is the head of the reference node.
+	ReferenceHeadBlockNum int64
+	// HeadLag is the number of blocks the node is behind the reference node.
+	HeadLag int64
+
+	PeerCount    int32
+	ActivePeers  int32
+	PassivePeers int32
+
+	// Stats are only set when the node exposes its metrics.
+	HasStats      bool
+	TPS           float64
+	ForkCount     int32
+	FailForkCount int32
+
+	Witnesses []WitnessHealth
+}
+
+// SolidityLag returns the number of blocks between the head and the solidified block.
+func (h *NodeHealth) SolidityLag() int64 {
+	return h.HeadBlockNum - h.SolidBlockNum
+}
+
+// NodeMonitor collects health information from a node, optionally comparing its
+// head block with a reference node.
+type NodeMonitor struct {
+	client    TronClient
+	reference TronClient
+	now       func() time.Time
+
+	mu         sync.Mutex
+	lastMissed map[string]int64
+}
+
+// NewNodeMonitor creates a monitor for the client. The reference client may be nil.
+func NewNodeMonitor(client, reference TronClient) *NodeMonitor {
+	return &NodeMonitor{
+		client:     client,
+		reference:  reference,
+		now:        time.Now,
+		lastMissed: make(map[string]int64),
+	}
+}
+
+// Collect fetches the node information, metrics and witness list and derives the health signals.
+// Metrics are optional: when the node does not expose them, HasStats is false. A reference
+// node that does not answer is reported with ReferenceUp false and is not an error.
+func (m *NodeMonitor) Collect() (*NodeHealth, error) {
+	info, err := m.client.GetNodeInfo()
+	if err != nil {
+		return nil, fmt.Errorf("Collect: %w", err)
+	}
+
+	health := &NodeHealth{
+		CollectedAt:   m.now(),
+		Version:       info.GetConfigNodeInfo().GetCodeVersion(),
+		HeadBlockNum:  parseNodeBlockNum(info.GetBlock()),
+		SolidBlockNum: parseNodeBlockNum(info.GetSolidityBlock()),
+		PeerCount:     info.GetCurrentConnectCount(),
+		ActivePeers:   info.GetActiveConnectCount(),
+		PassivePeers:  info.GetPassiveConnectCount(),
+	}
+
+	if stats, err := m.client.GetStatsInfo(); err == nil {
+		chain := stats.GetBlockchain()
+		health.HasStats = true
+		health.TPS = chain.GetTps().GetOneMinuteRate()
+		health.ForkCount = chain.GetForkCount()
+		health.FailForkCount = chain.GetFailForkCount()
+		if chain.GetHeadBlockNum() > health.HeadBlockNum {
+			health.HeadBlockNum = chain.GetHeadBlockNum()
+		}
+	}
+
+	if m.reference != nil {
+		health.HasReference = true
+		if block, err := m.reference.GetNowBlock(); err == nil {
+			health.ReferenceUp = true
+			health.ReferenceHeadBlockNum = block.GetBlockHeader().GetRawData().GetNumber()
+			health.HeadLag = health.ReferenceHeadBlockNum - health.HeadBlockNum
+		}
+	}
+
+	witnesses, err := m.client.ListWitnesses()
+	if err != nil {
+		return nil, fmt.Errorf("Collect: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range witnesses.GetWitnesses() {
+		addr := base58.EncodeCheck(w.GetAddress())
+		wh := WitnessHealth{
+			Address:  addr,
+			URL:      w.GetUrl(),
+			Active:   w.GetIsJobs(),
+			Produced: w.GetTotalProduced(),
+			Missed:   w.GetTotalMissed(),
+		}
+		if last, ok := m.lastMissed[addr]; ok {
+			wh.MissedSinceLast = wh.Missed - last
+		}
+		m.lastMissed[addr] = wh.Missed
+		health.Witnesses = append(health.Witnesses, wh)
+	}
+	sort.Slice(health.Witnesses, func(i, j int) bool {
+		return health.Witnesses[i].Address < health.Witnesses[j].Address
+	})
+	return health, nil
+}
+
+// ServeHTTP collects the node health and writes it in Prometheus exposition format.
+func (m *NodeMonitor) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	health, err := m.Collect()
+	if err != nil {
+		fmt.Fprintf(w, "# HELP tron_node_up Whether the node answered the last health collection.\n")
+		fmt.Fprintf(w, "# TYPE tron_node_up gauge\ntron_node_up 0\n")
+		return
+	}
+	_ = health.WritePrometheus(w)
+}
+
+// WritePrometheus writes the health snapshot in Prometheus text exposition format.
+func (h *NodeHealth) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatFloat(value))
+	}
+
+	gauge("tron_node_up", "Whether the node answered the last health collection.", 1)
+	fmt.Fprintf(bw, "# HELP tron_node_info Node version information.\n# TYPE tron_node_info gauge\n")
+	fmt.Fprintf(bw, "tron_node_info{version=\"%s\"} 1\n", escapeLabel(h.Version))
+	gauge("tron_node_head_block", "Head block number of the node.", float64(h.HeadBlockNum))
+	gauge("tron_node_solid_block", "Last solidified block number of the node.", float64(h.SolidBlockNum))
+	gauge("tron_node_solidity_lag_blocks", "Blocks between the head and the solidified block.", float64(h.SolidityLag()))
+	if h.HasReference {
+		gauge("tron_reference_up", "Whether the reference node answered the last health collection.", boolFloat(h.ReferenceUp))
+	}
+	if h.ReferenceUp {
+		gauge("tron_node_reference_head_block", "Head block number of the reference node.", float64(h.ReferenceHeadBlockNum))
+		gauge("tron_node_head_lag_blocks", "Blocks the node is behind the reference node.", float64(h.HeadLag))
+	}
+	gauge("tron_node_peers", "Number of connected peers.", float64(h.PeerCount))
+	gauge("tron_node_peers_active", "Number of active peer connections.", float64(h.ActivePeers))
+	gauge("tron_node_peers_passive", "Number of passive peer connections.", float64(h.PassivePeers))
+	if h.HasStats {
+		gauge("tron_node_tps", "Transactions per second over the last minute.", h.TPS)
+		gauge("tron_node_forks", "Number of forks seen by the node.", float64(h.ForkCount))
+		gauge("tron_node_failed_forks", "Number of forks the node failed to switch to.", float64(h.FailForkCount))
+	}
+
+	if len(h.Witnesses) > 0 {
+		fmt.Fprintf(bw, "# HELP tron_witness_produced_blocks_total Blocks produced by the witness.\n# TYPE tron_witness_produced_blocks_total counter\n")
+		for _, wh := range h.Witnesses {
+			fmt.Fprintf(bw, "tron_witness_produced_blocks_total{witness=\"%s\",active=\"%t\"} %d\n", escapeLabel(wh.Address), wh.Active, wh.Produced)
+		}
+		fmt.Fprintf(bw, "# HELP tron_witness_missed_blocks_total Blocks missed by the witness.\n# TYPE tron_witness_missed_blocks_total counter\n")
+		for _, wh := range h.Witnesses {
+			fmt.Fprintf(bw, "tron_witness_missed_blocks_total{witness=\"%s\",active=\"%t\"} %d\n", escapeLabel(wh.Address), wh.Active, wh.Missed)
+		}
+		fmt.Fprintf(bw, "# HELP tron_witness_missed_blocks_recent Blocks missed by the witness since the previous collection.\n# TYPE tron_witness_missed_blocks_recent gauge\n")
+		for _, wh := range h.Witnesses {
+			fmt.Fprintf(bw, "tron_witness_missed_blocks_recent{witness=\"%s\",active=\"%t\"} %d\n", escapeLabel(wh.Address), wh.Active, wh.MissedSinceLast)
+		}
+	}
+	return bw.Flush()
+}
+
+// parseNodeBlockNum extracts the number from a node info block string such as "Num:123,ID:abc".
+func parseNodeBlockNum(s string) int64 {
+	for _, part := range strings.Split(s, ",") {
+		if num, ok := strings.CutPrefix(strings.TrimSpace(part), "Num:"); ok {
+			n, _ := strconv.ParseInt(num, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// labelEscaper escapes a label value as the Prometheus text format expects.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// formatFloat formats a metric value without exponent for integral values.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMonitorClient struct {
+	TronClient
+	head   int64
+	missed int64
+}
+
+func (f *fakeMonitorClient) GetNodeInfo() (*core.NodeInfo, error) {
+	return &core.NodeInfo{
+		Block:               fmt.Sprintf("Num:%d,ID:00000000%x", f.head, f.head),
+		SolidityBlock:       fmt.Sprintf("Num:%d,ID:00000000%x", f.head-19, f.head-19),
+		CurrentConnectCount: 30,
+		ConfigNodeInfo:      &core.NodeInfo_ConfigNodeInfo{CodeVersion: "4.7.4"},
+	}, nil
+}
+
+func (f *fakeMonitorClient) GetStatsInfo() (*core.MetricsInfo, error) {
+	return nil, fmt.Errorf("GetStatsInfo: metrics disabled")
+}
+
+func (f *fakeMonitorClient) GetNowBlock() (*api.BlockExtention, error) {
+	return &api.BlockExtention{BlockHeader: &core.BlockHeader{RawData: &core.BlockHeaderRaw{Number: f.head}}}, nil
+}
+
+func (f *fakeMonitorClient) ListWitnesses() (*api.WitnessList, error) {
+	addr, _ := base58.DecodeCheck("TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL")
+	return &api.WitnessList{Witnesses: []*core.Witness{
+		{Address: addr, IsJobs: true, TotalProduced: 1000, TotalMissed: f.missed},
+	}}, nil
+}
+
+func TestNodeMonitorCollect(t *testing.T) {
+	node := &fakeMonitorClient{head: 100, missed: 3}
+	m := NewNodeMonitor(node, &fakeMonitorClient{head: 105})
+
+	health, err := m.Collect()
+	require.Nil(t, err)
+	assert.Equal(t, int64(100), health.HeadBlockNum)
+	assert.Equal(t, int64(19), health.SolidityLag())
+	assert.Equal(t, int64(5), health.HeadLag)
+	assert.False(t, health.HasStats)
+
+	node.missed = 5
+	health, err = m.Collect()
+	require.Nil(t, err)
+	require.Len(t, health.Witnesses, 1)
+	assert.Equal(t, int64(2), health.Witnesses[0].MissedSinceLast)
+
+	var buf bytes.Buffer
+	require.Nil(t, health.WritePrometheus(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "tron_node_head_lag_blocks 5\n")
+	assert.Contains(t, out, "tron_node_peers 30\n")
+	assert.Contains(t, out, `tron_node_info{version="4.7.4"} 1`)
+	assert.Contains(t, out, `tron_witness_missed_blocks_total{witness="TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL",active="true"} 5`)
+	assert.NotContains(t, out, "tron_node_tps")
+	assert.Contains(t, out, "tron_reference_up 1\n")
+	assert.Contains(t, out, `tron_witness_missed_blocks_recent{witness="TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL",active="true"} 2`)
+}
+
+// fakeDownClient is a reference node that does not answer.
+type fakeDownClient struct {
+	TronClient
+}
+
+func (f *fakeDownClient) GetNowBlock() (*api.BlockExtention, error) {
+	return nil, fmt.Errorf("GetNowBlock: connection refused")
+}
+
+func TestNodeMonitorReferenceDown(t *testing.T) {
+	m := NewNodeMonitor(&fakeMonitorClient{head: 100}, &fakeDownClient{})
+	health, err := m.Collect()
+	require.Nil(t, err)
+	assert.True(t, health.HasReference)
+	assert.False(t, health.ReferenceUp)
+	assert.Zero(t, health.HeadLag)
+
+	var buf bytes.Buffer
+	require.Nil(t, health.WritePrometheus(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "tron_node_up 1\n")
+	assert.Contains(t, out, "tron_reference_up 0\n")
+	assert.NotContains(t, out, "tron_node_head_lag_blocks")
+	assert.Contains(t, out, "tron_node_head_block 100\n")
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `4.7\\4 \"beta\"\nnext`, escapeLabel("4.7\\4 \"beta\"\nnext"))
+	// Go quoting would escape non-ASCII characters and tabs, Prometheus keeps them.
+	assert.Equal(t, "ü\t", escapeLabel("ü\t"))
+}
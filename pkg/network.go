@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
 )
 
 // ListNodes queries the list of nodes connected to the API.
@@ -73,3 +74,27 @@ func (g *GrpcClient) GetMemoFee() (*api.PricesResponseMessage, error) {
 	}
 	return result, nil
 }
+
+// GetNodeInfo retrieves information about the connected node, its peers and machine.
+func (g *GrpcClient) GetNodeInfo() (*core.NodeInfo, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	result, err := g.Client.GetNodeInfo(ctx, new(api.EmptyMessage))
+	if err != nil {
+		return nil, fmt.Errorf("GetNodeInfo: %w", err)
+	}
+	return result, nil
+}
+
+// GetStatsInfo retrieves the node metrics. The node must run with metrics enabled.
+func (g *GrpcClient) GetStatsInfo() (*core.MetricsInfo, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	result, err := g.Monitor.GetStatsInfo(ctx, new(api.EmptyMessage))
+	if err != nil {
+		return nil, fmt.Errorf("GetStatsInfo: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,581 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// PacketType is the first byte of a discovery packet.
+type PacketType byte
+
+// Discovery packet types. Each UDP packet is the packet type followed by the
+// protobuf encoding of the message.
+const (
+	PacketPing      PacketType = 0x01 // core.PingMessage
+	PacketPong      PacketType = 0x02 // core.PongMessage
+	PacketFindNode  PacketType = 0x03 // core.FindNeighbours
+	PacketNeighbors PacketType = 0x04 // core.Neighbours
+)
+
+const (
+	// MaxNeighbors is the number of nodes a node returns to a find node request.
+	MaxNeighbors = 16
+	// maxPacketSize bounds the discovery packets read.
+	maxPacketSize = 2048
+)
+
+// ErrTimeout is returned when a node does not answer a discovery request in time.
+var ErrTimeout = errors.New("discovery request timed out")
+
+// Node is a node of the network, as known to the discovery table.
+type Node struct {
+	ID      []byte
+	Host    string // IPv4 address
+	Port    int    // peer and discovery port
+	Version int32  // protocol version reported by the node, 0 until it answers
+
+	Discovered time.Time     // when the node was first seen
+	LastPong   time.Time     // last answer to a ping
+	Latency    time.Duration // round trip of the last ping
+	Failures   int           // consecutive unanswered pings
+}
+
+// Addr returns the address of the node, for Dial and the discovery requests.
+func (n *Node) Addr() string {
+	return net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+}
+
+// Alive reports whether the node answered its last ping.
+func (n *Node) Alive() bool {
+	return !n.LastPong.IsZero() && n.Failures == 0
+}
+
+// DiscoveryConfig describes the local node announced during discovery.
+type DiscoveryConfig struct {
+	Version int32  // protocol version of the network, MainnetVersion when 0
+	NodeID  []byte // random when empty
+	// Host is the advertised IPv4 address. Nodes answer to the address packets
+	// come from, but drop packets without a valid address. 127.0.0.1 when empty.
+	Host string
+	Port int32 // advertised port, the local UDP port when 0
+
+	Bootnodes   []string      // host:port of the nodes the crawl starts from
+	Timeout     time.Duration // wait for each answer, 2 seconds when 0
+	MaxFailures int           // unanswered pings before a node is dropped, 3 when 0
+	MaxNodes    int           // size of the node table, 1000 when 0
+	Concurrency int           // nodes queried at once while crawling, 16 when 0
+}
+
+// Discovery crawls the network with the discovery protocol of java-tron and
+// keeps a table of the nodes found with their liveness. It answers the pings
+// and find node requests of other nodes.
+type Discovery struct {
+	conn net.PacketConn
+	cfg  DiscoveryConfig
+	self *core.Endpoint
+	now  func() time.Time
+
+	mu       sync.Mutex
+	nodes    map[string]*Node                    // by hex node ID
+	waiters  map[string][]*waiter                // by packet type and sender address
+	replies  map[PacketType]func() proto.Message // decoders of awaited answers
+	pings    map[string]*pingCall                // pings in flight by address
+	sequence int64                               // timestamp of the last find node request
+
+	done chan struct{}
+}
+
+// waiter is a request waiting for an answer accepted by match.
+type waiter struct {
+	ch    chan proto.Message
+	match func(proto.Message) bool
+}
+
+// pingCall is a ping in flight, shared by the concurrent pings of an address.
+type pingCall struct {
+	done chan struct{}
+	node *Node
+	err  error
+}
+
+// ListenDiscovery binds a UDP address, e.g. ":18888", and starts answering
+// discovery requests.
+func ListenDiscovery(addr string, cfg DiscoveryConfig) (*Discovery, error) {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewDiscovery(conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewDiscovery starts answering discovery requests on conn.
+func NewDiscovery(conn net.PacketConn, cfg DiscoveryConfig) (*Discovery, error) {
+	if cfg.Version == 0 {
+		cfg.Version = MainnetVersion
+	}
+	if cfg.Host == "" {
+		cfg.Host = "127.0.0.1"
+	}
+	if cfg.Port == 0 {
+		if udp, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+			cfg.Port = int32(udp.Port)
+		}
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.MaxFailures == 0 {
+		cfg.MaxFailures = 3
+	}
+	if cfg.MaxNodes == 0 {
+		cfg.MaxNodes = 1000
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = 16
+	}
+	if len(cfg.NodeID) == 0 {
+		cfg.NodeID = make([]byte, NodeIDLength)
+		if _, err := rand.Read(cfg.NodeID); err != nil {
+			return nil, err
+		}
+	}
+	if !validHost(cfg.Host) || len(cfg.NodeID) != NodeIDLength {
+		return nil, fmt.Errorf("NewDiscovery: invalid local node %s with ID of %d bytes", cfg.Host, len(cfg.NodeID))
+	}
+
+	d := &Discovery{
+		conn:    conn,
+		cfg:     cfg,
+		self:    &core.Endpoint{Address: []byte(cfg.Host), Port: cfg.Port, NodeId: cfg.NodeID},
+		now:     time.Now,
+		nodes:   make(map[string]*Node),
+		waiters: make(map[string][]*waiter),
+		pings:   make(map[string]*pingCall),
+		replies: map[PacketType]func() proto.Message{
+			PacketPong:      func() proto.Message { return new(core.PongMessage) },
+			PacketNeighbors: func() proto.Message { return new(core.Neighbours) },
+		},
+		done: make(chan struct{}),
+	}
+	go d.readLoop()
+	return d, nil
+}
+
+// Close stops the discovery service.
+func (d *Discovery) Close() error {
+	err := d.conn.Close()
+	<-d.done
+	return err
+}
+
+// Ping checks that the node at addr is alive and returns it. The node is added
+// to the table, or its liveness is updated; a node failing too many pings in a
+// row is dropped.
+//
+// Pongs do not echo the ping: they carry the protocol version and the clock of
+// the node. A pong is thus matched by its sender address and, for a known node,
+// its node ID, and one ping at a time is sent to an address: concurrent pings of
+// the same address share the answer.
+func (d *Discovery) Ping(ctx context.Context, addr string) (*Node, error) {
+	to, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	key := to.String()
+	d.mu.Lock()
+	call, ok := d.pings[key]
+	if !ok {
+		call = &pingCall{done: make(chan struct{})}
+		d.pings[key] = call
+	}
+	d.mu.Unlock()
+	if ok {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		c := *call.node
+		return &c, nil
+	}
+
+	call.node, call.err = d.ping(ctx, to)
+	d.mu.Lock()
+	delete(d.pings, key)
+	d.mu.Unlock()
+	close(call.done)
+	if call.err != nil {
+		return nil, call.err
+	}
+	c := *call.node
+	return &c, nil
+}
+
+// ping sends a ping to the node at to and updates the table with its pong.
+func (d *Discovery) ping(ctx context.Context, to *net.UDPAddr) (*Node, error) {
+	var known []byte
+	d.mu.Lock()
+	for _, n := range d.nodes {
+		if n.Host == to.IP.String() && n.Port == to.Port {
+			known = n.ID
+		}
+	}
+	d.mu.Unlock()
+	match := func(m proto.Message) bool {
+		id := m.(*core.PongMessage).GetFrom().GetNodeId()
+		if known != nil {
+			return bytes.Equal(id, known)
+		}
+		return len(id) == NodeIDLength
+	}
+
+	ping := &core.PingMessage{
+		From:      d.self,
+		To:        &core.Endpoint{Address: []byte(to.IP.String()), Port: int32(to.Port)},
+		Version:   d.cfg.Version,
+		Timestamp: d.now().UnixMilli(),
+	}
+	sent := d.now()
+	reply, err := d.request(ctx, to, PacketPing, ping, PacketPong, match)
+	if errors.Is(err, ErrTimeout) {
+		d.fail(to)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pong := reply.(*core.PongMessage)
+	from := pong.GetFrom()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := d.add(from.GetNodeId(), to.IP.String(), to.Port)
+	if n == nil {
+		return nil, fmt.Errorf("Ping: the node table is full")
+	}
+	n.Version = pong.GetEcho()
+	n.LastPong = d.now()
+	n.Latency = n.LastPong.Sub(sent)
+	n.Failures = 0
+	c := *n
+	return &c, nil
+}
+
+// FindNodes asks the node at addr for the nodes closest to target, a node ID,
+// and adds them to the table. The returned nodes are not pinged yet.
+func (d *Discovery) FindNodes(ctx context.Context, addr string, target []byte) ([]*Node, error) {
+	to, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	// Nodes answer with the timestamp of the request, which identifies it.
+	d.mu.Lock()
+	d.sequence = max(d.sequence+1, d.now().UnixMilli())
+	find := &core.FindNeighbours{From: d.self, TargetId: target, Timestamp: d.sequence}
+	d.mu.Unlock()
+	match := func(m proto.Message) bool {
+		return m.(*core.Neighbours).GetTimestamp() == find.Timestamp
+	}
+	reply, err := d.request(ctx, to, PacketFindNode, find, PacketNeighbors, match)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var found []*Node
+	for _, e := range reply.(*core.Neighbours).GetNeighbours() {
+		host := string(e.GetAddress())
+		if !validHost(host) || len(e.GetNodeId()) != NodeIDLength || string(e.GetNodeId()) == string(d.cfg.NodeID) {
+			continue
+		}
+		if n := d.add(e.GetNodeId(), host, int(e.GetPort())); n != nil {
+			c := *n
+			found = append(found, &c)
+		}
+	}
+	return found, nil
+}
+
+// Crawl pings the bootnodes and the known nodes, asks each live node for its
+// neighbors, and continues with the nodes found until no new node answers or
+// the context is done.
+func (d *Discovery) Crawl(ctx context.Context) error {
+	seen := make(map[string]bool)
+	var queue []string
+	enqueue := func(addr string) {
+		if !seen[addr] {
+			seen[addr] = true
+			queue = append(queue, addr)
+		}
+	}
+	for _, addr := range d.cfg.Bootnodes {
+		enqueue(addr)
+	}
+	for _, n := range d.Nodes() {
+		enqueue(n.Addr())
+	}
+
+	for len(queue) > 0 {
+		batch := queue
+		queue = nil
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, d.cfg.Concurrency)
+		for _, addr := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(addr string) {
+				defer func() { <-sem; wg.Done() }()
+				if _, err := d.Ping(ctx, addr); err != nil {
+					return
+				}
+				target := make([]byte, NodeIDLength)
+				_, _ = rand.Read(target)
+				found, err := d.FindNodes(ctx, addr, target)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				for _, n := range found {
+					enqueue(n.Addr())
+				}
+				mu.Unlock()
+			}(addr)
+		}
+		wg.Wait()
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh pings every node of the table to update its liveness.
+func (d *Discovery) Refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, d.cfg.Concurrency)
+	for _, n := range d.Nodes() {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(addr string) {
+			defer func() { <-sem; wg.Done() }()
+			_, _ = d.Ping(ctx, addr)
+		}(n.Addr())
+	}
+	wg.Wait()
+}
+
+// Nodes returns a copy of the node table, by discovery time.
+func (d *Discovery) Nodes() []*Node {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	nodes := make([]*Node, 0, len(d.nodes))
+	for _, n := range d.nodes {
+		c := *n
+		nodes = append(nodes, &c)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if !nodes[i].Discovered.Equal(nodes[j].Discovered) {
+			return nodes[i].Discovered.Before(nodes[j].Discovered)
+		}
+		return nodes[i].Addr() < nodes[j].Addr()
+	})
+	return nodes
+}
+
+// Healthy returns up to limit live nodes of the network, by latency. A limit of
+// 0 returns all of them.
+func (d *Discovery) Healthy(limit int) []*Node {
+	var healthy []*Node
+	for _, n := range d.Nodes() {
+		if n.Alive() && n.Version == d.cfg.Version {
+			healthy = append(healthy, n)
+		}
+	}
+	sort.SliceStable(healthy, func(i, j int) bool { return healthy[i].Latency < healthy[j].Latency })
+	if limit > 0 && len(healthy) > limit {
+		healthy = healthy[:limit]
+	}
+	return healthy
+}
+
+// request sends a packet and waits for an answer of the given type from the
+// same address accepted by match. Each answer is passed to one request.
+func (d *Discovery) request(ctx context.Context, to *net.UDPAddr, t PacketType, m proto.Message, reply PacketType, match func(proto.Message) bool) (proto.Message, error) {
+	key := waiterKey(reply, to)
+	wait := &waiter{ch: make(chan proto.Message, 1), match: match}
+	d.mu.Lock()
+	d.waiters[key] = append(d.waiters[key], wait)
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		waiters := d.waiters[key]
+		for i, w := range waiters {
+			if w == wait {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(d.waiters, key)
+		} else {
+			d.waiters[key] = waiters
+		}
+	}()
+
+	if err := d.send(to, t, m); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(d.cfg.Timeout)
+	defer timer.Stop()
+	select {
+	case msg := <-wait.ch:
+		return msg, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("%s: %w", to, ErrTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-d.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (d *Discovery) send(to net.Addr, t PacketType, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = d.conn.WriteTo(append([]byte{byte(t)}, data...), to)
+	return err
+}
+
+// readLoop answers requests and passes answers to the waiting requests until
+// the connection is closed. Malformed packets are dropped.
+func (d *Discovery) readLoop() {
+	defer close(d.done)
+	buf := make([]byte, maxPacketSize)
+	for {
+		size, from, err := d.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		udp, ok := from.(*net.UDPAddr)
+		if !ok || size < 2 {
+			continue
+		}
+		t, data := PacketType(buf[0]), buf[1:size]
+
+		switch t {
+		case PacketPing:
+			ping := new(core.PingMessage)
+			if proto.Unmarshal(data, ping) != nil || len(ping.GetFrom().GetNodeId()) != NodeIDLength {
+				continue
+			}
+			_ = d.send(udp, PacketPong, &core.PongMessage{From: d.self, Echo: d.cfg.Version, Timestamp: d.now().UnixMilli()})
+
+		case PacketFindNode:
+			find := new(core.FindNeighbours)
+			if proto.Unmarshal(data, find) != nil || len(find.GetFrom().GetNodeId()) != NodeIDLength {
+				continue
+			}
+			reply := &core.Neighbours{From: d.self, Timestamp: find.GetTimestamp()}
+			for _, n := range d.Healthy(MaxNeighbors) {
+				reply.Neighbours = append(reply.Neighbours, &core.Endpoint{Address: []byte(n.Host), Port: int32(n.Port), NodeId: n.ID})
+			}
+			_ = d.send(udp, PacketNeighbors, reply)
+
+		case PacketPong, PacketNeighbors:
+			msg := d.replies[t]()
+			if proto.Unmarshal(data, msg) != nil {
+				continue
+			}
+			key := waiterKey(t, udp)
+			d.mu.Lock()
+		deliver:
+			for _, w := range d.waiters[key] {
+				if !w.match(msg) {
+					continue
+				}
+				select {
+				case w.ch <- msg:
+					break deliver
+				default:
+				}
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// add returns the node with the given ID, adding it to the table when there
+// is room. The caller holds the lock.
+func (d *Discovery) add(id []byte, host string, port int) *Node {
+	key := hex.EncodeToString(id)
+	n, ok := d.nodes[key]
+	if !ok {
+		if len(d.nodes) >= d.cfg.MaxNodes {
+			return nil
+		}
+		n = &Node{ID: append([]byte(nil), id...), Discovered: d.now()}
+		d.nodes[key] = n
+	}
+	n.Host, n.Port = host, port
+	return n
+}
+
+// fail records an unanswered ping of the node at addr, dropping it after too
+// many in a row.
+func (d *Discovery) fail(addr *net.UDPAddr) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, n := range d.nodes {
+		if n.Host == addr.IP.String() && n.Port == addr.Port {
+			if n.Failures++; n.Failures >= d.cfg.MaxFailures {
+				delete(d.nodes, key)
+			}
+		}
+	}
+}
+
+func waiterKey(t PacketType, addr *net.UDPAddr) string {
+	return strconv.Itoa(int(t)) + "/" + addr.String()
+}
+
+// validHost reports whether host is an IPv4 address nodes accept in endpoints.
+func validHost(host string) bool {
+	ip := net.ParseIP(host).To4()
+	return ip != nil && ip[0] != 0
+}
@@ -0,0 +1,250 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeDiscoveryNode is a java-tron node answering discovery requests over UDP.
+type fakeDiscoveryNode struct {
+	conn      net.PacketConn
+	id        []byte
+	version   int32
+	neighbors []*fakeDiscoveryNode
+}
+
+func newFakeDiscoveryNode(t *testing.T, id byte, version int32) *fakeDiscoveryNode {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.Nil(t, err)
+	n := &fakeDiscoveryNode{conn: conn, id: bytes.Repeat([]byte{id}, NodeIDLength), version: version}
+	t.Cleanup(func() { conn.Close() })
+	return n
+}
+
+func (n *fakeDiscoveryNode) addr() string {
+	return n.conn.LocalAddr().String()
+}
+
+func (n *fakeDiscoveryNode) endpoint() *core.Endpoint {
+	port := n.conn.LocalAddr().(*net.UDPAddr).Port
+	return &core.Endpoint{Address: []byte("127.0.0.1"), Port: int32(port), NodeId: n.id}
+}
+
+// serve answers requests until the node is closed.
+func (n *fakeDiscoveryNode) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		size, from, err := n.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var reply proto.Message
+		var t PacketType
+		switch PacketType(buf[0]) {
+		case PacketPing:
+			t, reply = PacketPong, &core.PongMessage{From: n.endpoint(), Echo: n.version}
+		case PacketFindNode:
+			find := new(core.FindNeighbours)
+			if proto.Unmarshal(buf[1:size], find) != nil || len(find.GetTargetId()) != NodeIDLength {
+				continue
+			}
+			neighbors := &core.Neighbours{From: n.endpoint(), Timestamp: find.GetTimestamp()}
+			for _, neighbor := range n.neighbors {
+				neighbors.Neighbours = append(neighbors.Neighbours, neighbor.endpoint())
+			}
+			t, reply = PacketNeighbors, neighbors
+		default:
+			continue
+		}
+		data, _ := proto.Marshal(reply)
+		_, _ = n.conn.WriteTo(append([]byte{byte(t)}, data...), from)
+	}
+}
+
+func TestDiscoveryCrawl(t *testing.T) {
+	boot := newFakeDiscoveryNode(t, 1, MainnetVersion)
+	near := newFakeDiscoveryNode(t, 2, MainnetVersion)
+	far := newFakeDiscoveryNode(t, 3, MainnetVersion)
+	dead := newFakeDiscoveryNode(t, 4, MainnetVersion)
+	testnet := newFakeDiscoveryNode(t, 5, NileVersion)
+	boot.neighbors = []*fakeDiscoveryNode{near, dead}
+	near.neighbors = []*fakeDiscoveryNode{boot, far, testnet}
+	for _, n := range []*fakeDiscoveryNode{boot, near, far, testnet} {
+		go n.serve()
+	}
+	dead.conn.Close()
+
+	d, err := ListenDiscovery("127.0.0.1:0", DiscoveryConfig{
+		Bootnodes: []string{boot.addr()},
+		Timeout:   200 * time.Millisecond,
+	})
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Nil(t, d.Crawl(ctx))
+
+	nodes := d.Nodes()
+	require.Len(t, nodes, 5)
+	assert.Equal(t, boot.id, nodes[0].ID)
+	byID := make(map[byte]*Node)
+	for _, n := range nodes {
+		byID[n.ID[0]] = n
+	}
+	assert.True(t, byID[3].Alive())
+	assert.False(t, byID[4].Alive())
+	assert.Equal(t, 1, byID[4].Failures)
+	assert.Equal(t, NileVersion, byID[5].Version)
+
+	healthy := d.Healthy(0)
+	require.Len(t, healthy, 3)
+	for _, n := range healthy {
+		assert.NotEqual(t, byte(4), n.ID[0])
+		assert.NotEqual(t, byte(5), n.ID[0])
+	}
+	assert.Len(t, d.Healthy(2), 2)
+
+	// A node stops answering.
+	near.conn.Close()
+	d.Refresh(ctx)
+	assert.Len(t, d.Healthy(0), 2)
+	d.Refresh(ctx)
+	d.Refresh(ctx)
+	assert.Len(t, d.Nodes(), 3, "nodes failing 3 pings are dropped")
+}
+
+func TestDiscoveryAnswers(t *testing.T) {
+	peer := newFakeDiscoveryNode(t, 1, MainnetVersion)
+	go peer.serve()
+	d, err := ListenDiscovery("127.0.0.1:0", DiscoveryConfig{Timeout: 200 * time.Millisecond})
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	n, err := d.Ping(ctx, peer.addr())
+	require.Nil(t, err)
+	assert.Equal(t, peer.id, n.ID)
+	assert.Equal(t, MainnetVersion, n.Version)
+	assert.Equal(t, peer.addr(), n.Addr())
+
+	// A node of the network pings and queries the service.
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer conn.Close()
+	self := &core.Endpoint{Address: []byte("127.0.0.1"), Port: 18888, NodeId: bytes.Repeat([]byte{9}, NodeIDLength)}
+	exchange := func(typ PacketType, m proto.Message, reply proto.Message) PacketType {
+		data, _ := proto.Marshal(m)
+		_, err := conn.WriteTo(append([]byte{byte(typ)}, data...), d.conn.LocalAddr())
+		require.Nil(t, err)
+		require.Nil(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		buf := make([]byte, maxPacketSize)
+		size, _, err := conn.ReadFrom(buf)
+		require.Nil(t, err)
+		require.Nil(t, proto.Unmarshal(buf[1:size], reply))
+		return PacketType(buf[0])
+	}
+
+	pong := new(core.PongMessage)
+	assert.Equal(t, PacketPong, exchange(PacketPing, &core.PingMessage{From: self, Version: MainnetVersion}, pong))
+	assert.Equal(t, MainnetVersion, pong.GetEcho())
+	assert.Len(t, pong.GetFrom().GetNodeId(), NodeIDLength)
+	port := d.conn.LocalAddr().(*net.UDPAddr).Port
+	assert.Equal(t, int32(port), pong.GetFrom().GetPort())
+
+	neighbors := new(core.Neighbours)
+	assert.Equal(t, PacketNeighbors, exchange(PacketFindNode, &core.FindNeighbours{From: self, TargetId: self.NodeId, Timestamp: 42}, neighbors))
+	assert.Equal(t, int64(42), neighbors.GetTimestamp())
+	require.Len(t, neighbors.GetNeighbours(), 1)
+	assert.Equal(t, peer.id, neighbors.GetNeighbours()[0].GetNodeId())
+
+	_, err = d.Ping(ctx, "127.0.0.1:1")
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestDiscoveryMatchesAnswers(t *testing.T) {
+	node := newFakeDiscoveryNode(t, 1, MainnetVersion)
+	other := newFakeDiscoveryNode(t, 2, MainnetVersion)
+	d, err := ListenDiscovery("127.0.0.1:0", DiscoveryConfig{Timeout: time.Second})
+	require.Nil(t, err)
+	defer d.Close()
+
+	// receive reads the next request sent to the node, or returns 0 after wait.
+	receive := func(wait time.Duration, m proto.Message) PacketType {
+		require.Nil(t, node.conn.SetReadDeadline(time.Now().Add(wait)))
+		buf := make([]byte, maxPacketSize)
+		size, _, err := node.conn.ReadFrom(buf)
+		if err != nil {
+			return 0
+		}
+		if m != nil {
+			require.Nil(t, proto.Unmarshal(buf[1:size], m))
+		}
+		return PacketType(buf[0])
+	}
+	reply := func(typ PacketType, m proto.Message) {
+		data, _ := proto.Marshal(m)
+		_, err := node.conn.WriteTo(append([]byte{byte(typ)}, data...), d.conn.LocalAddr())
+		require.Nil(t, err)
+	}
+	ctx := context.Background()
+
+	// Concurrent pings of a node send one ping and share its pong.
+	type result struct {
+		node *Node
+		err  error
+	}
+	results := make(chan result, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			n, err := d.Ping(ctx, node.addr())
+			results <- result{n, err}
+		}()
+	}
+	require.Equal(t, PacketPing, receive(time.Second, nil))
+	assert.Zero(t, receive(100*time.Millisecond, nil))
+	reply(PacketPong, &core.PongMessage{From: node.endpoint(), Echo: MainnetVersion})
+	for i := 0; i < 2; i++ {
+		r := <-results
+		require.Nil(t, r.err)
+		assert.Equal(t, node.id, r.node.ID)
+	}
+
+	// A pong of another node ID from the address of a known node is ignored.
+	go func() {
+		n, err := d.Ping(ctx, node.addr())
+		results <- result{n, err}
+	}()
+	require.Equal(t, PacketPing, receive(time.Second, nil))
+	reply(PacketPong, &core.PongMessage{From: other.endpoint(), Echo: NileVersion})
+	time.Sleep(50 * time.Millisecond)
+	reply(PacketPong, &core.PongMessage{From: node.endpoint(), Echo: MainnetVersion})
+	r := <-results
+	require.Nil(t, r.err)
+	assert.Equal(t, node.id, r.node.ID)
+	assert.Equal(t, MainnetVersion, r.node.Version)
+
+	// Neighbors answer the find node request with the same timestamp.
+	found := make(chan []*Node, 1)
+	go func() {
+		nodes, err := d.FindNodes(ctx, node.addr(), node.id)
+		assert.Nil(t, err)
+		found <- nodes
+	}()
+	find := new(core.FindNeighbours)
+	require.Equal(t, PacketFindNode, receive(time.Second, find))
+	stale := &core.Endpoint{Address: []byte("10.0.0.1"), Port: DefaultPort, NodeId: bytes.Repeat([]byte{3}, NodeIDLength)}
+	reply(PacketNeighbors, &core.Neighbours{From: node.endpoint(), Neighbours: []*core.Endpoint{stale}, Timestamp: find.GetTimestamp() - 1})
+	reply(PacketNeighbors, &core.Neighbours{From: node.endpoint(), Neighbours: []*core.Endpoint{other.endpoint()}, Timestamp: find.GetTimestamp()})
+	nodes := <-found
+	require.Len(t, nodes, 1)
+	assert.Equal(t, other.id, nodes[0].ID)
+}
@@ -0,0 +1,230 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Message types of the libp2p layer of java-tron 4.7 and later. The messages of
+// the TRON protocol keep the types below 0x80.
+const (
+	MsgKeepAlivePing MessageType = 0xff // KeepAliveMessage
+	MsgKeepAlivePong MessageType = 0xfe // KeepAliveMessage
+	MsgP2PHello      MessageType = 0xfd // HelloMessage of libp2p
+	MsgP2PStatus     MessageType = 0xfc // StatusMessage
+	MsgP2PDisconnect MessageType = 0xfb // P2pDisconnectMessage
+)
+
+// Protocol selects the connection layer spoken with a node.
+type Protocol int
+
+const (
+	// ProtocolAuto speaks libp2p, and Dial connects again with the legacy
+	// protocol when the node does not answer the libp2p handshake.
+	ProtocolAuto Protocol = iota
+	// ProtocolLibp2p is the protocol of java-tron 4.7 and later: the libp2p
+	// handshake, then the TRON messages in compressed frames.
+	ProtocolLibp2p
+	// ProtocolLegacy is the protocol of java-tron before 4.7, where the TRON
+	// hello message opens the connection.
+	ProtocolLegacy
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolAuto:
+		return "auto"
+	case ProtocolLibp2p:
+		return "libp2p"
+	case ProtocolLegacy:
+		return "legacy"
+	}
+	return fmt.Sprintf("Protocol(%d)", int(p))
+}
+
+const (
+	// libp2pVersion is the version of the libp2p layer sent in its hello message.
+	libp2pVersion = 1
+	// libp2pNormal is the code of a hello message accepting the connection.
+	libp2pNormal = 0
+	// libp2pDifferentVersion is the disconnect reason for another network.
+	libp2pDifferentVersion = 4
+)
+
+// compression types of CompressMessage.
+const (
+	uncompressed     = 0
+	snappyCompressed = 1
+)
+
+// libp2pHello is the hello message of the libp2p layer. Its network ID is the
+// protocol version of the TRON network.
+type libp2pHello struct {
+	From      *core.Endpoint
+	NetworkID int32
+	Code      int32
+	Timestamp int64
+	Version   int32
+}
+
+func (h *libp2pHello) marshal() ([]byte, error) {
+	from, err := proto.Marshal(h.From)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, from)
+	b = appendVarint(b, 2, uint64(h.NetworkID))
+	b = appendVarint(b, 3, uint64(h.Code))
+	b = appendVarint(b, 4, uint64(h.Timestamp))
+	b = appendVarint(b, 5, uint64(h.Version))
+	return b, nil
+}
+
+func (h *libp2pHello) unmarshal(data []byte) error {
+	return consumeFields(data, func(num protowire.Number, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			h.From = new(core.Endpoint)
+			return proto.Unmarshal(b, h.From)
+		case 2:
+			h.NetworkID = int32(v)
+		case 3:
+			h.Code = int32(v)
+		case 4:
+			h.Timestamp = int64(v)
+		case 5:
+			h.Version = int32(v)
+		}
+		return nil
+	})
+}
+
+// keepAlive encodes the KeepAliveMessage of pings and pongs.
+func keepAlive(timestamp int64) []byte {
+	return appendVarint(nil, 1, uint64(timestamp))
+}
+
+// p2pDisconnect encodes a P2pDisconnectMessage.
+func p2pDisconnect(reason int32) []byte {
+	return appendVarint(nil, 1, uint64(reason))
+}
+
+// p2pDisconnectReason decodes the reason of a P2pDisconnectMessage.
+func p2pDisconnectReason(data []byte) (int32, error) {
+	var reason int32
+	err := consumeFields(data, func(num protowire.Number, v uint64, _ []byte) error {
+		if num == 1 {
+			reason = int32(v)
+		}
+		return nil
+	})
+	return reason, err
+}
+
+// compress wraps a message in a CompressMessage, compressed with snappy when it
+// is smaller.
+func compress(data []byte) []byte {
+	typ, payload := uint64(uncompressed), data
+	if compressed := snappy.Encode(nil, data); len(compressed) < len(data) {
+		typ, payload = snappyCompressed, compressed
+	}
+	var b []byte
+	if typ != uncompressed {
+		b = appendVarint(b, 1, typ)
+	}
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, payload)
+}
+
+// uncompress returns the message wrapped in a CompressMessage.
+func uncompress(frame []byte) ([]byte, error) {
+	var typ uint64
+	var data []byte
+	err := consumeFields(frame, func(num protowire.Number, v uint64, b []byte) error {
+		switch num {
+		case 1:
+			typ = v
+		case 2:
+			data = b
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compressed message: %w", err)
+	}
+	switch typ {
+	case uncompressed:
+	case snappyCompressed:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, fmt.Errorf("compressed message: %w", err)
+		}
+		if size >= MaxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the limit of %d", size, MaxMessageSize)
+		}
+		if data, err = snappy.Decode(nil, data); err != nil {
+			return nil, fmt.Errorf("compressed message: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("compressed message: unknown compression %d", typ)
+	}
+	if len(data) == 0 {
+		return nil, errEmptyMessage
+	}
+	return data, nil
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// consumeFields calls field with each field of an encoded message: the value of
+// varint fields, the content of length-delimited ones. Other fields are skipped.
+func consumeFields(data []byte, field func(num protowire.Number, v uint64, b []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		var v uint64
+		var b []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			b, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+		if err := field(num, v, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
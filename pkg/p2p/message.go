@@ -0,0 +1,141 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+// Package p2p speaks the TRON peer-to-peer protocol with java-tron nodes: it
+// performs the handshake, follows the inventory of transactions and blocks the
+// node advertises, and fetches them directly from the node. Discovery crawls
+// the network over UDP to find nodes.
+//
+// Each message on the wire is a protobuf varint length followed by a one byte
+// message type and the protobuf encoding of the message.
+//
+// From 4.7, java-tron nodes run the libp2p layer of the tronprotocol/libp2p
+// library: the connection opens with the hello message of libp2p, after which
+// each frame holds a CompressMessage wrapping a message, compressed with snappy
+// when it is smaller. The libp2p layer sends its own keep-alive messages. Older
+// nodes speak the legacy protocol, where the TRON hello message opens the
+// connection and the frames hold the messages directly.
+package p2p
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MessageType is the first byte of a message, identifying its content.
+type MessageType byte
+
+// Message types of the TRON protocol.
+const (
+	MsgTransaction         MessageType = 0x01 // core.Transaction
+	MsgBlock               MessageType = 0x02 // core.Block
+	MsgTransactions        MessageType = 0x03 // core.Transactions
+	MsgBlocks              MessageType = 0x04 // core.Items
+	MsgBlockHeaders        MessageType = 0x05 // core.Items
+	MsgInventory           MessageType = 0x06 // core.Inventory
+	MsgFetchInvData        MessageType = 0x07 // core.Inventory
+	MsgSyncBlockChain      MessageType = 0x08 // core.BlockInventory
+	MsgBlockChainInventory MessageType = 0x09 // core.ChainInventory
+	MsgItemNotFound        MessageType = 0x10 // core.Items
+	MsgFetchBlockHeaders   MessageType = 0x11 // core.BlockInventory
+	MsgBlockInventory      MessageType = 0x12 // core.BlockInventory
+	MsgTransactionInv      MessageType = 0x13 // core.Inventory
+	MsgPbftCommit          MessageType = 0x14 // core.PBFTCommitResult
+
+	MsgHello      MessageType = 0x20 // core.HelloMessage
+	MsgDisconnect MessageType = 0x21 // core.DisconnectMessage
+	MsgPing       MessageType = 0x22 // fixed payload
+	MsgPong       MessageType = 0x23 // fixed payload
+)
+
+// MaxMessageSize is the largest message accepted by java-tron nodes.
+const MaxMessageSize = 5 * 1024 * 1024
+
+// pingPayload is the fixed content of ping and pong messages.
+var pingPayload = []byte{0xc0}
+
+var errEmptyMessage = errors.New("empty message")
+
+// Message is a message read from a peer, with its content still encoded.
+type Message struct {
+	Type MessageType
+	Data []byte
+}
+
+// Decode unmarshals the content of the message into m.
+func (msg *Message) Decode(m proto.Message) error {
+	if err := proto.Unmarshal(msg.Data, m); err != nil {
+		return fmt.Errorf("message 0x%02x: %w", byte(msg.Type), err)
+	}
+	return nil
+}
+
+// Encode returns the content of a message as sent on the wire: the message type
+// followed by the encoded message. A nil message is sent as the ping payload.
+func Encode(t MessageType, m proto.Message) ([]byte, error) {
+	data := pingPayload
+	if m != nil {
+		var err error
+		if data, err = proto.Marshal(m); err != nil {
+			return nil, err
+		}
+	}
+	return append([]byte{byte(t)}, data...), nil
+}
+
+// WriteMessage writes a message to w with its length prefix.
+func WriteMessage(w io.Writer, t MessageType, m proto.Message) error {
+	data, err := Encode(t, m)
+	if err != nil {
+		return err
+	}
+	return writeFrame(w, data)
+}
+
+// ReadMessage reads a length-prefixed message from r.
+func ReadMessage(r *bufio.Reader) (*Message, error) {
+	data, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Type: MessageType(data[0]), Data: data[1:]}, nil
+}
+
+// writeFrame writes data to w with its length prefix.
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) >= MaxMessageSize {
+		return fmt.Errorf("message of %d bytes exceeds the limit of %d", len(data), MaxMessageSize)
+	}
+	frame := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen32+len(data)), uint64(len(data)))
+	_, err := w.Write(append(frame, data...))
+	return err
+}
+
+// readFrame reads the content of a length-prefixed frame from r.
+func readFrame(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size >= MaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the limit of %d", size, MaxMessageSize)
+	}
+	if size == 0 {
+		return nil, errEmptyMessage
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
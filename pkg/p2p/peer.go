@@ -0,0 +1,644 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protocol versions of the public networks, sent in the hello message.
+const (
+	MainnetVersion int32 = 11111
+	NileVersion    int32 = 201910292
+)
+
+const (
+	// DefaultPort is the port java-tron nodes listen on for peers.
+	DefaultPort = 18888
+	// DefaultTimeout is the time without messages after which a peer is dropped.
+	// Nodes ping their peers every 10 seconds.
+	DefaultTimeout = time.Minute
+	// NodeIDLength is the length of a node ID, an uncompressed public key without prefix.
+	NodeIDLength = 64
+)
+
+var (
+	// ErrIncompatible is returned when the peer is on another network.
+	ErrIncompatible = errors.New("peer is on another network")
+	// ErrUnsupportedProtocol is returned when the peer does not answer the
+	// handshake of the protocol, as legacy nodes do with libp2p and the reverse.
+	ErrUnsupportedProtocol = errors.New("peer does not speak the protocol")
+)
+
+// DisconnectError is returned when the peer closes the connection with a reason.
+type DisconnectError struct {
+	Reason core.ReasonCode
+}
+
+func (e *DisconnectError) Error() string {
+	return "peer disconnected: " + e.Reason.String()
+}
+
+// P2PDisconnectError is returned when the libp2p layer of the peer refuses the
+// handshake or closes the connection, with the code of its hello message or the
+// reason of its disconnect message.
+type P2PDisconnectError struct {
+	Code int32
+}
+
+func (e *P2PDisconnectError) Error() string {
+	return fmt.Sprintf("peer disconnected: libp2p code %d", e.Code)
+}
+
+// BlockID identifies a block: the hash of its header with the first 8 bytes
+// replaced by the big-endian block number.
+type BlockID []byte
+
+// Number returns the block number encoded in the ID.
+func (id BlockID) Number() int64 {
+	if len(id) < 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(id))
+}
+
+func (id BlockID) String() string {
+	return hex.EncodeToString(id)
+}
+
+// HeaderID computes the ID of a block from its header.
+func HeaderID(raw *core.BlockHeaderRaw) (BlockID, error) {
+	data, err := proto.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	binary.BigEndian.PutUint64(hash[:], uint64(raw.GetNumber()))
+	return hash[:], nil
+}
+
+// Handler receives the inventory and data pushed by a peer. The functions are
+// called from the goroutine reading the connection and must not block it. Nil
+// functions are skipped.
+type Handler struct {
+	// Inventory is called with the IDs of the transactions or blocks advertised
+	// by the peer.
+	Inventory func(p *Peer, inv *core.Inventory)
+	// Transactions is called with fetched transactions. When set, advertised
+	// transactions are fetched as they are announced.
+	Transactions func(p *Peer, txs []*core.Transaction)
+	// Block is called with fetched blocks that are not awaited by FetchBlocks.
+	// When set, advertised blocks are fetched as they are announced.
+	Block func(p *Peer, block *core.Block)
+}
+
+// Config describes the local node announced to the peer.
+type Config struct {
+	Version int32  // protocol version of the network, MainnetVersion when 0
+	NodeID  []byte // random when empty
+	Host    string // advertised address, may be empty
+	Port    int32  // advertised port, 0 when not listening
+
+	Genesis BlockID // genesis block of the network
+	Solid   BlockID // a solidified block known to the peer
+	// Head is the head block announced to the peer, Solid when empty. It must
+	// not be ahead of the peer, which would then try to sync from us.
+	Head BlockID
+
+	Handler  Handler
+	Timeout  time.Duration // DefaultTimeout when 0
+	Protocol Protocol      // ProtocolAuto when 0
+}
+
+// Peer is a connection to a java-tron node.
+type Peer struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	cfg     Config
+	handler Handler
+	hello   *core.HelloMessage
+
+	protocol   Protocol // protocol spoken with the peer
+	compressed bool     // frames hold CompressMessages
+
+	wmu sync.Mutex // serializes writes
+
+	syncMu   sync.Mutex // one chain sync request at a time
+	synced   BlockID    // last block ID returned by SyncChain
+	mu       sync.Mutex
+	chainInv chan *core.ChainInventory
+	blocks   map[string]chan *core.Block // blocks awaited by FetchBlocks
+
+	done chan struct{}
+	err  error
+}
+
+// Dial connects to a node and performs the handshake. With ProtocolAuto, it
+// connects again with the legacy protocol when the node does not answer the
+// libp2p handshake.
+func Dial(ctx context.Context, addr string, cfg Config) (*Peer, error) {
+	p, err := dial(ctx, addr, cfg)
+	if cfg.Protocol == ProtocolAuto && errors.Is(err, ErrUnsupportedProtocol) {
+		cfg.Protocol = ProtocolLegacy
+		p, err = dial(ctx, addr, cfg)
+	}
+	return p, err
+}
+
+func dial(ctx context.Context, addr string, cfg Config) (*Peer, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPeer(ctx, conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewPeer performs the handshake on an established connection and starts
+// reading the messages of the peer. ProtocolAuto speaks libp2p.
+func NewPeer(ctx context.Context, conn net.Conn, cfg Config) (*Peer, error) {
+	if cfg.Version == 0 {
+		cfg.Version = MainnetVersion
+	}
+	if cfg.Protocol == ProtocolAuto {
+		cfg.Protocol = ProtocolLibp2p
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if len(cfg.Head) == 0 {
+		cfg.Head = cfg.Solid
+	}
+	if len(cfg.Genesis) == 0 || len(cfg.Solid) == 0 {
+		return nil, fmt.Errorf("NewPeer: the genesis and solidified block IDs are required")
+	}
+	if len(cfg.NodeID) == 0 {
+		cfg.NodeID = make([]byte, NodeIDLength)
+		if _, err := rand.Read(cfg.NodeID); err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Peer{
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		cfg:      cfg,
+		handler:  cfg.Handler,
+		protocol: cfg.Protocol,
+		chainInv: make(chan *core.ChainInventory, 1),
+		blocks:   make(map[string]chan *core.Block),
+		done:     make(chan struct{}),
+	}
+	if err := p.handshake(ctx); err != nil {
+		return nil, err
+	}
+	go p.readLoop()
+	return p, nil
+}
+
+// handshake exchanges hello messages, of the libp2p layer first when the peer
+// speaks libp2p, and checks the peer is on the same network.
+func (p *Peer) handshake(ctx context.Context) error {
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	defer p.conn.SetDeadline(time.Time{})
+
+	if p.protocol == ProtocolLibp2p {
+		if err := p.libp2pHandshake(); err != nil {
+			return fmt.Errorf("handshake: %w", err)
+		}
+	}
+
+	hello := &core.HelloMessage{
+		From:           &core.Endpoint{Address: []byte(p.cfg.Host), Port: p.cfg.Port, NodeId: p.cfg.NodeID},
+		Version:        p.cfg.Version,
+		Timestamp:      time.Now().UnixMilli(),
+		GenesisBlockId: helloBlockID(p.cfg.Genesis),
+		SolidBlockId:   helloBlockID(p.cfg.Solid),
+		HeadBlockId:    helloBlockID(p.cfg.Head),
+	}
+	if err := p.send(MsgHello, hello); err != nil {
+		return fmt.Errorf("handshake: %w", err)
+	}
+
+	msg, err := p.readMessage()
+	for err == nil && (msg.Type == MsgKeepAlivePing || msg.Type == MsgKeepAlivePong) {
+		if err = p.handle(msg); err == nil {
+			msg, err = p.readMessage()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("handshake: %w", err)
+	}
+	switch msg.Type {
+	case MsgHello:
+	case MsgDisconnect:
+		reason := new(core.DisconnectMessage)
+		if err := msg.Decode(reason); err != nil {
+			return fmt.Errorf("handshake: %w", err)
+		}
+		return fmt.Errorf("handshake: %w", &DisconnectError{Reason: reason.GetReason()})
+	case MsgP2PDisconnect:
+		return fmt.Errorf("handshake: %w", p2pDisconnectError(msg))
+	case MsgTransaction, MsgBlock, MsgTransactions, MsgBlocks, MsgBlockHeaders, MsgInventory,
+		MsgFetchInvData, MsgSyncBlockChain, MsgBlockChainInventory, MsgItemNotFound,
+		MsgFetchBlockHeaders, MsgBlockInventory, MsgTransactionInv, MsgPbftCommit, MsgPing, MsgPong:
+		return fmt.Errorf("handshake: unexpected message 0x%02x", byte(msg.Type))
+	default:
+		return fmt.Errorf("handshake: %w: message 0x%02x", ErrUnsupportedProtocol, byte(msg.Type))
+	}
+
+	remote := new(core.HelloMessage)
+	if err := msg.Decode(remote); err != nil {
+		return fmt.Errorf("handshake: %w", err)
+	}
+	if remote.GetVersion() != p.cfg.Version {
+		_ = p.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_INCOMPATIBLE_VERSION})
+		return fmt.Errorf("handshake: %w: version %d", ErrIncompatible, remote.GetVersion())
+	}
+	if !bytes.Equal(remote.GetGenesisBlockId().GetHash(), p.cfg.Genesis) {
+		_ = p.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_INCOMPATIBLE_CHAIN})
+		return fmt.Errorf("handshake: %w: genesis block %x", ErrIncompatible, remote.GetGenesisBlockId().GetHash())
+	}
+	p.hello = remote
+	return nil
+}
+
+// libp2pHandshake exchanges the hello messages of the libp2p layer, after which
+// the frames are compressed. The client opens the handshake and the node answers
+// with its own hello, or closes the connection if it speaks the legacy protocol.
+func (p *Peer) libp2pHandshake() error {
+	hello := &libp2pHello{
+		From:      &core.Endpoint{Address: []byte(p.cfg.Host), Port: p.cfg.Port, NodeId: p.cfg.NodeID},
+		NetworkID: p.cfg.Version,
+		Code:      libp2pNormal,
+		Timestamp: time.Now().UnixMilli(),
+		Version:   libp2pVersion,
+	}
+	data, err := hello.marshal()
+	if err != nil {
+		return err
+	}
+	if err := p.write(MsgP2PHello, data); err != nil {
+		return err
+	}
+
+	msg, err := p.readMessage()
+	if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+		return fmt.Errorf("%w: connection closed after the libp2p hello", ErrUnsupportedProtocol)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case msg.Type == MsgP2PHello:
+	case msg.Type == MsgP2PDisconnect:
+		return p2pDisconnectError(msg)
+	case msg.Type < 0x80:
+		return fmt.Errorf("%w: message 0x%02x", ErrUnsupportedProtocol, byte(msg.Type))
+	default:
+		return fmt.Errorf("unexpected message 0x%02x", byte(msg.Type))
+	}
+
+	remote := new(libp2pHello)
+	if err := remote.unmarshal(msg.Data); err != nil {
+		return fmt.Errorf("message 0x%02x: %w", byte(msg.Type), err)
+	}
+	if remote.Code != libp2pNormal {
+		return &P2PDisconnectError{Code: remote.Code}
+	}
+	if remote.NetworkID != p.cfg.Version {
+		_ = p.write(MsgP2PDisconnect, p2pDisconnect(libp2pDifferentVersion))
+		return fmt.Errorf("%w: version %d", ErrIncompatible, remote.NetworkID)
+	}
+	p.compressed = true
+	return nil
+}
+
+// Protocol returns the protocol spoken with the peer.
+func (p *Peer) Protocol() Protocol {
+	return p.protocol
+}
+
+// Hello returns the hello message of the peer.
+func (p *Peer) Hello() *core.HelloMessage {
+	return p.hello
+}
+
+// NodeID returns the ID of the peer.
+func (p *Peer) NodeID() []byte {
+	return p.hello.GetFrom().GetNodeId()
+}
+
+// Head returns the head block announced by the peer in the handshake.
+func (p *Peer) Head() BlockID {
+	return p.hello.GetHeadBlockId().GetHash()
+}
+
+// Done is closed when the connection is closed.
+func (p *Peer) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err waits for the connection to close and returns the reason, nil when it was
+// closed by Close.
+func (p *Peer) Err() error {
+	<-p.done
+	return p.err
+}
+
+// Close disconnects from the peer.
+func (p *Peer) Close() error {
+	_ = p.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_REQUESTED})
+	return p.conn.Close()
+}
+
+// SyncChain asks the peer for the IDs of its main chain from the given block,
+// which must be on it. The peer returns up to 2000 IDs starting with the given
+// block, and the number of blocks after them. It only advertises its inventory
+// once a request is answered with the given block alone.
+func (p *Peer) SyncChain(ctx context.Context, from BlockID) (*core.ChainInventory, error) {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	req := &core.BlockInventory{
+		Ids:  []*core.BlockInventory_BlockId{{Hash: from, Number: from.Number()}},
+		Type: core.BlockInventory_SYNC,
+	}
+	if err := p.send(MsgSyncBlockChain, req); err != nil {
+		return nil, fmt.Errorf("SyncChain: %w", err)
+	}
+	select {
+	case inv := <-p.chainInv:
+		if ids := inv.GetIds(); len(ids) > 0 {
+			p.synced = ids[len(ids)-1].GetHash()
+		}
+		return inv, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.done:
+		return nil, fmt.Errorf("SyncChain: %w", p.err)
+	}
+}
+
+// Subscribe catches up with the head of the peer, starting from the last block
+// returned by SyncChain or the head announced in the handshake, and returns it.
+// From then on the peer advertises its new transactions and blocks to the
+// handler.
+func (p *Peer) Subscribe(ctx context.Context) (BlockID, error) {
+	p.syncMu.Lock()
+	head := p.synced
+	p.syncMu.Unlock()
+	if len(head) == 0 {
+		head = p.cfg.Head
+	}
+	for {
+		inv, err := p.SyncChain(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+		ids := inv.GetIds()
+		if len(ids) == 0 || !bytes.Equal(ids[0].GetHash(), head) {
+			return nil, fmt.Errorf("Subscribe: peer does not have block %d on its chain", head.Number())
+		}
+		if len(ids) == 1 {
+			return head, nil
+		}
+		head = ids[len(ids)-1].GetHash()
+	}
+}
+
+// FetchBlocks fetches blocks from the peer and returns them in order. The peer
+// only serves blocks it advertised, or blocks returned by SyncChain before the
+// chain sync completes; it disconnects on other requests.
+func (p *Peer) FetchBlocks(ctx context.Context, ids []BlockID) ([]*core.Block, error) {
+	waits := make([]chan *core.Block, len(ids))
+	req := &core.Inventory{Type: core.Inventory_BLOCK}
+	p.mu.Lock()
+	for i, id := range ids {
+		waits[i] = make(chan *core.Block, 1)
+		p.blocks[string(id)] = waits[i]
+		req.Ids = append(req.Ids, id)
+	}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		for _, id := range ids {
+			delete(p.blocks, string(id))
+		}
+		p.mu.Unlock()
+	}()
+
+	if err := p.send(MsgFetchInvData, req); err != nil {
+		return nil, fmt.Errorf("FetchBlocks: %w", err)
+	}
+	blocks := make([]*core.Block, len(ids))
+	for i, wait := range waits {
+		select {
+		case blocks[i] = <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.done:
+			return nil, fmt.Errorf("FetchBlocks: %w", p.err)
+		}
+	}
+	return blocks, nil
+}
+
+// FetchTransactions requests advertised transactions from the peer. They are
+// passed to the Transactions handler when received.
+func (p *Peer) FetchTransactions(ids [][]byte) error {
+	if err := p.send(MsgFetchInvData, &core.Inventory{Type: core.Inventory_TRX, Ids: ids}); err != nil {
+		return fmt.Errorf("FetchTransactions: %w", err)
+	}
+	return nil
+}
+
+// send writes a message to the peer, or returns the reason the connection was closed.
+func (p *Peer) send(t MessageType, m proto.Message) error {
+	data, err := Encode(t, m)
+	if err != nil {
+		return err
+	}
+	return p.write(t, data[1:])
+}
+
+// write writes an encoded message to the peer, in a CompressMessage after the
+// libp2p handshake.
+func (p *Peer) write(t MessageType, data []byte) error {
+	select {
+	case <-p.done:
+		if p.err != nil {
+			return p.err
+		}
+		return net.ErrClosed
+	default:
+	}
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	frame := append([]byte{byte(t)}, data...)
+	if p.compressed {
+		frame = compress(frame)
+	}
+	return writeFrame(p.conn, frame)
+}
+
+// readMessage reads the next message of the peer.
+func (p *Peer) readMessage() (*Message, error) {
+	data, err := readFrame(p.r)
+	if err == nil && p.compressed {
+		data, err = uncompress(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Type: MessageType(data[0]), Data: data[1:]}, nil
+}
+
+// readLoop handles the messages of the peer until the connection fails.
+func (p *Peer) readLoop() {
+	var err error
+	for err == nil {
+		if err = p.conn.SetReadDeadline(time.Now().Add(p.cfg.Timeout)); err != nil {
+			break
+		}
+		var msg *Message
+		if msg, err = p.readMessage(); err == nil {
+			err = p.handle(msg)
+		}
+	}
+	if errors.Is(err, net.ErrClosed) {
+		err = nil // closed locally
+	}
+	p.err = err
+	p.conn.Close()
+	close(p.done)
+}
+
+// handle processes a message of the peer. Messages the client does not use are
+// ignored.
+func (p *Peer) handle(msg *Message) error {
+	switch msg.Type {
+	case MsgPing:
+		return p.send(MsgPong, nil)
+
+	case MsgKeepAlivePing:
+		return p.write(MsgKeepAlivePong, keepAlive(time.Now().UnixMilli()))
+
+	case MsgP2PDisconnect:
+		return p2pDisconnectError(msg)
+
+	case MsgDisconnect:
+		reason := new(core.DisconnectMessage)
+		if err := msg.Decode(reason); err != nil {
+			return err
+		}
+		return &DisconnectError{Reason: reason.GetReason()}
+
+	case MsgBlockChainInventory:
+		inv := new(core.ChainInventory)
+		if err := msg.Decode(inv); err != nil {
+			return err
+		}
+		select {
+		case p.chainInv <- inv:
+		default: // not requested
+		}
+
+	case MsgInventory, MsgTransactionInv:
+		inv := new(core.Inventory)
+		if err := msg.Decode(inv); err != nil {
+			return err
+		}
+		if p.handler.Inventory != nil {
+			p.handler.Inventory(p, inv)
+		}
+		if inv.GetType() == core.Inventory_TRX && p.handler.Transactions != nil ||
+			inv.GetType() == core.Inventory_BLOCK && p.handler.Block != nil {
+			return p.send(MsgFetchInvData, inv)
+		}
+
+	case MsgTransaction:
+		tx := new(core.Transaction)
+		if err := msg.Decode(tx); err != nil {
+			return err
+		}
+		if p.handler.Transactions != nil {
+			p.handler.Transactions(p, []*core.Transaction{tx})
+		}
+
+	case MsgTransactions:
+		txs := new(core.Transactions)
+		if err := msg.Decode(txs); err != nil {
+			return err
+		}
+		if p.handler.Transactions != nil {
+			p.handler.Transactions(p, txs.GetTransactions())
+		}
+
+	case MsgBlock:
+		block := new(core.Block)
+		if err := msg.Decode(block); err != nil {
+			return err
+		}
+		id, err := HeaderID(block.GetBlockHeader().GetRawData())
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		wait, ok := p.blocks[string(id)]
+		delete(p.blocks, string(id))
+		p.mu.Unlock()
+		if ok {
+			wait <- block
+		} else if p.handler.Block != nil {
+			p.handler.Block(p, block)
+		}
+	}
+	return nil
+}
+
+// p2pDisconnectError returns the error of a disconnect message of libp2p.
+func p2pDisconnectError(msg *Message) error {
+	reason, err := p2pDisconnectReason(msg.Data)
+	if err != nil {
+		return fmt.Errorf("message 0x%02x: %w", byte(msg.Type), err)
+	}
+	return &P2PDisconnectError{Code: reason}
+}
+
+func helloBlockID(id BlockID) *core.HelloMessage_BlockId {
+	return &core.HelloMessage_BlockId{Hash: id, Number: id.Number()}
+}
@@ -0,0 +1,449 @@
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeNode is the java-tron side of a connection, scripted by the tests.
+type fakeNode struct {
+	t          *testing.T
+	conn       net.Conn
+	r          *bufio.Reader
+	compressed bool // after the libp2p handshake
+}
+
+// read reads the next message.
+func (n *fakeNode) read() (*Message, error) {
+	data, err := readFrame(n.r)
+	if err == nil && n.compressed {
+		data, err = uncompress(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Type: MessageType(data[0]), Data: data[1:]}, nil
+}
+
+// expect reads the next message, checks its type and decodes it into m.
+func (n *fakeNode) expect(typ MessageType, m proto.Message) bool {
+	msg, err := n.read()
+	if !assert.Nil(n.t, err) || !assert.Equal(n.t, typ, msg.Type) {
+		return false
+	}
+	if m == nil {
+		return assert.Equal(n.t, pingPayload, msg.Data)
+	}
+	return assert.Nil(n.t, msg.Decode(m))
+}
+
+func (n *fakeNode) send(typ MessageType, m proto.Message) {
+	data, err := Encode(typ, m)
+	require.Nil(n.t, err)
+	n.write(typ, data[1:])
+}
+
+// write sends an encoded message.
+func (n *fakeNode) write(typ MessageType, data []byte) {
+	frame := append([]byte{byte(typ)}, data...)
+	if n.compressed {
+		frame = compress(frame)
+	}
+	assert.Nil(n.t, writeFrame(n.conn, frame))
+}
+
+// acceptLibp2p answers the libp2p hello of the client as a node of the network.
+func (n *fakeNode) acceptLibp2p(networkID int32) *libp2pHello {
+	msg, err := n.read()
+	if !assert.Nil(n.t, err) || !assert.Equal(n.t, MsgP2PHello, msg.Type) {
+		return nil
+	}
+	hello := new(libp2pHello)
+	assert.Nil(n.t, hello.unmarshal(msg.Data))
+	data, err := (&libp2pHello{
+		From:      &core.Endpoint{NodeId: bytes.Repeat([]byte{7}, NodeIDLength), Port: DefaultPort},
+		NetworkID: networkID,
+		Timestamp: hello.Timestamp,
+		Version:   libp2pVersion,
+	}).marshal()
+	require.Nil(n.t, err)
+	n.write(MsgP2PHello, data)
+	n.compressed = true
+	return hello
+}
+
+// listen starts a node running a script on each of the next connections.
+func listen(t *testing.T, scripts ...func(n *fakeNode)) (string, <-chan struct{}) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { ln.Close() })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, script := range scripts {
+			conn, err := ln.Accept()
+			if !assert.Nil(t, err) {
+				return
+			}
+			script(&fakeNode{t: t, conn: conn, r: bufio.NewReader(conn)})
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String(), done
+}
+
+// testChain returns the IDs of a chain of empty blocks.
+func testChain(t *testing.T, length int) ([]BlockID, []*core.Block) {
+	var ids []BlockID
+	var blocks []*core.Block
+	var parent []byte
+	for i := 0; i < length; i++ {
+		raw := &core.BlockHeaderRaw{Number: int64(i), ParentHash: parent, Timestamp: int64(1_700_000_000_000 + i*3000)}
+		id, err := HeaderID(raw)
+		require.Nil(t, err)
+		ids = append(ids, id)
+		blocks = append(blocks, &core.Block{BlockHeader: &core.BlockHeader{RawData: raw}})
+		parent = id
+	}
+	return ids, blocks
+}
+
+// nodeHello answers the hello of the client as a node with the given head.
+func nodeHello(n *fakeNode, genesis, head BlockID) *core.HelloMessage {
+	hello := new(core.HelloMessage)
+	if !n.expect(MsgHello, hello) {
+		return nil
+	}
+	n.send(MsgHello, &core.HelloMessage{
+		From:           &core.Endpoint{NodeId: bytes.Repeat([]byte{7}, NodeIDLength), Port: DefaultPort},
+		Version:        MainnetVersion,
+		Timestamp:      hello.GetTimestamp(),
+		GenesisBlockId: helloBlockID(genesis),
+		SolidBlockId:   helloBlockID(head),
+		HeadBlockId:    helloBlockID(head),
+	})
+	return hello
+}
+
+func TestHandshake(t *testing.T) {
+	ids, _ := testChain(t, 20)
+	addr, done := listen(t, func(n *fakeNode) {
+		n.acceptLibp2p(MainnetVersion)
+		hello := nodeHello(n, ids[0], ids[19])
+		assert.Equal(t, MainnetVersion, hello.GetVersion())
+		assert.Len(t, hello.GetFrom().GetNodeId(), NodeIDLength)
+		assert.Equal(t, []byte(ids[0]), hello.GetGenesisBlockId().GetHash())
+		assert.Equal(t, int64(10), hello.GetSolidBlockId().GetNumber())
+		assert.Equal(t, []byte(ids[10]), hello.GetHeadBlockId().GetHash())
+
+		reason := new(core.DisconnectMessage)
+		n.expect(MsgDisconnect, reason)
+		assert.Equal(t, core.ReasonCode_REQUESTED, reason.GetReason())
+	})
+
+	p, err := Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[10]})
+	require.Nil(t, err)
+	assert.Equal(t, int64(19), p.Head().Number())
+	assert.Equal(t, bytes.Repeat([]byte{7}, NodeIDLength), p.NodeID())
+	assert.Equal(t, ProtocolLibp2p, p.Protocol())
+
+	require.Nil(t, p.Close())
+	<-done
+	assert.Nil(t, p.Err())
+}
+
+func TestHandshakeRejected(t *testing.T) {
+	ids, _ := testChain(t, 3)
+	other, _ := testChain(t, 1)
+	other[0][31]++
+
+	addr, done := listen(t, func(n *fakeNode) {
+		n.acceptLibp2p(MainnetVersion)
+		nodeHello(n, other[0], ids[2])
+		reason := new(core.DisconnectMessage)
+		n.expect(MsgDisconnect, reason)
+		assert.Equal(t, core.ReasonCode_INCOMPATIBLE_CHAIN, reason.GetReason())
+	})
+	_, err := Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1]})
+	assert.ErrorIs(t, err, ErrIncompatible)
+	<-done
+
+	addr, done = listen(t, func(n *fakeNode) {
+		n.acceptLibp2p(MainnetVersion)
+		n.expect(MsgHello, new(core.HelloMessage))
+		n.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_TOO_MANY_PEERS})
+	})
+	_, err = Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1]})
+	var disconnect *DisconnectError
+	require.True(t, errors.As(err, &disconnect))
+	assert.Equal(t, core.ReasonCode_TOO_MANY_PEERS, disconnect.Reason)
+	<-done
+
+	// The libp2p layer of a node of another network.
+	addr, done = listen(t, func(n *fakeNode) {
+		n.acceptLibp2p(NileVersion)
+		n.compressed = false // the handshake failed
+		msg, err := n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgP2PDisconnect, msg.Type)
+		reason, err := p2pDisconnectReason(msg.Data)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(libp2pDifferentVersion), reason)
+	})
+	_, err = Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1]})
+	assert.ErrorIs(t, err, ErrIncompatible)
+	<-done
+
+	// A libp2p hello refusing the connection.
+	addr, done = listen(t, func(n *fakeNode) {
+		_, err := n.read()
+		require.Nil(t, err)
+		data, err := (&libp2pHello{NetworkID: MainnetVersion, Code: 1, Version: libp2pVersion}).marshal()
+		require.Nil(t, err)
+		n.write(MsgP2PHello, data)
+	})
+	_, err = Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1]})
+	var p2pDisconnect *P2PDisconnectError
+	require.True(t, errors.As(err, &p2pDisconnect))
+	assert.Equal(t, int32(1), p2pDisconnect.Code)
+	<-done
+}
+
+func TestHandshakeLibp2p(t *testing.T) {
+	ids, _ := testChain(t, 3)
+	addr, done := listen(t, func(n *fakeNode) {
+		hello := n.acceptLibp2p(MainnetVersion)
+		assert.Equal(t, MainnetVersion, hello.NetworkID)
+		assert.Equal(t, int32(libp2pVersion), hello.Version)
+		assert.Equal(t, int32(libp2pNormal), hello.Code)
+		assert.Len(t, hello.From.GetNodeId(), NodeIDLength)
+
+		// The keep-alive of the libp2p layer may come before the TRON hello.
+		n.write(MsgKeepAlivePing, keepAlive(hello.Timestamp))
+		msg, err := n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgHello, msg.Type)
+		msg, err = n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgKeepAlivePong, msg.Type)
+		n.send(MsgHello, &core.HelloMessage{
+			From:           &core.Endpoint{NodeId: bytes.Repeat([]byte{7}, NodeIDLength)},
+			Version:        MainnetVersion,
+			GenesisBlockId: helloBlockID(ids[0]),
+			SolidBlockId:   helloBlockID(ids[2]),
+			HeadBlockId:    helloBlockID(ids[2]),
+		})
+
+		n.write(MsgKeepAlivePing, keepAlive(hello.Timestamp))
+		msg, err = n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgKeepAlivePong, msg.Type)
+		n.write(MsgP2PDisconnect, p2pDisconnect(7))
+	})
+	p, err := Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1], Protocol: ProtocolLibp2p})
+	require.Nil(t, err)
+	assert.Equal(t, ProtocolLibp2p, p.Protocol())
+	<-done
+	var disconnect *P2PDisconnectError
+	require.True(t, errors.As(p.Err(), &disconnect))
+	assert.Equal(t, int32(7), disconnect.Code)
+}
+
+func TestHandshakeLegacy(t *testing.T) {
+	ids, _ := testChain(t, 3)
+
+	// A legacy node closes the connection on the libp2p hello, and the client
+	// connects again with the legacy protocol.
+	addr, done := listen(t, func(n *fakeNode) {
+		msg, err := n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgP2PHello, msg.Type)
+	}, func(n *fakeNode) {
+		nodeHello(n, ids[0], ids[2])
+		n.expect(MsgDisconnect, new(core.DisconnectMessage))
+	})
+	p, err := Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1]})
+	require.Nil(t, err)
+	assert.Equal(t, ProtocolLegacy, p.Protocol())
+	assert.Equal(t, int64(2), p.Head().Number())
+	require.Nil(t, p.Close())
+	<-done
+
+	// Without fallback when libp2p is required.
+	addr, done = listen(t, func(n *fakeNode) {
+		msg, err := n.read()
+		require.Nil(t, err)
+		assert.Equal(t, MsgP2PHello, msg.Type)
+		n.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_BAD_PROTOCOL})
+	})
+	_, err = Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1], Protocol: ProtocolLibp2p})
+	assert.ErrorIs(t, err, ErrUnsupportedProtocol)
+	<-done
+
+	// A libp2p node answering the legacy hello of the client.
+	addr, done = listen(t, func(n *fakeNode) {
+		_, err := n.read()
+		require.Nil(t, err)
+		n.write(MsgP2PHello, nil)
+	})
+	_, err = Dial(context.Background(), addr, Config{Genesis: ids[0], Solid: ids[1], Protocol: ProtocolLegacy})
+	assert.ErrorIs(t, err, ErrUnsupportedProtocol)
+	<-done
+}
+
+func TestSubscribe(t *testing.T) {
+	ids, blocks := testChain(t, 20)
+	tx := &core.Transaction{RawData: &core.TransactionRaw{Timestamp: 1}}
+	txid := []byte("transaction-id")
+
+	addr, done := listen(t, func(n *fakeNode) {
+		n.acceptLibp2p(MainnetVersion)
+		nodeHello(n, ids[0], ids[14])
+
+		// The client is behind: the node lists its chain in two rounds.
+		req := new(core.BlockInventory)
+		n.expect(MsgSyncBlockChain, req)
+		assert.Equal(t, core.BlockInventory_SYNC, req.GetType())
+		assert.Equal(t, []byte(ids[10]), req.GetIds()[0].GetHash())
+		n.send(MsgBlockChainInventory, chainInventory(ids[10:13], 2))
+
+		fetch := new(core.Inventory)
+		n.expect(MsgFetchInvData, fetch)
+		assert.Equal(t, core.Inventory_BLOCK, fetch.GetType())
+		assert.Equal(t, [][]byte{ids[11], ids[12]}, fetch.GetIds())
+		n.send(MsgBlock, blocks[11])
+		n.send(MsgBlock, blocks[12])
+
+		n.expect(MsgSyncBlockChain, req)
+		assert.Equal(t, []byte(ids[12]), req.GetIds()[0].GetHash())
+		n.send(MsgBlockChainInventory, chainInventory(ids[12:15], 0))
+		n.expect(MsgSyncBlockChain, req)
+		assert.Equal(t, []byte(ids[14]), req.GetIds()[0].GetHash())
+		n.send(MsgBlockChainInventory, chainInventory(ids[14:15], 0))
+
+		n.send(MsgPing, nil)
+		n.expect(MsgPong, nil)
+
+		n.send(MsgInventory, &core.Inventory{Type: core.Inventory_TRX, Ids: [][]byte{txid}})
+		n.expect(MsgFetchInvData, fetch)
+		assert.Equal(t, core.Inventory_TRX, fetch.GetType())
+		assert.Equal(t, [][]byte{txid}, fetch.GetIds())
+		n.send(MsgTransactions, &core.Transactions{Transactions: []*core.Transaction{tx}})
+
+		n.send(MsgInventory, &core.Inventory{Type: core.Inventory_BLOCK, Ids: [][]byte{ids[15]}})
+		n.expect(MsgFetchInvData, fetch)
+		assert.Equal(t, [][]byte{ids[15]}, fetch.GetIds())
+		n.send(MsgBlock, blocks[15])
+
+		n.send(MsgDisconnect, &core.DisconnectMessage{Reason: core.ReasonCode_TIME_OUT})
+	})
+
+	var inventory []*core.Inventory
+	txs := make(chan *core.Transaction, 1)
+	received := make(chan *core.Block, 1)
+	cfg := Config{
+		Genesis: ids[0],
+		Solid:   ids[10],
+		Handler: Handler{
+			Inventory:    func(_ *Peer, inv *core.Inventory) { inventory = append(inventory, inv) },
+			Transactions: func(_ *Peer, list []*core.Transaction) { txs <- list[0] },
+			Block:        func(_ *Peer, block *core.Block) { received <- block },
+		},
+	}
+	p, err := Dial(context.Background(), addr, cfg)
+	require.Nil(t, err)
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inv, err := p.SyncChain(ctx, ids[10])
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), inv.GetRemainNum())
+	fetched, err := p.FetchBlocks(ctx, []BlockID{ids[11], ids[12]})
+	require.Nil(t, err)
+	assert.Equal(t, int64(11), fetched[0].GetBlockHeader().GetRawData().GetNumber())
+	assert.Equal(t, int64(12), fetched[1].GetBlockHeader().GetRawData().GetNumber())
+
+	head, err := p.Subscribe(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, ids[14], head)
+
+	assert.True(t, proto.Equal(tx, <-txs))
+	assert.Equal(t, int64(15), (<-received).GetBlockHeader().GetRawData().GetNumber())
+
+	<-done
+	var disconnect *DisconnectError
+	require.True(t, errors.As(p.Err(), &disconnect))
+	assert.Equal(t, core.ReasonCode_TIME_OUT, disconnect.Reason)
+	require.Len(t, inventory, 2)
+	assert.Equal(t, core.Inventory_BLOCK, inventory[1].GetType())
+
+	_, err = p.SyncChain(ctx, head)
+	assert.ErrorAs(t, err, &disconnect)
+}
+
+func TestMessageFraming(t *testing.T) {
+	var buf bytes.Buffer
+	inv := &core.Inventory{Type: core.Inventory_BLOCK, Ids: [][]byte{bytes.Repeat([]byte{1}, 200)}}
+	require.Nil(t, WriteMessage(&buf, MsgInventory, inv))
+	require.Nil(t, WriteMessage(&buf, MsgPing, nil))
+	// The 2-byte varint length is followed by the message type.
+	assert.Equal(t, byte(MsgInventory), buf.Bytes()[2])
+
+	r := bufio.NewReader(&buf)
+	msg, err := ReadMessage(r)
+	require.Nil(t, err)
+	assert.Equal(t, MsgInventory, msg.Type)
+	decoded := new(core.Inventory)
+	require.Nil(t, msg.Decode(decoded))
+	assert.True(t, proto.Equal(inv, decoded))
+
+	msg, err = ReadMessage(r)
+	require.Nil(t, err)
+	assert.Equal(t, &Message{Type: MsgPing, Data: pingPayload}, msg)
+
+	_, err = ReadMessage(bufio.NewReader(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x03})))
+	assert.ErrorContains(t, err, "exceeds the limit")
+}
+
+func TestCompressedFraming(t *testing.T) {
+	// Messages are compressed with snappy when it makes them smaller.
+	data, err := Encode(MsgInventory, &core.Inventory{Type: core.Inventory_BLOCK, Ids: [][]byte{bytes.Repeat([]byte{1}, 200)}})
+	require.Nil(t, err)
+	frame := compress(data)
+	assert.Less(t, len(frame), len(data))
+	assert.Equal(t, []byte{0x08, snappyCompressed}, frame[:2])
+	decoded, err := uncompress(frame)
+	require.Nil(t, err)
+	assert.Equal(t, data, decoded)
+
+	frame = compress([]byte{byte(MsgKeepAlivePing), 0x08, 0x01})
+	assert.Equal(t, []byte{0x12, 0x03, 0xff, 0x08, 0x01}, frame)
+	decoded, err = uncompress(frame)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0xff, 0x08, 0x01}, decoded)
+
+	_, err = uncompress([]byte{0x08, 0x05, 0x12, 0x01, 0x01})
+	assert.ErrorContains(t, err, "unknown compression")
+	_, err = uncompress(nil)
+	assert.ErrorIs(t, err, errEmptyMessage)
+}
+
+func chainInventory(ids []BlockID, remain int64) *core.ChainInventory {
+	inv := &core.ChainInventory{RemainNum: remain}
+	for _, id := range ids {
+		inv.Ids = append(inv.Ids, &core.ChainInventory_BlockId{Hash: id, Number: id.Number()})
+	}
+	return inv
+}
@@ -0,0 +1,128 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/pkg/p2p"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Default API ports of java-tron nodes.
+const (
+	DefaultGrpcPort = 50051
+	DefaultHTTPPort = 8090
+)
+
+// APIEndpoint is the API of a node that answered a probe.
+type APIEndpoint struct {
+	Node *p2p.Node
+	// Address is the host:port of a gRPC API, for NewGrpcClient, or the base URL
+	// of an HTTP API, for NewHTTPClient.
+	Address string
+	HTTP    bool
+	Head    int64         // head block number reported by the node
+	Latency time.Duration // round trip of GetNowBlock
+}
+
+// APIProbe configures ProbeAPIs.
+type APIProbe struct {
+	// GrpcPorts and HTTPPorts are the ports probed on each node, DefaultGrpcPort
+	// and DefaultHTTPPort when both are empty.
+	GrpcPorts []int
+	HTTPPorts []int
+
+	Timeout     time.Duration // wait for each answer, 3 seconds when 0
+	Concurrency int           // probes at once, 16 when 0
+}
+
+// ProbeAPIs calls GetNowBlock on the API ports of the nodes, for example the
+// healthy nodes of a p2p.Discovery crawl, and returns the APIs that answered by
+// latency. Discovery finds the peer port of the nodes only, and most nodes do
+// not expose their API. The gRPC APIs are probed without transport security.
+func ProbeAPIs(nodes []*p2p.Node, probe APIProbe) []APIEndpoint {
+	if len(probe.GrpcPorts) == 0 && len(probe.HTTPPorts) == 0 {
+		probe.GrpcPorts = []int{DefaultGrpcPort}
+		probe.HTTPPorts = []int{DefaultHTTPPort}
+	}
+	if probe.Timeout == 0 {
+		probe.Timeout = 3 * time.Second
+	}
+	if probe.Concurrency == 0 {
+		probe.Concurrency = 16
+	}
+
+	var candidates []APIEndpoint
+	for _, node := range nodes {
+		for _, port := range probe.GrpcPorts {
+			address := net.JoinHostPort(node.Host, strconv.Itoa(port))
+			candidates = append(candidates, APIEndpoint{Node: node, Address: address})
+		}
+		for _, port := range probe.HTTPPorts {
+			address := "http://" + net.JoinHostPort(node.Host, strconv.Itoa(port))
+			candidates = append(candidates, APIEndpoint{Node: node, Address: address, HTTP: true})
+		}
+	}
+
+	var (
+		mu    sync.Mutex
+		found []APIEndpoint
+		wg    sync.WaitGroup
+	)
+	sem := make(chan struct{}, probe.Concurrency)
+	for _, endpoint := range candidates {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(endpoint APIEndpoint) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if probeAPI(&endpoint, probe.Timeout) {
+				mu.Lock()
+				found = append(found, endpoint)
+				mu.Unlock()
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Latency < found[j].Latency })
+	return found
+}
+
+// probeAPI calls GetNowBlock on the endpoint and records the answer.
+func probeAPI(endpoint *APIEndpoint, timeout time.Duration) bool {
+	var client TronClient
+	if endpoint.HTTP {
+		client = NewHTTPClient(endpoint.Address, WithTimeout(timeout))
+	} else {
+		client = NewGrpcClient(endpoint.Address, WithTimeout(timeout),
+			WithDialOptions(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	}
+	if err := client.Start(); err != nil {
+		return false
+	}
+	defer client.Stop()
+
+	start := time.Now()
+	block, err := client.GetNowBlock()
+	if err != nil || block.GetBlockHeader().GetRawData() == nil {
+		return false
+	}
+	endpoint.Latency = time.Since(start)
+	endpoint.Head = block.GetBlockHeader().GetRawData().GetNumber()
+	return true
+}
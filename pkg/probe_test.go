@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/p2p"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeWalletServer is a gRPC API answering GetNowBlock.
+type fakeWalletServer struct {
+	api.UnimplementedWalletServer
+	head int64
+}
+
+func (s *fakeWalletServer) GetNowBlock2(context.Context, *api.EmptyMessage) (*api.BlockExtention, error) {
+	return &api.BlockExtention{BlockHeader: &core.BlockHeader{RawData: &core.BlockHeaderRaw{Number: s.head}}}, nil
+}
+
+// port returns the port of a local address.
+func port(t *testing.T, addr string) int {
+	_, p, err := net.SplitHostPort(addr)
+	require.Nil(t, err)
+	n, err := strconv.Atoi(p)
+	require.Nil(t, err)
+	return n
+}
+
+func TestProbeAPIs(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	server := grpc.NewServer()
+	api.RegisterWalletServer(server, &fakeWalletServer{head: 42})
+	go func() { _ = server.Serve(ln) }()
+	defer server.Stop()
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wallet/getnowblock" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, `{"blockID":"0000000000000029aa","block_header":{"raw_data":{"number":41}}}`)
+	}))
+	defer httpServer.Close()
+	httpURL, err := url.Parse(httpServer.URL)
+	require.Nil(t, err)
+
+	// A port accepting connections without answering, and a closed one.
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer silent.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	closed.Close()
+
+	node := &p2p.Node{Host: "127.0.0.1", Port: p2p.DefaultPort}
+	start := time.Now()
+	found := ProbeAPIs([]*p2p.Node{node}, APIProbe{
+		GrpcPorts: []int{port(t, ln.Addr().String()), port(t, silent.Addr().String()), port(t, closed.Addr().String())},
+		HTTPPorts: []int{port(t, httpURL.Host), port(t, silent.Addr().String())},
+		Timeout:   300 * time.Millisecond,
+	})
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	require.Len(t, found, 2)
+	byAddress := make(map[string]APIEndpoint)
+	for _, endpoint := range found {
+		assert.Equal(t, node, endpoint.Node)
+		assert.Positive(t, endpoint.Latency)
+		byAddress[endpoint.Address] = endpoint
+	}
+	grpcAPI, ok := byAddress[ln.Addr().String()]
+	require.True(t, ok)
+	assert.False(t, grpcAPI.HTTP)
+	assert.Equal(t, int64(42), grpcAPI.Head)
+	httpAPI, ok := byAddress[httpServer.URL]
+	require.True(t, ok)
+	assert.True(t, httpAPI.HTTP)
+	assert.Equal(t, int64(41), httpAPI.Head)
+	assert.LessOrEqual(t, found[0].Latency, found[1].Latency)
+
+	// The addresses are ready for the clients.
+	client := NewHTTPClient(httpAPI.Address)
+	require.Nil(t, client.Start())
+	block, err := client.GetNowBlock()
+	require.Nil(t, err)
+	assert.Equal(t, int64(41), block.GetBlockHeader().GetRawData().GetNumber())
+}
@@ -0,0 +1,189 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// DefaultExpiration is the transaction lifetime used by java-tron when none is set.
+	DefaultExpiration = 60 * time.Second
+	// MaxExpiration is the longest transaction lifetime accepted by the network.
+	MaxExpiration = 24 * time.Hour
+)
+
+// BlockRef is a block used as the TaPoS reference of transactions.
+type BlockRef struct {
+	Num  int64
+	Hash []byte // block ID, 32 bytes
+}
+
+// RefBlockBytes returns bytes 6..8 of the big-endian block number.
+func (r *BlockRef) RefBlockBytes() []byte {
+	num := make([]byte, 8)
+	binary.BigEndian.PutUint64(num, uint64(r.Num))
+	return num[6:8]
+}
+
+// RefBlockHash returns bytes 8..16 of the block ID.
+func (r *BlockRef) RefBlockHash() []byte {
+	if len(r.Hash) < 16 {
+		return nil
+	}
+	return r.Hash[8:16]
+}
+
+// Apply sets the reference fields and the expiration on raw transaction data.
+func (r *BlockRef) Apply(raw *core.TransactionRaw, expiration time.Time) {
+	raw.RefBlockBytes = r.RefBlockBytes()
+	raw.RefBlockHash = r.RefBlockHash()
+	raw.Expiration = expiration.UnixMilli()
+}
+
+// BlockReferenceProvider fetches recent block references and caches them so that
+// many transactions can be built with a single node call.
+type BlockReferenceProvider struct {
+	client TronClient
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	ref       *BlockRef
+	fetchedAt time.Time
+}
+
+// NewBlockReferenceProvider creates a provider that refreshes its reference after ttl.
+// A ttl of 0 or less uses 30 seconds, well within the reference window of the network.
+func NewBlockReferenceProvider(client TronClient, ttl time.Duration) *BlockReferenceProvider {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &BlockReferenceProvider{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Reference returns the cached block reference, fetching a new one when it is stale.
+func (p *BlockReferenceProvider) Reference() (*BlockRef, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ref != nil && p.now().Sub(p.fetchedAt) < p.ttl {
+		return p.ref, nil
+	}
+	return p.refresh()
+}
+
+// Refresh fetches a new block reference regardless of the cache.
+func (p *BlockReferenceProvider) Refresh() (*BlockRef, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refresh()
+}
+
+// refresh fetches a reference from the Database service, falling back to the
+// head block when the node does not expose it. It must be called with mu held.
+func (p *BlockReferenceProvider) refresh() (*BlockRef, error) {
+	var ref *BlockRef
+
+	br, err := p.client.GetBlockReference()
+	switch {
+	case err == nil:
+		ref = &BlockRef{Num: br.GetBlockNum(), Hash: br.GetBlockHash()}
+	case status.Code(err) == codes.Unimplemented:
+		block, err := p.client.GetNowBlock()
+		if err != nil {
+			return nil, fmt.Errorf("BlockReference: %w", err)
+		}
+		ref = &BlockRef{Num: block.GetBlockHeader().GetRawData().GetNumber(), Hash: block.GetBlockid()}
+	default:
+		return nil, fmt.Errorf("BlockReference: %w", err)
+	}
+	if len(ref.Hash) != 32 {
+		return nil, fmt.Errorf("BlockReference: invalid block hash length %d", len(ref.Hash))
+	}
+
+	p.ref = ref
+	p.fetchedAt = p.now()
+	return ref, nil
+}
+
+// Prepare re-references a node-built transaction with the cached block and a custom
+// expiration, then updates its ID. The transaction must be signed afterwards.
+func (p *BlockReferenceProvider) Prepare(tx *api.TransactionExtention, expiration time.Duration) error {
+	if tx.GetTransaction().GetRawData() == nil {
+		return fmt.Errorf("Prepare: transaction has no raw data")
+	}
+	if expiration <= 0 || expiration > MaxExpiration {
+		return fmt.Errorf("Prepare: expiration must be between 0 and %s", MaxExpiration)
+	}
+	ref, err := p.Reference()
+	if err != nil {
+		return err
+	}
+
+	ref.Apply(tx.Transaction.RawData, p.now().Add(expiration))
+	txid, err := transactionID(tx.Transaction.RawData)
+	if err != nil {
+		return fmt.Errorf("Prepare: %w", err)
+	}
+	tx.Txid = txid
+	return nil
+}
+
+// NewTransaction builds an unsigned transaction locally from a contract, without
+// calling the node except to refresh the block reference. An expiration of 0 uses
+// DefaultExpiration.
+func (p *BlockReferenceProvider) NewTransaction(contractType core.Transaction_Contract_ContractType, contract proto.Message, feeLimit int64, expiration time.Duration) (*api.TransactionExtention, error) {
+	if expiration == 0 {
+		expiration = DefaultExpiration
+	}
+	if expiration < 0 || expiration > MaxExpiration {
+		return nil, fmt.Errorf("NewTransaction: expiration must be between 0 and %s", MaxExpiration)
+	}
+	param, err := anypb.New(contract)
+	if err != nil {
+		return nil, fmt.Errorf("NewTransaction: %w", err)
+	}
+	ref, err := p.Reference()
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	raw := &core.TransactionRaw{
+		Contract:  []*core.Transaction_Contract{{Type: contractType, Parameter: param}},
+		Timestamp: now.UnixMilli(),
+		FeeLimit:  feeLimit,
+	}
+	ref.Apply(raw, now.Add(expiration))
+
+	txid, err := transactionID(raw)
+	if err != nil {
+		return nil, fmt.Errorf("NewTransaction: %w", err)
+	}
+	return &api.TransactionExtention{
+		Transaction: &core.Transaction{RawData: raw},
+		Txid:        txid,
+		Result:      &api.Return{Result: true},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeReferenceClient struct {
+	TronClient
+	ref   *api.BlockReference
+	calls int
+}
+
+func (f *fakeReferenceClient) GetBlockReference() (*api.BlockReference, error) {
+	f.calls++
+	if f.ref == nil {
+		return nil, status.Error(codes.Unimplemented, "unknown service protocol.Database")
+	}
+	return f.ref, nil
+}
+
+func (f *fakeReferenceClient) GetNowBlock() (*api.BlockExtention, error) {
+	hash, _ := hex.DecodeString("0000000003a1d9b6c43d0cf4b0d3b7a7e4f5b9f3e0d1c2b3a4958677a8b9cadb")
+	return &api.BlockExtention{
+		Blockid:     hash,
+		BlockHeader: &core.BlockHeader{RawData: &core.BlockHeaderRaw{Number: 0x3a1d9b6}},
+	}, nil
+}
+
+func TestBlockRef(t *testing.T) {
+	hash, _ := hex.DecodeString("0000000003a1d9b6c43d0cf4b0d3b7a7e4f5b9f3e0d1c2b3a4958677a8b9cadb")
+	ref := &BlockRef{Num: 0x3a1d9b6, Hash: hash}
+
+	assert.Equal(t, "d9b6", hex.EncodeToString(ref.RefBlockBytes()))
+	assert.Equal(t, "c43d0cf4b0d3b7a7", hex.EncodeToString(ref.RefBlockHash()))
+}
+
+func TestBlockReferenceProvider(t *testing.T) {
+	hash, _ := hex.DecodeString("0000000003a1d9b6c43d0cf4b0d3b7a7e4f5b9f3e0d1c2b3a4958677a8b9cadb")
+	client := &fakeReferenceClient{ref: &api.BlockReference{BlockNum: 0x3a1d9b6, BlockHash: hash}}
+	now := time.UnixMilli(1_700_000_000_000)
+	p := NewBlockReferenceProvider(client, time.Minute)
+	p.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		tx, err := p.NewTransaction(core.Transaction_Contract_TransferContract, &core.TransferContract{Amount: int64(i)}, 0, time.Hour)
+		require.Nil(t, err)
+		assert.Equal(t, now.Add(time.Hour).UnixMilli(), tx.Transaction.RawData.Expiration)
+		assert.Equal(t, "d9b6", hex.EncodeToString(tx.Transaction.RawData.RefBlockBytes))
+		assert.Len(t, tx.Txid, 32)
+	}
+	assert.Equal(t, 1, client.calls)
+
+	_, err := p.NewTransaction(core.Transaction_Contract_TransferContract, &core.TransferContract{}, 0, 25*time.Hour)
+	assert.NotNil(t, err)
+}
+
+func TestBlockReferenceProviderFallback(t *testing.T) {
+	p := NewBlockReferenceProvider(&fakeReferenceClient{}, 0)
+
+	ref, err := p.Reference()
+	require.Nil(t, err)
+	assert.Equal(t, int64(0x3a1d9b6), ref.Num)
+}
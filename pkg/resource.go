@@ -120,6 +120,30 @@ func (g *GrpcClient) UnfreezeAsset(from string) (*api.TransactionExtention, erro
 	return tx, nil
 }
 
+// FreezeBalanceV2 stakes TRX for bandwidth or energy (Stake 2.0).
+func (g *GrpcClient) FreezeBalanceV2(from string, resource core.ResourceCode, frozenBalance int64) (*api.TransactionExtention, error) {
+	contract := &core.FreezeBalanceV2Contract{}
+	var err error
+
+	if contract.OwnerAddress, err = base58.DecodeCheck(from); err != nil {
+		return nil, fmt.Errorf("FreezeBalanceV2: failed to decode from address: %w", err)
+	}
+	contract.FrozenBalance = frozenBalance
+	contract.Resource = resource
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.FreezeBalanceV2(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("FreezeBalanceV2 RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
 // UnfreezeBalanceV2 unfreezes TRX (new version).
 func (g *GrpcClient) UnfreezeBalanceV2(from string, resource core.ResourceCode, unfreezeBalance int64) (*api.TransactionExtention, error) {
 	contract := &core.UnfreezeBalanceV2Contract{}
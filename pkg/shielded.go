@@ -9,7 +9,10 @@
 package pkg
 
 import (
+	"fmt"
+
 	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
 )
 
 // GetSpendingKey retrieves a spending key.
@@ -20,36 +23,56 @@ func (g *GrpcClient) GetSpendingKey() (*api.BytesMessage, error) {
 	return g.Client.GetSpendingKey(ctx, new(api.EmptyMessage))
 }
 
-// GetExpandedSpendingKey retrieves the expanded spending key.
+// GetExpandedSpendingKey expands a hex-encoded spending key into ask, nsk and ovk.
 func (g *GrpcClient) GetExpandedSpendingKey(key string) (*api.ExpandedSpendingKeyMessage, error) {
+	sk, err := shieldedKeyBytes("spending key", key)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := g.getContext()
 	defer cancel()
 
-	return g.Client.GetExpandedSpendingKey(ctx, &api.BytesMessage{Value: []byte(key)})
+	return g.Client.GetExpandedSpendingKey(ctx, &api.BytesMessage{Value: sk})
 }
 
-// GetAkFromAsk retrieves `Ak` from `Ask`.
-func (g *GrpcClient) GetAkFromAsk(ak string) (*api.BytesMessage, error) {
+// GetAkFromAsk retrieves `Ak` from a hex-encoded `Ask`.
+func (g *GrpcClient) GetAkFromAsk(ask string) (*api.BytesMessage, error) {
+	askBytes, err := shieldedKeyBytes("ask", ask)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := g.getContext()
 	defer cancel()
 
-	return g.Client.GetAkFromAsk(ctx, &api.BytesMessage{Value: []byte(ak)})
+	return g.Client.GetAkFromAsk(ctx, &api.BytesMessage{Value: askBytes})
 }
 
-// GetNkFromNsk retrieves `Nk` from `Nsk`.
-func (g *GrpcClient) GetNkFromNsk(nk string) (*api.BytesMessage, error) {
+// GetNkFromNsk retrieves `Nk` from a hex-encoded `Nsk`.
+func (g *GrpcClient) GetNkFromNsk(nsk string) (*api.BytesMessage, error) {
+	nskBytes, err := shieldedKeyBytes("nsk", nsk)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := g.getContext()
 	defer cancel()
 
-	return g.Client.GetNkFromNsk(ctx, &api.BytesMessage{Value: []byte(nk)})
+	return g.Client.GetNkFromNsk(ctx, &api.BytesMessage{Value: nskBytes})
 }
 
-// GetIncomingViewingKey retrieves an incoming viewing key.
+// GetIncomingViewingKey retrieves the incoming viewing key of hex-encoded `Ak` and `Nk`.
 func (g *GrpcClient) GetIncomingViewingKey(ak, nk string) (*api.IncomingViewingKeyMessage, error) {
+	vk, err := newViewingKeyMessage(ak, nk)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := g.getContext()
 	defer cancel()
 
-	return g.Client.GetIncomingViewingKey(ctx, &api.ViewingKeyMessage{Ak: []byte(ak), Nk: []byte(nk)})
+	return g.Client.GetIncomingViewingKey(ctx, vk)
 }
 
 // GetDiversifier retrieves a diversifier message.
@@ -68,6 +91,48 @@ func (g *GrpcClient) GetRcm() (*api.BytesMessage, error) {
 	return g.Client.GetRcm(ctx, new(api.EmptyMessage))
 }
 
+// GetZenPaymentAddress retrieves the payment address of a hex-encoded incoming viewing key and diversifier.
+func (g *GrpcClient) GetZenPaymentAddress(ivk, d string) (*api.PaymentAddressMessage, error) {
+	msg, err := newIvkDiversifierMessage(ivk, d)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.GetZenPaymentAddress(ctx, msg)
+}
+
+// newViewingKeyMessage decodes hex-encoded `Ak` and `Nk`.
+func newViewingKeyMessage(ak, nk string) (*api.ViewingKeyMessage, error) {
+	akBytes, err := shieldedKeyBytes("ak", ak)
+	if err != nil {
+		return nil, err
+	}
+	nkBytes, err := shieldedKeyBytes("nk", nk)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ViewingKeyMessage{Ak: akBytes, Nk: nkBytes}, nil
+}
+
+// newIvkDiversifierMessage decodes a hex-encoded incoming viewing key and diversifier.
+func newIvkDiversifierMessage(ivk, d string) (*api.IncomingViewingKeyDiversifierMessage, error) {
+	ivkBytes, err := shieldedKeyBytes("incoming viewing key", ivk)
+	if err != nil {
+		return nil, err
+	}
+	dBytes, err := shieldedKeyBytes("diversifier", d)
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncomingViewingKeyDiversifierMessage{
+		Ivk: &api.IncomingViewingKeyMessage{Ivk: ivkBytes},
+		D:   &api.DiversifierMessage{D: dBytes},
+	}, nil
+}
+
 // GetNewShieldedAddress generates a new shielded address.
 func (g *GrpcClient) GetNewShieldedAddress() (*api.ShieldedAddressInfo, error) {
 	ctx, cancel := g.getContext()
@@ -75,3 +140,102 @@ func (g *GrpcClient) GetNewShieldedAddress() (*api.ShieldedAddressInfo, error) {
 
 	return g.Client.GetNewShieldedAddress(ctx, new(api.EmptyMessage))
 }
+
+// CreateShieldedContractParameters builds the parameters of a shielded TRC-20 mint, transfer or burn,
+// signing the spends with the spend authorizing key.
+func (g *GrpcClient) CreateShieldedContractParameters(params *api.PrivateShieldedTRC20Parameters) (*api.ShieldedTRC20Parameters, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.CreateShieldedContractParameters(ctx, params)
+}
+
+// CreateShieldedContractParametersWithoutAsk builds the parameters of a shielded TRC-20 transaction
+// whose spends are signed separately.
+func (g *GrpcClient) CreateShieldedContractParametersWithoutAsk(params *api.PrivateShieldedTRC20ParametersWithoutAsk) (*api.ShieldedTRC20Parameters, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.CreateShieldedContractParametersWithoutAsk(ctx, params)
+}
+
+// ScanShieldedTRC20NotesByIvk scans a block range for the shielded TRC-20 notes received with an incoming viewing key.
+func (g *GrpcClient) ScanShieldedTRC20NotesByIvk(params *api.IvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.ScanShieldedTRC20NotesByIvk(ctx, params)
+}
+
+// ScanShieldedTRC20NotesByOvk scans a block range for the shielded TRC-20 notes sent with an outgoing viewing key.
+func (g *GrpcClient) ScanShieldedTRC20NotesByOvk(params *api.OvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.ScanShieldedTRC20NotesByOvk(ctx, params)
+}
+
+// IsShieldedTRC20ContractNoteSpent checks whether a shielded TRC-20 note has been spent.
+func (g *GrpcClient) IsShieldedTRC20ContractNoteSpent(params *api.NfTRC20Parameters) (*api.NullifierResult, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.IsShieldedTRC20ContractNoteSpent(ctx, params)
+}
+
+// GetTriggerInputForShieldedTRC20Contract encodes the contract call input of shielded TRC-20
+// parameters built without the spend authorizing key, once the spends are signed.
+func (g *GrpcClient) GetTriggerInputForShieldedTRC20Contract(params *api.ShieldedTRC20TriggerContractParameters) (*api.BytesMessage, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.GetTriggerInputForShieldedTRC20Contract(ctx, params)
+}
+
+// CreateShieldedTransaction builds a shielded TRX transfer. Transfers from a
+// transparent address must still be signed by its owner.
+func (g *GrpcClient) CreateShieldedTransaction(params *api.PrivateParameters) (*api.TransactionExtention, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.CreateShieldedTransaction(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("CreateShieldedTransaction RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetMerkleTreeVoucherInfo retrieves the Merkle vouchers and paths of shielded notes, needed to spend them.
+func (g *GrpcClient) GetMerkleTreeVoucherInfo(points *core.OutputPointInfo) (*core.IncrementalMerkleVoucherInfo, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.GetMerkleTreeVoucherInfo(ctx, points)
+}
+
+// ScanNoteByIvk scans a block range for the shielded TRX notes received with an incoming viewing key.
+func (g *GrpcClient) ScanNoteByIvk(params *api.IvkDecryptParameters) (*api.DecryptNotes, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.ScanNoteByIvk(ctx, params)
+}
+
+// ScanNoteByOvk scans a block range for the shielded TRX notes sent with an outgoing viewing key.
+func (g *GrpcClient) ScanNoteByOvk(params *api.OvkDecryptParameters) (*api.DecryptNotes, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.ScanNoteByOvk(ctx, params)
+}
+
+// IsSpend checks whether the nullifier of a shielded TRX note has been revealed, i.e. the note is spent.
+func (g *GrpcClient) IsSpend(params *api.NoteParameters) (*api.SpendResult, error) {
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	return g.Client.IsSpend(ctx, params)
+}
@@ -0,0 +1,273 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/bech32"
+	hexutil "github.com/dszi/go-tron/common/hexutil"
+	"github.com/dszi/go-tron/pb/api"
+)
+
+// PaymentAddressHRP is the bech32 human-readable part of shielded payment addresses.
+const PaymentAddressHRP = "ztron"
+
+// diversifierLength is the length of a diversifier, and of the first part of a payment address.
+const diversifierLength = 11
+
+// SpendingKey is the root key of a shielded account, from which all other keys are derived.
+type SpendingKey [32]byte
+
+// ParseSpendingKey parses a hex-encoded spending key.
+func ParseSpendingKey(s string) (SpendingKey, error) {
+	return parseKey32("spending key", s)
+}
+
+// String returns the key in hex.
+func (k SpendingKey) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// ExpandedSpendingKey holds the keys expanded from a spending key: the spend
+// authorizing key, the proof authorizing key and the outgoing viewing key.
+type ExpandedSpendingKey struct {
+	Ask [32]byte
+	Nsk [32]byte
+	Ovk [32]byte
+}
+
+// ParseExpandedSpendingKey parses the hex-encoded ask, nsk and ovk.
+func ParseExpandedSpendingKey(ask, nsk, ovk string) (ExpandedSpendingKey, error) {
+	var (
+		k   ExpandedSpendingKey
+		err error
+	)
+	if k.Ask, err = parseKey32("ask", ask); err != nil {
+		return k, err
+	}
+	if k.Nsk, err = parseKey32("nsk", nsk); err != nil {
+		return k, err
+	}
+	k.Ovk, err = parseKey32("ovk", ovk)
+	return k, err
+}
+
+// IncomingViewingKey decrypts the notes received by the payment addresses of an account.
+type IncomingViewingKey [32]byte
+
+// ParseIncomingViewingKey parses a hex-encoded incoming viewing key.
+func ParseIncomingViewingKey(s string) (IncomingViewingKey, error) {
+	return parseKey32("incoming viewing key", s)
+}
+
+// String returns the key in hex.
+func (k IncomingViewingKey) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// Diversifier selects one of the payment addresses of an incoming viewing key.
+type Diversifier [diversifierLength]byte
+
+// PaymentAddress is a shielded address, encoded in bech32 as "ztron1...".
+type PaymentAddress struct {
+	Diversifier Diversifier
+	PkD         [32]byte // diversified transmission key
+}
+
+// ParsePaymentAddress parses a bech32 payment address.
+func ParsePaymentAddress(s string) (PaymentAddress, error) {
+	var a PaymentAddress
+	hrp, data, err := bech32.Decode(s)
+	if err != nil {
+		return a, fmt.Errorf("invalid payment address: %w", err)
+	}
+	if hrp != PaymentAddressHRP {
+		return a, fmt.Errorf("invalid payment address: prefix %q, expected %q", hrp, PaymentAddressHRP)
+	}
+	raw, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return a, fmt.Errorf("invalid payment address: %w", err)
+	}
+	if len(raw) != diversifierLength+32 {
+		return a, fmt.Errorf("invalid payment address: %d bytes, expected %d", len(raw), diversifierLength+32)
+	}
+	copy(a.Diversifier[:], raw[:diversifierLength])
+	copy(a.PkD[:], raw[diversifierLength:])
+	return a, nil
+}
+
+// Bytes returns the diversifier followed by pkD.
+func (a PaymentAddress) Bytes() []byte {
+	return append(append([]byte{}, a.Diversifier[:]...), a.PkD[:]...)
+}
+
+// String returns the bech32 encoding of the address.
+func (a PaymentAddress) String() string {
+	data, err := bech32.ConvertBits(a.Bytes(), 8, 5, true)
+	if err != nil {
+		return ""
+	}
+	s, err := bech32.Encode(PaymentAddressHRP, data)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// ShieldedKeys holds the keys of a shielded account and its default payment address.
+type ShieldedKeys struct {
+	SpendingKey SpendingKey
+	ExpandedSpendingKey
+	Ak      [32]byte
+	Nk      [32]byte
+	Ivk     IncomingViewingKey
+	Address PaymentAddress
+}
+
+// TRC20Keys returns the keys for a ShieldedTRC20Wallet.
+func (k *ShieldedKeys) TRC20Keys() ShieldedTRC20Keys {
+	return ShieldedTRC20Keys{
+		Ask: k.Ask[:],
+		Nsk: k.Nsk[:],
+		Ovk: k.Ovk[:],
+		Ak:  k.Ak[:],
+		Nk:  k.Nk[:],
+		Ivk: k.Ivk[:],
+	}
+}
+
+// NewShieldedKeys generates a spending key on the node and derives the keys and payment address from it.
+func NewShieldedKeys(client TronClient) (*ShieldedKeys, error) {
+	sk, err := client.GetSpendingKey()
+	if err != nil {
+		return nil, fmt.Errorf("NewShieldedKeys: %w", err)
+	}
+	key, err := bytesKey32("spending key", sk.GetValue())
+	if err != nil {
+		return nil, fmt.Errorf("NewShieldedKeys: %w", err)
+	}
+	return DeriveShieldedKeys(client, key)
+}
+
+// DeriveShieldedKeys derives the keys of a spending key and a payment address
+// with a new diversifier, with the node: sk → (ask, nsk, ovk) → (ak, nk) → ivk → address.
+func DeriveShieldedKeys(client TronClient, sk SpendingKey) (*ShieldedKeys, error) {
+	keys := &ShieldedKeys{SpendingKey: sk}
+
+	expanded, err := client.GetExpandedSpendingKey(sk.String())
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: expanded spending key: %w", err)
+	}
+	if keys.Ask, err = bytesKey32("ask", expanded.GetAsk()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+	if keys.Nsk, err = bytesKey32("nsk", expanded.GetNsk()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+	if keys.Ovk, err = bytesKey32("ovk", expanded.GetOvk()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+
+	ak, err := client.GetAkFromAsk(hex.EncodeToString(keys.Ask[:]))
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: ak: %w", err)
+	}
+	if keys.Ak, err = bytesKey32("ak", ak.GetValue()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+
+	nk, err := client.GetNkFromNsk(hex.EncodeToString(keys.Nsk[:]))
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: nk: %w", err)
+	}
+	if keys.Nk, err = bytesKey32("nk", nk.GetValue()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+
+	ivk, err := client.GetIncomingViewingKey(hex.EncodeToString(keys.Ak[:]), hex.EncodeToString(keys.Nk[:]))
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: incoming viewing key: %w", err)
+	}
+	if keys.Ivk, err = bytesKey32("incoming viewing key", ivk.GetIvk()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+
+	d, err := client.GetDiversifier()
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: diversifier: %w", err)
+	}
+	addr, err := client.GetZenPaymentAddress(keys.Ivk.String(), hex.EncodeToString(d.GetD()))
+	if err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: payment address: %w", err)
+	}
+	if keys.Address, err = ParsePaymentAddress(addr.GetPaymentAddress()); err != nil {
+		return nil, fmt.Errorf("DeriveShieldedKeys: %w", err)
+	}
+	return keys, nil
+}
+
+// ShieldedKeysFromAddressInfo converts the result of GetNewShieldedAddress.
+func ShieldedKeysFromAddressInfo(info *api.ShieldedAddressInfo) (*ShieldedKeys, error) {
+	var (
+		keys = new(ShieldedKeys)
+		err  error
+	)
+	fields := []struct {
+		name string
+		dst  *[32]byte
+		src  []byte
+	}{
+		{"spending key", (*[32]byte)(&keys.SpendingKey), info.GetSk()},
+		{"ask", &keys.Ask, info.GetAsk()},
+		{"nsk", &keys.Nsk, info.GetNsk()},
+		{"ovk", &keys.Ovk, info.GetOvk()},
+		{"ak", &keys.Ak, info.GetAk()},
+		{"nk", &keys.Nk, info.GetNk()},
+		{"incoming viewing key", (*[32]byte)(&keys.Ivk), info.GetIvk()},
+	}
+	for _, f := range fields {
+		if *f.dst, err = bytesKey32(f.name, f.src); err != nil {
+			return nil, err
+		}
+	}
+	if keys.Address, err = ParsePaymentAddress(info.GetPaymentAddress()); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// shieldedKeyBytes decodes a hex-encoded key passed to a shielded RPC.
+func shieldedKeyBytes(name, key string) ([]byte, error) {
+	b, err := hexutil.FromHex(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// parseKey32 parses a hex-encoded 32-byte key.
+func parseKey32(name, s string) ([32]byte, error) {
+	b, err := shieldedKeyBytes(name, s)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return bytesKey32(name, b)
+}
+
+// bytesKey32 checks the length of a 32-byte key.
+func bytesKey32(name string, b []byte) ([32]byte, error) {
+	var k [32]byte
+	if len(b) != len(k) {
+		return k, fmt.Errorf("invalid %s: %d bytes, expected %d", name, len(b), len(k))
+	}
+	copy(k[:], b)
+	return k, nil
+}
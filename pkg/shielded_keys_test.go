@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeyClient derives each key by filling it with the first byte of its input plus one,
+// and fails on inputs that are not 32-byte keys, as the node does.
+type fakeKeyClient struct {
+	TronClient
+	address PaymentAddress
+}
+
+func fill(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+func (f *fakeKeyClient) decode(t string, s string) ([]byte, error) {
+	b, err := shieldedKeyBytes(t, s)
+	if err != nil || len(b) != 32 {
+		return nil, assert.AnError
+	}
+	return b, nil
+}
+
+func (f *fakeKeyClient) GetSpendingKey() (*api.BytesMessage, error) {
+	return &api.BytesMessage{Value: fill(1)}, nil
+}
+
+func (f *fakeKeyClient) GetExpandedSpendingKey(key string) (*api.ExpandedSpendingKeyMessage, error) {
+	sk, err := f.decode("sk", key)
+	if err != nil {
+		return nil, err
+	}
+	return &api.ExpandedSpendingKeyMessage{Ask: fill(sk[0] + 1), Nsk: fill(sk[0] + 2), Ovk: fill(sk[0] + 3)}, nil
+}
+
+func (f *fakeKeyClient) GetAkFromAsk(ask string) (*api.BytesMessage, error) {
+	b, err := f.decode("ask", ask)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BytesMessage{Value: fill(b[0] + 10)}, nil
+}
+
+func (f *fakeKeyClient) GetNkFromNsk(nsk string) (*api.BytesMessage, error) {
+	b, err := f.decode("nsk", nsk)
+	if err != nil {
+		return nil, err
+	}
+	return &api.BytesMessage{Value: fill(b[0] + 10)}, nil
+}
+
+func (f *fakeKeyClient) GetIncomingViewingKey(ak, nk string) (*api.IncomingViewingKeyMessage, error) {
+	a, err := f.decode("ak", ak)
+	if err != nil {
+		return nil, err
+	}
+	n, err := f.decode("nk", nk)
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncomingViewingKeyMessage{Ivk: fill(a[0] + n[0])}, nil
+}
+
+func (f *fakeKeyClient) GetDiversifier() (*api.DiversifierMessage, error) {
+	return &api.DiversifierMessage{D: f.address.Diversifier[:]}, nil
+}
+
+func (f *fakeKeyClient) GetZenPaymentAddress(ivk, d string) (*api.PaymentAddressMessage, error) {
+	if _, err := f.decode("ivk", ivk); err != nil {
+		return nil, err
+	}
+	if d != hex.EncodeToString(f.address.Diversifier[:]) {
+		return nil, assert.AnError
+	}
+	return &api.PaymentAddressMessage{PaymentAddress: f.address.String()}, nil
+}
+
+func TestPaymentAddress(t *testing.T) {
+	var addr PaymentAddress
+	copy(addr.Diversifier[:], "diversifier")
+	copy(addr.PkD[:], fill(7))
+
+	s := addr.String()
+	assert.True(t, strings.HasPrefix(s, "ztron1"))
+	parsed, err := ParsePaymentAddress(s)
+	require.Nil(t, err)
+	assert.Equal(t, addr, parsed)
+
+	_, err = ParsePaymentAddress(s[:len(s)-1] + "q")
+	assert.Error(t, err)
+	_, err = ParsePaymentAddress("TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9")
+	assert.Error(t, err)
+}
+
+func TestParseShieldedKeys(t *testing.T) {
+	sk, err := ParseSpendingKey("0x" + strings.Repeat("ab", 32))
+	require.Nil(t, err)
+	assert.Equal(t, strings.Repeat("ab", 32), sk.String())
+
+	_, err = ParseSpendingKey(strings.Repeat("ab", 31))
+	assert.ErrorContains(t, err, "31 bytes, expected 32")
+	_, err = ParseIncomingViewingKey("zz")
+	assert.ErrorContains(t, err, "invalid incoming viewing key")
+	_, err = ParseExpandedSpendingKey(strings.Repeat("01", 32), strings.Repeat("02", 32), "")
+	assert.ErrorContains(t, err, "invalid ovk")
+}
+
+func TestDeriveShieldedKeys(t *testing.T) {
+	client := &fakeKeyClient{}
+	copy(client.address.Diversifier[:], "diversifier")
+	copy(client.address.PkD[:], fill(9))
+
+	keys, err := NewShieldedKeys(client)
+	require.Nil(t, err)
+	assert.Equal(t, SpendingKey(*(*[32]byte)(fill(1))), keys.SpendingKey)
+	assert.Equal(t, fill(2), keys.Ask[:])
+	assert.Equal(t, fill(3), keys.Nsk[:])
+	assert.Equal(t, fill(4), keys.Ovk[:])
+	assert.Equal(t, fill(12), keys.Ak[:])
+	assert.Equal(t, fill(13), keys.Nk[:])
+	assert.Equal(t, fill(25), keys.Ivk[:])
+	assert.Equal(t, client.address, keys.Address)
+
+	trc20 := keys.TRC20Keys()
+	assert.Equal(t, fill(25), trc20.Ivk)
+	assert.Equal(t, fill(2), trc20.Ask)
+}
+
+func TestShieldedKeysFromAddressInfo(t *testing.T) {
+	var addr PaymentAddress
+	copy(addr.PkD[:], fill(8))
+	info := &api.ShieldedAddressInfo{
+		Sk: fill(1), Ask: fill(2), Nsk: fill(3), Ovk: fill(4), Ak: fill(5), Nk: fill(6), Ivk: fill(7),
+		D: addr.Diversifier[:], PkD: addr.PkD[:], PaymentAddress: addr.String(),
+	}
+	keys, err := ShieldedKeysFromAddressInfo(info)
+	require.Nil(t, err)
+	assert.Equal(t, fill(7), keys.Ivk[:])
+	assert.Equal(t, addr, keys.Address)
+
+	info.Nk = fill(6)[:20]
+	_, err = ShieldedKeysFromAddressInfo(info)
+	assert.ErrorContains(t, err, "invalid nk")
+}
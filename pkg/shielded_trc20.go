@@ -0,0 +1,539 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pkg/abi"
+)
+
+// Methods of the shielded TRC-20 contract called with the parameters built by the node.
+const (
+	shieldedMintMethod     = "mint(uint256,bytes32[9],bytes32[2],bytes32[21])"
+	shieldedTransferMethod = "transfer(bytes32[10][],bytes32[2][],bytes32[9][],bytes32[2],bytes32[21][])"
+	shieldedBurnMethod     = "burn(bytes32[10],bytes32[2],uint256,bytes32[2],address,bytes32[3],bytes32[9][],bytes32[21][])"
+)
+
+// shieldedScanMaxBlocks is the largest block range the node scans for notes in one request.
+const shieldedScanMaxBlocks = 1000
+
+// shieldedPathLength is the depth of the note commitment tree of the shielded TRC-20 contract.
+const shieldedPathLength = 32
+
+// ShieldedTRC20Keys holds the keys of a shielded TRC-20 wallet, as raw bytes.
+// Ask may be left empty for a wallet whose spends are signed elsewhere; Ak, Nk
+// and Ivk are required to scan notes and check their spent status.
+type ShieldedTRC20Keys struct {
+	Ask []byte // spend authorizing key
+	Nsk []byte // proof authorizing key
+	Ovk []byte // outgoing viewing key
+	Ak  []byte
+	Nk  []byte
+	Ivk []byte // incoming viewing key
+}
+
+// ShieldedTRC20Note is a note received by the wallet.
+type ShieldedTRC20Note struct {
+	Note     *api.Note
+	Position int64 // leaf index in the note commitment tree
+	TxID     []byte
+	Index    int32
+	Spent    bool
+}
+
+// Value returns the value of the note, in units of the token amount divided by the scaling factor.
+func (n *ShieldedTRC20Note) Value() int64 {
+	return n.Note.GetValue()
+}
+
+// ShieldedTRC20Output is a note to create, in units of the token amount divided by the scaling factor.
+type ShieldedTRC20Output struct {
+	PaymentAddress string // ztron1... shielded address
+	Value          int64
+	Memo           []byte
+}
+
+// ShieldedTRC20Call is a mint, transfer or burn ready to be sent to the shielded contract.
+type ShieldedTRC20Call struct {
+	Method string // contract method signature
+	Params *api.ShieldedTRC20Parameters
+	// Input is the ABI-encoded method arguments. It is empty for spends built
+	// without the spend authorizing key until Finalize is called.
+	Input []byte
+	// Spends lists the notes consumed by the call, and Alphas the randomizers
+	// their spend authority signatures are computed with.
+	Spends []*ShieldedTRC20Note
+	Alphas [][]byte
+	// Amount and TransparentTo are the token amount and base58 recipient of a burn.
+	Amount        string
+	TransparentTo string
+}
+
+// Data returns the call data of the contract call: the method selector followed by the input.
+func (c *ShieldedTRC20Call) Data() []byte {
+	data, _ := abi.Pack(c.Method, nil)
+	return append(data, c.Input...)
+}
+
+// ShieldedTRC20Wallet builds mint, transfer and burn calls of a shielded TRC-20 contract
+// and keeps track of the notes received with its incoming viewing key.
+type ShieldedTRC20Wallet struct {
+	client   TronClient
+	contract string
+	token    string
+	keys     ShieldedTRC20Keys
+
+	mu            sync.Mutex
+	scalingFactor *big.Int
+	notes         map[string]*ShieldedTRC20Note
+}
+
+// NewShieldedTRC20Wallet creates a wallet for the shielded contract wrapping the TRC-20 token.
+func NewShieldedTRC20Wallet(client TronClient, contractAddress, tokenAddress string, keys ShieldedTRC20Keys) (*ShieldedTRC20Wallet, error) {
+	if _, err := base58.DecodeCheck(contractAddress); err != nil {
+		return nil, fmt.Errorf("invalid shielded contract address: %w", err)
+	}
+	if _, err := base58.DecodeCheck(tokenAddress); err != nil {
+		return nil, fmt.Errorf("invalid token address: %w", err)
+	}
+	return &ShieldedTRC20Wallet{
+		client:   client,
+		contract: contractAddress,
+		token:    tokenAddress,
+		keys:     keys,
+		notes:    make(map[string]*ShieldedTRC20Note),
+	}, nil
+}
+
+// contractBytes returns the address of the shielded contract with its prefix.
+func (w *ShieldedTRC20Wallet) contractBytes() []byte {
+	b, _ := base58.DecodeCheck(w.contract)
+	return b
+}
+
+// call calls a constant method of the shielded contract and returns its result.
+func (w *ShieldedTRC20Wallet) call(method string, param []abi.Param) ([]byte, error) {
+	data, err := abi.Pack(method, param)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := w.client.TriggerConstantContract(w.contract, w.contract, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	if len(tx.GetConstantResult()) == 0 {
+		return nil, fmt.Errorf("%s: empty result", method)
+	}
+	return tx.GetConstantResult()[0], nil
+}
+
+// ScalingFactor returns the number of token units represented by one unit of note value.
+// It is read from the contract once.
+func (w *ShieldedTRC20Wallet) ScalingFactor() (*big.Int, error) {
+	w.mu.Lock()
+	factor := w.scalingFactor
+	w.mu.Unlock()
+	if factor != nil {
+		return factor, nil
+	}
+
+	result, err := w.call("scalingFactor()", nil)
+	if err != nil {
+		return nil, err
+	}
+	factor = new(big.Int).SetBytes(result)
+	if factor.Sign() == 0 {
+		return nil, fmt.Errorf("invalid scaling factor 0")
+	}
+	w.mu.Lock()
+	w.scalingFactor = factor
+	w.mu.Unlock()
+	return factor, nil
+}
+
+// tokenAmount converts a note value to a token amount.
+func (w *ShieldedTRC20Wallet) tokenAmount(value int64) (*big.Int, error) {
+	if value <= 0 {
+		return nil, fmt.Errorf("value must be positive")
+	}
+	factor, err := w.ScalingFactor()
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Mul(big.NewInt(value), factor), nil
+}
+
+// Approve allows the shielded contract to take the token amount of a mint of value from the sender.
+func (w *ShieldedTRC20Wallet) Approve(from string, value, feeLimit int64) (*api.TransactionExtention, error) {
+	amount, err := w.tokenAmount(value)
+	if err != nil {
+		return nil, fmt.Errorf("Approve: %w", err)
+	}
+	jsonString := fmt.Sprintf(`[{"address":"%s"},{"uint256":"%s"}]`, w.contract, amount)
+	return w.client.TriggerContract(from, w.token, "approve(address,uint256)", jsonString, feeLimit, 0, "", 0)
+}
+
+// Mint builds a call that converts tokens of the sender into a shielded note.
+// The shielded contract must first be allowed to take the tokens with Approve.
+func (w *ShieldedTRC20Wallet) Mint(output ShieldedTRC20Output) (*ShieldedTRC20Call, error) {
+	amount, err := w.tokenAmount(output.Value)
+	if err != nil {
+		return nil, fmt.Errorf("Mint: %w", err)
+	}
+	receive, err := w.receiveNote(output)
+	if err != nil {
+		return nil, fmt.Errorf("Mint: %w", err)
+	}
+
+	params, err := w.client.CreateShieldedContractParameters(&api.PrivateShieldedTRC20Parameters{
+		Ovk:                           w.keys.Ovk,
+		FromAmount:                    amount.String(),
+		ShieldedReceives:              []*api.ReceiveNote{receive},
+		Shielded_TRC20ContractAddress: w.contractBytes(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Mint: %w", err)
+	}
+	return w.newCall(shieldedMintMethod, params, nil, nil)
+}
+
+// Transfer builds a call that spends one or two notes into one or two new notes.
+// The values of the spent notes and of the outputs must be equal.
+func (w *ShieldedTRC20Wallet) Transfer(spends []*ShieldedTRC20Note, outputs []ShieldedTRC20Output) (*ShieldedTRC20Call, error) {
+	if len(spends) < 1 || len(spends) > 2 {
+		return nil, fmt.Errorf("Transfer: expected 1 or 2 notes to spend but got %d", len(spends))
+	}
+	if len(outputs) < 1 || len(outputs) > 2 {
+		return nil, fmt.Errorf("Transfer: expected 1 or 2 outputs but got %d", len(outputs))
+	}
+	var in, out int64
+	for _, n := range spends {
+		in += n.Value()
+	}
+	for _, o := range outputs {
+		if o.Value <= 0 {
+			return nil, fmt.Errorf("Transfer: output value must be positive")
+		}
+		out += o.Value
+	}
+	if in != out {
+		return nil, fmt.Errorf("Transfer: spent value %d does not match output value %d", in, out)
+	}
+
+	receives := make([]*api.ReceiveNote, len(outputs))
+	for i, o := range outputs {
+		r, err := w.receiveNote(o)
+		if err != nil {
+			return nil, fmt.Errorf("Transfer: %w", err)
+		}
+		receives[i] = r
+	}
+	call, err := w.spend(shieldedTransferMethod, spends, receives, "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("Transfer: %w", err)
+	}
+	return call, nil
+}
+
+// Burn builds a call that spends a note to send value to a transparent address,
+// with the rest of the note going to an optional change output.
+func (w *ShieldedTRC20Wallet) Burn(spend *ShieldedTRC20Note, to string, value int64, change *ShieldedTRC20Output) (*ShieldedTRC20Call, error) {
+	toDesc, err := base58.DecodeCheck(to)
+	if err != nil {
+		return nil, fmt.Errorf("Burn: invalid recipient address: %w", err)
+	}
+	amount, err := w.tokenAmount(value)
+	if err != nil {
+		return nil, fmt.Errorf("Burn: %w", err)
+	}
+	var receives []*api.ReceiveNote
+	rest := spend.Value() - value
+	if change != nil {
+		if change.Value != rest {
+			return nil, fmt.Errorf("Burn: change value %d does not match the remaining value %d", change.Value, rest)
+		}
+		r, err := w.receiveNote(*change)
+		if err != nil {
+			return nil, fmt.Errorf("Burn: %w", err)
+		}
+		receives = append(receives, r)
+	} else if rest != 0 {
+		return nil, fmt.Errorf("Burn: note value %d does not match burnt value %d without a change output", spend.Value(), value)
+	}
+
+	call, err := w.spend(shieldedBurnMethod, []*ShieldedTRC20Note{spend}, receives, amount.String(), toDesc)
+	if err != nil {
+		return nil, fmt.Errorf("Burn: %w", err)
+	}
+	call.TransparentTo = to
+	return call, nil
+}
+
+// spend builds the parameters of a transfer or burn, signed by the node with the
+// spend authorizing key when the wallet has one.
+func (w *ShieldedTRC20Wallet) spend(method string, notes []*ShieldedTRC20Note, receives []*api.ReceiveNote, toAmount string, to []byte) (*ShieldedTRC20Call, error) {
+	if len(w.keys.Nsk) == 0 || len(w.keys.Ask) == 0 && len(w.keys.Ak) == 0 {
+		return nil, fmt.Errorf("spending requires nsk and either ask or ak")
+	}
+	spends := make([]*api.SpendNoteTRC20, len(notes))
+	alphas := make([][]byte, len(notes))
+	for i, n := range notes {
+		if n.Spent {
+			return nil, fmt.Errorf("note %x:%d is already spent", n.TxID, n.Index)
+		}
+		s, err := w.spendNote(n)
+		if err != nil {
+			return nil, err
+		}
+		spends[i] = s
+		alphas[i] = s.Alpha
+	}
+
+	var (
+		params *api.ShieldedTRC20Parameters
+		err    error
+	)
+	if len(w.keys.Ask) > 0 {
+		params, err = w.client.CreateShieldedContractParameters(&api.PrivateShieldedTRC20Parameters{
+			Ask:                           w.keys.Ask,
+			Nsk:                           w.keys.Nsk,
+			Ovk:                           w.keys.Ovk,
+			ShieldedSpends:                spends,
+			ShieldedReceives:              receives,
+			TransparentToAddress:          to,
+			ToAmount:                      toAmount,
+			Shielded_TRC20ContractAddress: w.contractBytes(),
+		})
+	} else {
+		params, err = w.client.CreateShieldedContractParametersWithoutAsk(&api.PrivateShieldedTRC20ParametersWithoutAsk{
+			Ak:                            w.keys.Ak,
+			Nsk:                           w.keys.Nsk,
+			Ovk:                           w.keys.Ovk,
+			ShieldedSpends:                spends,
+			ShieldedReceives:              receives,
+			TransparentToAddress:          to,
+			ToAmount:                      toAmount,
+			Shielded_TRC20ContractAddress: w.contractBytes(),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	call, err := w.newCall(method, params, notes, alphas)
+	if err != nil {
+		return nil, err
+	}
+	call.Amount = toAmount
+	return call, nil
+}
+
+// newCall wraps the parameters built by the node, decoding the contract input if present.
+func (w *ShieldedTRC20Wallet) newCall(method string, params *api.ShieldedTRC20Parameters, spends []*ShieldedTRC20Note, alphas [][]byte) (*ShieldedTRC20Call, error) {
+	call := &ShieldedTRC20Call{Method: method, Params: params, Spends: spends, Alphas: alphas}
+	if params.GetTriggerContractInput() != "" {
+		input, err := hex.DecodeString(params.GetTriggerContractInput())
+		if err != nil {
+			return nil, fmt.Errorf("invalid trigger input: %w", err)
+		}
+		call.Input = input
+	}
+	return call, nil
+}
+
+// receiveNote creates a note for an output with a fresh random commitment.
+func (w *ShieldedTRC20Wallet) receiveNote(o ShieldedTRC20Output) (*api.ReceiveNote, error) {
+	if o.PaymentAddress == "" {
+		return nil, fmt.Errorf("missing payment address")
+	}
+	rcm, err := w.client.GetRcm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rcm: %w", err)
+	}
+	return &api.ReceiveNote{Note: &api.Note{
+		Value:          o.Value,
+		PaymentAddress: o.PaymentAddress,
+		Rcm:            rcm.GetValue(),
+		Memo:           o.Memo,
+	}}, nil
+}
+
+// spendNote prepares a note to spend: a random alpha and the Merkle path of
+// the note commitment, read from the contract.
+func (w *ShieldedTRC20Wallet) spendNote(n *ShieldedTRC20Note) (*api.SpendNoteTRC20, error) {
+	alpha, err := w.client.GetRcm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alpha: %w", err)
+	}
+	result, err := w.call("getPath(uint256)", []abi.Param{{"uint256": fmt.Sprint(n.Position)}})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 32*(1+shieldedPathLength) {
+		return nil, fmt.Errorf("getPath: unexpected result of %d bytes", len(result))
+	}
+	return &api.SpendNoteTRC20{
+		Note:  n.Note,
+		Alpha: alpha.GetValue(),
+		Root:  result[:32],
+		Path:  result[32:],
+		Pos:   n.Position,
+	}, nil
+}
+
+// Finalize encodes the input of a call built without the spend authorizing key,
+// given the spend authority signatures of its spends, in order.
+func (w *ShieldedTRC20Wallet) Finalize(call *ShieldedTRC20Call, signatures [][]byte) error {
+	if len(signatures) != len(call.Spends) {
+		return fmt.Errorf("Finalize: expected %d signatures but got %d", len(call.Spends), len(signatures))
+	}
+	params := &api.ShieldedTRC20TriggerContractParameters{
+		Shielded_TRC20_Parameters: call.Params,
+		Amount:                    call.Amount,
+	}
+	for _, sig := range signatures {
+		params.SpendAuthoritySignature = append(params.SpendAuthoritySignature, &api.BytesMessage{Value: sig})
+	}
+	if call.TransparentTo != "" {
+		to, err := base58.DecodeCheck(call.TransparentTo)
+		if err != nil {
+			return fmt.Errorf("Finalize: invalid recipient address: %w", err)
+		}
+		params.TransparentToAddress = to
+	}
+
+	input, err := w.client.GetTriggerInputForShieldedTRC20Contract(params)
+	if err != nil {
+		return fmt.Errorf("Finalize: %w", err)
+	}
+	call.Input = input.GetValue()
+	return nil
+}
+
+// Trigger creates the contract call transaction of a mint, transfer or burn, to be signed and broadcast.
+func (w *ShieldedTRC20Wallet) Trigger(from string, call *ShieldedTRC20Call, feeLimit int64) (*api.TransactionExtention, error) {
+	if len(call.Input) == 0 {
+		return nil, fmt.Errorf("Trigger: the call has no input; sign the spends and call Finalize")
+	}
+	return w.client.TriggerContractData(from, w.contract, call.Data(), feeLimit, 0)
+}
+
+// Scan scans the blocks from startBlock up to, but excluding, endBlock for notes
+// received with the incoming viewing key. It records them in the wallet and
+// returns the notes not seen before.
+func (w *ShieldedTRC20Wallet) Scan(startBlock, endBlock int64) ([]*ShieldedTRC20Note, error) {
+	if len(w.keys.Ivk) == 0 {
+		return nil, fmt.Errorf("Scan: the wallet has no incoming viewing key")
+	}
+	var found []*ShieldedTRC20Note
+	for start := startBlock; start < endBlock; start += shieldedScanMaxBlocks {
+		end := start + shieldedScanMaxBlocks
+		if end > endBlock {
+			end = endBlock
+		}
+		result, err := w.client.ScanShieldedTRC20NotesByIvk(&api.IvkDecryptTRC20Parameters{
+			StartBlockIndex:               start,
+			EndBlockIndex:                 end,
+			Shielded_TRC20ContractAddress: w.contractBytes(),
+			Ivk:                           w.keys.Ivk,
+			Ak:                            w.keys.Ak,
+			Nk:                            w.keys.Nk,
+		})
+		if err != nil {
+			return found, fmt.Errorf("Scan: blocks %d to %d: %w", start, end, err)
+		}
+
+		w.mu.Lock()
+		for _, tx := range result.GetNoteTxs() {
+			key := noteKey(tx.GetTxid(), tx.GetIndex())
+			if n, ok := w.notes[key]; ok {
+				n.Spent = n.Spent || tx.GetIsSpent()
+				continue
+			}
+			n := &ShieldedTRC20Note{
+				Note:     tx.GetNote(),
+				Position: tx.GetPosition(),
+				TxID:     tx.GetTxid(),
+				Index:    tx.GetIndex(),
+				Spent:    tx.GetIsSpent(),
+			}
+			w.notes[key] = n
+			found = append(found, n)
+		}
+		w.mu.Unlock()
+	}
+	return found, nil
+}
+
+// noteKey identifies a note by its transaction and output index.
+func noteKey(txid []byte, index int32) string {
+	return fmt.Sprintf("%s:%d", hex.EncodeToString(txid), index)
+}
+
+// RefreshSpent checks the spent status of the unspent notes with the node.
+func (w *ShieldedTRC20Wallet) RefreshSpent() error {
+	for _, n := range w.Unspent() {
+		result, err := w.client.IsShieldedTRC20ContractNoteSpent(&api.NfTRC20Parameters{
+			Note:                          n.Note,
+			Ak:                            w.keys.Ak,
+			Nk:                            w.keys.Nk,
+			Position:                      n.Position,
+			Shielded_TRC20ContractAddress: w.contractBytes(),
+		})
+		if err != nil {
+			return fmt.Errorf("RefreshSpent: %w", err)
+		}
+		if result.GetIsSpent() {
+			w.mu.Lock()
+			n.Spent = true
+			w.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Notes returns the notes of the wallet, ordered by position.
+func (w *ShieldedTRC20Wallet) Notes() []*ShieldedTRC20Note {
+	return w.filterNotes(func(*ShieldedTRC20Note) bool { return true })
+}
+
+// Unspent returns the notes not known to be spent, ordered by position.
+func (w *ShieldedTRC20Wallet) Unspent() []*ShieldedTRC20Note {
+	return w.filterNotes(func(n *ShieldedTRC20Note) bool { return !n.Spent })
+}
+
+// filterNotes returns the notes matching keep, ordered by position.
+func (w *ShieldedTRC20Wallet) filterNotes(keep func(*ShieldedTRC20Note) bool) []*ShieldedTRC20Note {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var notes []*ShieldedTRC20Note
+	for _, n := range w.notes {
+		if keep(n) {
+			notes = append(notes, n)
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Position < notes[j].Position })
+	return notes
+}
+
+// Balance returns the total value of the unspent notes.
+func (w *ShieldedTRC20Wallet) Balance() int64 {
+	var balance int64
+	for _, n := range w.Unspent() {
+		balance += n.Value()
+	}
+	return balance
+}
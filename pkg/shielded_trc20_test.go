@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShieldedClient struct {
+	TronClient
+	scans    [][2]int64
+	params   *api.PrivateShieldedTRC20Parameters
+	spent    map[int64]bool
+	lastData []byte
+}
+
+func (f *fakeShieldedClient) TriggerConstantContract(_, _ string, data []byte) (*api.TransactionExtention, error) {
+	scaling, _ := abi.Pack("scalingFactor()", nil)
+	if bytes.Equal(data, scaling) {
+		return &api.TransactionExtention{ConstantResult: [][]byte{uint256Bytes(100)}}, nil
+	}
+	// getPath(uint256): root followed by the 32 path nodes.
+	return &api.TransactionExtention{ConstantResult: [][]byte{make([]byte, 33*32)}}, nil
+}
+
+func (f *fakeShieldedClient) GetRcm() (*api.BytesMessage, error) {
+	return &api.BytesMessage{Value: []byte{1}}, nil
+}
+
+func (f *fakeShieldedClient) CreateShieldedContractParameters(p *api.PrivateShieldedTRC20Parameters) (*api.ShieldedTRC20Parameters, error) {
+	f.params = p
+	return &api.ShieldedTRC20Parameters{TriggerContractInput: "abcd"}, nil
+}
+
+func (f *fakeShieldedClient) ScanShieldedTRC20NotesByIvk(p *api.IvkDecryptTRC20Parameters) (*api.DecryptNotesTRC20, error) {
+	f.scans = append(f.scans, [2]int64{p.StartBlockIndex, p.EndBlockIndex})
+	// One note per scanned range, found again by the next scan of the same range.
+	return &api.DecryptNotesTRC20{NoteTxs: []*api.DecryptNotesTRC20_NoteTx{{
+		Note:     &api.Note{Value: 5},
+		Position: p.StartBlockIndex,
+		Txid:     big.NewInt(p.StartBlockIndex).Bytes(),
+	}}}, nil
+}
+
+func (f *fakeShieldedClient) IsShieldedTRC20ContractNoteSpent(p *api.NfTRC20Parameters) (*api.NullifierResult, error) {
+	return &api.NullifierResult{IsSpent: f.spent[p.Position]}, nil
+}
+
+func (f *fakeShieldedClient) TriggerContractData(_, _ string, data []byte, _, _ int64) (*api.TransactionExtention, error) {
+	f.lastData = data
+	return &api.TransactionExtention{}, nil
+}
+
+func uint256Bytes(v int64) []byte {
+	b := make([]byte, 32)
+	big.NewInt(v).FillBytes(b)
+	return b
+}
+
+func newTestShieldedWallet(t *testing.T, client TronClient, keys ShieldedTRC20Keys) *ShieldedTRC20Wallet {
+	w, err := NewShieldedTRC20Wallet(client, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", keys)
+	require.Nil(t, err)
+	return w
+}
+
+func TestShieldedTRC20Scan(t *testing.T) {
+	client := &fakeShieldedClient{spent: map[int64]bool{1000: true}}
+	w := newTestShieldedWallet(t, client, ShieldedTRC20Keys{Ivk: []byte{1}, Ak: []byte{2}, Nk: []byte{3}})
+
+	found, err := w.Scan(0, 2500)
+	require.Nil(t, err)
+	assert.Equal(t, [][2]int64{{0, 1000}, {1000, 2000}, {2000, 2500}}, client.scans)
+	assert.Len(t, found, 3)
+	assert.Equal(t, int64(15), w.Balance())
+
+	found, err = w.Scan(0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, found)
+
+	require.Nil(t, w.RefreshSpent())
+	assert.Equal(t, int64(10), w.Balance())
+	assert.Len(t, w.Notes(), 3)
+	assert.Len(t, w.Unspent(), 2)
+}
+
+func TestShieldedTRC20Build(t *testing.T) {
+	client := &fakeShieldedClient{}
+	w := newTestShieldedWallet(t, client, ShieldedTRC20Keys{Ask: []byte{1}, Nsk: []byte{2}, Ovk: []byte{3}})
+
+	call, err := w.Mint(ShieldedTRC20Output{PaymentAddress: "ztron1test", Value: 7})
+	require.Nil(t, err)
+	assert.Equal(t, "700", client.params.FromAmount)
+	assert.Len(t, client.params.ShieldedReceives, 1)
+
+	_, err = w.Trigger("TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", call, 0)
+	require.Nil(t, err)
+	selector, _ := abi.Pack(shieldedMintMethod, nil)
+	assert.Equal(t, append(selector, 0xab, 0xcd), client.lastData)
+
+	note := &ShieldedTRC20Note{Note: &api.Note{Value: 10}, Position: 4}
+	_, err = w.Transfer([]*ShieldedTRC20Note{note}, []ShieldedTRC20Output{{PaymentAddress: "ztron1test", Value: 9}})
+	assert.ErrorContains(t, err, "does not match")
+
+	call, err = w.Burn(note, "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", 6, &ShieldedTRC20Output{PaymentAddress: "ztron1test", Value: 4})
+	require.Nil(t, err)
+	assert.Equal(t, "600", client.params.ToAmount)
+	assert.Equal(t, int64(4), client.params.ShieldedSpends[0].Pos)
+	assert.Len(t, client.params.ShieldedSpends[0].Path, 32*32)
+	assert.Equal(t, shieldedBurnMethod, call.Method)
+
+	note.Spent = true
+	_, err = w.Transfer([]*ShieldedTRC20Note{note}, []ShieldedTRC20Output{{PaymentAddress: "ztron1test", Value: 10}})
+	assert.ErrorContains(t, err, "already spent")
+}
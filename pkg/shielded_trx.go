@@ -0,0 +1,322 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+)
+
+// Limits of a shielded TRX transfer enforced by the node.
+const (
+	shieldedMaxSpends   = 1
+	shieldedMaxReceives = 2
+)
+
+// ShieldedNote is a shielded TRX note found by the wallet.
+type ShieldedNote struct {
+	Note  *api.Note
+	TxID  []byte
+	Index int32 // index of the note among the outputs of the transaction
+	Spent bool
+}
+
+// Value returns the value of the note, in sun.
+func (n *ShieldedNote) Value() int64 {
+	return n.Note.GetValue()
+}
+
+// ShieldedOutput is a shielded TRX note to create, with a value in sun.
+type ShieldedOutput struct {
+	PaymentAddress string // ztron1... shielded address
+	Value          int64
+	Memo           []byte
+}
+
+// ShieldedWallet keeps track of the shielded TRX notes of an account and builds
+// transfers into, within and out of the shielded pool.
+type ShieldedWallet struct {
+	client TronClient
+	keys   *ShieldedKeys
+
+	mu    sync.Mutex
+	notes map[string]*ShieldedNote
+	sent  map[string]*ShieldedNote
+}
+
+// NewShieldedWallet creates a wallet for the keys of a shielded account.
+func NewShieldedWallet(client TronClient, keys *ShieldedKeys) *ShieldedWallet {
+	return &ShieldedWallet{
+		client: client,
+		keys:   keys,
+		notes:  make(map[string]*ShieldedNote),
+		sent:   make(map[string]*ShieldedNote),
+	}
+}
+
+// Scan scans the blocks from startBlock up to, but excluding, endBlock for notes
+// received with the incoming viewing key. It records them in the wallet and
+// returns the notes not seen before.
+func (w *ShieldedWallet) Scan(startBlock, endBlock int64) ([]*ShieldedNote, error) {
+	return w.scan(startBlock, endBlock, w.notes, func(start, end int64) (*api.DecryptNotes, error) {
+		return w.client.ScanNoteByIvk(&api.IvkDecryptParameters{
+			StartBlockIndex: start,
+			EndBlockIndex:   end,
+			Ivk:             w.keys.Ivk[:],
+		})
+	})
+}
+
+// ScanSent scans the blocks from startBlock up to, but excluding, endBlock for
+// notes sent with the outgoing viewing key, and returns the notes not seen before.
+func (w *ShieldedWallet) ScanSent(startBlock, endBlock int64) ([]*ShieldedNote, error) {
+	return w.scan(startBlock, endBlock, w.sent, func(start, end int64) (*api.DecryptNotes, error) {
+		return w.client.ScanNoteByOvk(&api.OvkDecryptParameters{
+			StartBlockIndex: start,
+			EndBlockIndex:   end,
+			Ovk:             w.keys.Ovk[:],
+		})
+	})
+}
+
+// scan scans a block range in windows the node accepts, adding new notes to dst.
+func (w *ShieldedWallet) scan(startBlock, endBlock int64, dst map[string]*ShieldedNote, fetch func(start, end int64) (*api.DecryptNotes, error)) ([]*ShieldedNote, error) {
+	var found []*ShieldedNote
+	for start := startBlock; start < endBlock; start += shieldedScanMaxBlocks {
+		end := start + shieldedScanMaxBlocks
+		if end > endBlock {
+			end = endBlock
+		}
+		result, err := fetch(start, end)
+		if err != nil {
+			return found, fmt.Errorf("scan blocks %d to %d: %w", start, end, err)
+		}
+
+		w.mu.Lock()
+		for _, tx := range result.GetNoteTxs() {
+			key := noteKey(tx.GetTxid(), tx.GetIndex())
+			if _, ok := dst[key]; ok {
+				continue
+			}
+			n := &ShieldedNote{Note: tx.GetNote(), TxID: tx.GetTxid(), Index: tx.GetIndex()}
+			dst[key] = n
+			found = append(found, n)
+		}
+		w.mu.Unlock()
+	}
+	return found, nil
+}
+
+// RefreshSpent checks with the node whether the nullifiers of the unspent notes have been revealed.
+func (w *ShieldedWallet) RefreshSpent() error {
+	for _, n := range w.Unspent() {
+		result, err := w.client.IsSpend(&api.NoteParameters{
+			Ak:    w.keys.Ak[:],
+			Nk:    w.keys.Nk[:],
+			Note:  n.Note,
+			Txid:  n.TxID,
+			Index: n.Index,
+		})
+		if err != nil {
+			return fmt.Errorf("RefreshSpent: %w", err)
+		}
+		if result.GetResult() {
+			w.mu.Lock()
+			n.Spent = true
+			w.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Notes returns the received notes, ordered by transaction and index.
+func (w *ShieldedWallet) Notes() []*ShieldedNote {
+	return w.filterNotes(w.notes, func(*ShieldedNote) bool { return true })
+}
+
+// Unspent returns the received notes not known to be spent, ordered by transaction and index.
+func (w *ShieldedWallet) Unspent() []*ShieldedNote {
+	return w.filterNotes(w.notes, func(n *ShieldedNote) bool { return !n.Spent })
+}
+
+// Sent returns the notes found by ScanSent, ordered by transaction and index.
+func (w *ShieldedWallet) Sent() []*ShieldedNote {
+	return w.filterNotes(w.sent, func(*ShieldedNote) bool { return true })
+}
+
+// filterNotes returns the notes of src matching keep, in a stable order.
+func (w *ShieldedWallet) filterNotes(src map[string]*ShieldedNote, keep func(*ShieldedNote) bool) []*ShieldedNote {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var (
+		keys  []string
+		notes []*ShieldedNote
+	)
+	for key, n := range src {
+		if keep(n) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		notes = append(notes, src[key])
+	}
+	return notes
+}
+
+// Balance returns the total value of the unspent notes, in sun.
+func (w *ShieldedWallet) Balance() int64 {
+	var balance int64
+	for _, n := range w.Unspent() {
+		balance += n.Value()
+	}
+	return balance
+}
+
+// Shield builds a transfer of amount sun from a transparent address into shielded notes.
+// The amount must cover the outputs and the shielded transaction fee, and the
+// transaction must be signed by the owner of the transparent address.
+func (w *ShieldedWallet) Shield(from string, amount int64, outputs []ShieldedOutput) (*api.TransactionExtention, error) {
+	fromDesc, err := base58.DecodeCheck(from)
+	if err != nil {
+		return nil, fmt.Errorf("Shield: invalid sender address: %w", err)
+	}
+	receives, err := w.receiveNotes(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("Shield: %w", err)
+	}
+	return w.create("Shield", &api.PrivateParameters{
+		TransparentFromAddress: fromDesc,
+		FromAmount:             amount,
+		ShieldedReceives:       receives,
+	}, nil)
+}
+
+// Transfer builds a transfer of a shielded note into one or two new notes.
+// The outputs receive the value of the note less the shielded transaction fee.
+func (w *ShieldedWallet) Transfer(spend *ShieldedNote, outputs []ShieldedOutput) (*api.TransactionExtention, error) {
+	receives, err := w.receiveNotes(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("Transfer: %w", err)
+	}
+	return w.create("Transfer", &api.PrivateParameters{ShieldedReceives: receives}, []*ShieldedNote{spend})
+}
+
+// Unshield builds a transfer of a shielded note to a transparent address, with
+// an optional change output. The amount and change receive the value of the
+// note less the shielded transaction fee.
+func (w *ShieldedWallet) Unshield(spend *ShieldedNote, to string, amount int64, change *ShieldedOutput) (*api.TransactionExtention, error) {
+	toDesc, err := base58.DecodeCheck(to)
+	if err != nil {
+		return nil, fmt.Errorf("Unshield: invalid recipient address: %w", err)
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("Unshield: amount must be positive")
+	}
+	var outputs []ShieldedOutput
+	if change != nil {
+		outputs = append(outputs, *change)
+	}
+	receives, err := w.receiveNotes(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("Unshield: %w", err)
+	}
+	return w.create("Unshield", &api.PrivateParameters{
+		ShieldedReceives:     receives,
+		TransparentToAddress: toDesc,
+		ToAmount:             amount,
+	}, []*ShieldedNote{spend})
+}
+
+// create adds the spends and the keys to the parameters and builds the transaction on the node.
+func (w *ShieldedWallet) create(op string, params *api.PrivateParameters, spends []*ShieldedNote) (*api.TransactionExtention, error) {
+	if len(spends) > shieldedMaxSpends {
+		return nil, fmt.Errorf("%s: at most %d note can be spent", op, shieldedMaxSpends)
+	}
+	if len(params.ShieldedReceives) > shieldedMaxReceives {
+		return nil, fmt.Errorf("%s: at most %d outputs are allowed", op, shieldedMaxReceives)
+	}
+	if len(spends) > 0 {
+		params.Ask = w.keys.Ask[:]
+		params.Nsk = w.keys.Nsk[:]
+	}
+	params.Ovk = w.keys.Ovk[:]
+
+	for _, n := range spends {
+		spend, err := w.spendNote(n)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		params.ShieldedSpends = append(params.ShieldedSpends, spend)
+	}
+
+	tx, err := w.client.CreateShieldedTransaction(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return tx, nil
+}
+
+// receiveNotes creates notes for the outputs with fresh random commitments.
+func (w *ShieldedWallet) receiveNotes(outputs []ShieldedOutput) ([]*api.ReceiveNote, error) {
+	receives := make([]*api.ReceiveNote, len(outputs))
+	for i, o := range outputs {
+		if _, err := ParsePaymentAddress(o.PaymentAddress); err != nil {
+			return nil, err
+		}
+		if o.Value <= 0 {
+			return nil, fmt.Errorf("output value must be positive")
+		}
+		rcm, err := w.client.GetRcm()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rcm: %w", err)
+		}
+		receives[i] = &api.ReceiveNote{Note: &api.Note{
+			Value:          o.Value,
+			PaymentAddress: o.PaymentAddress,
+			Rcm:            rcm.GetValue(),
+			Memo:           o.Memo,
+		}}
+	}
+	return receives, nil
+}
+
+// spendNote prepares a note to spend: a random alpha and the Merkle voucher
+// and path of the note commitment.
+func (w *ShieldedWallet) spendNote(n *ShieldedNote) (*api.SpendNote, error) {
+	if n.Spent {
+		return nil, fmt.Errorf("note %x:%d is already spent", n.TxID, n.Index)
+	}
+	alpha, err := w.client.GetRcm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alpha: %w", err)
+	}
+	info, err := w.client.GetMerkleTreeVoucherInfo(&core.OutputPointInfo{
+		OutPoints: []*core.OutputPoint{{Hash: n.TxID, Index: n.Index}},
+		BlockNum:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Merkle voucher: %w", err)
+	}
+	if len(info.GetVouchers()) == 0 || len(info.GetPaths()) == 0 {
+		return nil, fmt.Errorf("no Merkle voucher for note %x:%d", n.TxID, n.Index)
+	}
+	return &api.SpendNote{
+		Note:    n.Note,
+		Alpha:   alpha.GetValue(),
+		Voucher: info.GetVouchers()[0],
+		Path:    info.GetPaths()[0],
+	}, nil
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeZenClient struct {
+	TronClient
+	spent  map[int32]bool
+	params *api.PrivateParameters
+}
+
+func (f *fakeZenClient) ScanNoteByIvk(p *api.IvkDecryptParameters) (*api.DecryptNotes, error) {
+	// Two notes of one transaction per scanned range.
+	txid := []byte{byte(p.StartBlockIndex / 1000)}
+	return &api.DecryptNotes{NoteTxs: []*api.DecryptNotes_NoteTx{
+		{Note: &api.Note{Value: 100}, Txid: txid, Index: 0},
+		{Note: &api.Note{Value: 50}, Txid: txid, Index: 1},
+	}}, nil
+}
+
+func (f *fakeZenClient) ScanNoteByOvk(p *api.OvkDecryptParameters) (*api.DecryptNotes, error) {
+	return &api.DecryptNotes{NoteTxs: []*api.DecryptNotes_NoteTx{{Note: &api.Note{Value: 7}, Txid: []byte{9}}}}, nil
+}
+
+func (f *fakeZenClient) IsSpend(p *api.NoteParameters) (*api.SpendResult, error) {
+	return &api.SpendResult{Result: f.spent[p.Index]}, nil
+}
+
+func (f *fakeZenClient) GetRcm() (*api.BytesMessage, error) {
+	return &api.BytesMessage{Value: []byte{1}}, nil
+}
+
+func (f *fakeZenClient) GetMerkleTreeVoucherInfo(points *core.OutputPointInfo) (*core.IncrementalMerkleVoucherInfo, error) {
+	return &core.IncrementalMerkleVoucherInfo{
+		Vouchers: []*core.IncrementalMerkleVoucher{{Rt: points.OutPoints[0].Hash}},
+		Paths:    [][]byte{{2}},
+	}, nil
+}
+
+func (f *fakeZenClient) CreateShieldedTransaction(p *api.PrivateParameters) (*api.TransactionExtention, error) {
+	f.params = p
+	return &api.TransactionExtention{Txid: []byte{1}}, nil
+}
+
+func newTestShieldedKeys() *ShieldedKeys {
+	keys := &ShieldedKeys{}
+	keys.Ask[0], keys.Nsk[0], keys.Ovk[0] = 1, 2, 3
+	keys.Ak[0], keys.Nk[0], keys.Ivk[0] = 4, 5, 6
+	return keys
+}
+
+func TestShieldedWalletScan(t *testing.T) {
+	client := &fakeZenClient{spent: map[int32]bool{1: true}}
+	w := NewShieldedWallet(client, newTestShieldedKeys())
+
+	found, err := w.Scan(0, 1500)
+	require.Nil(t, err)
+	assert.Len(t, found, 4)
+	found, err = w.Scan(0, 100)
+	require.Nil(t, err)
+	assert.Empty(t, found)
+	assert.Equal(t, int64(300), w.Balance())
+
+	require.Nil(t, w.RefreshSpent())
+	assert.Equal(t, int64(200), w.Balance())
+	assert.Len(t, w.Unspent(), 2)
+	assert.Len(t, w.Notes(), 4)
+
+	sent, err := w.ScanSent(0, 10)
+	require.Nil(t, err)
+	assert.Len(t, sent, 1)
+	assert.Len(t, w.Sent(), 1)
+}
+
+func TestShieldedWalletTransfers(t *testing.T) {
+	client := &fakeZenClient{}
+	keys := newTestShieldedKeys()
+	w := NewShieldedWallet(client, keys)
+
+	var to PaymentAddress
+	to.PkD[0] = 1
+	out := ShieldedOutput{PaymentAddress: to.String(), Value: 90}
+
+	_, err := w.Shield("TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", 100, []ShieldedOutput{out})
+	require.Nil(t, err)
+	assert.Equal(t, int64(100), client.params.FromAmount)
+	assert.Empty(t, client.params.Ask)
+	assert.Len(t, client.params.ShieldedReceives, 1)
+
+	note := &ShieldedNote{Note: &api.Note{Value: 100}, TxID: []byte{7}, Index: 1}
+	_, err = w.Transfer(note, []ShieldedOutput{out})
+	require.Nil(t, err)
+	assert.Equal(t, keys.Ask[:], client.params.Ask)
+	require.Len(t, client.params.ShieldedSpends, 1)
+	assert.Equal(t, []byte{7}, client.params.ShieldedSpends[0].Voucher.Rt)
+	assert.Equal(t, []byte{2}, client.params.ShieldedSpends[0].Path)
+
+	_, err = w.Unshield(note, "TMWXhuxiT1KczhBxCseCDDsrhmpYGUcoA9", 50, &ShieldedOutput{PaymentAddress: to.String(), Value: 40})
+	require.Nil(t, err)
+	assert.Equal(t, int64(50), client.params.ToAmount)
+	assert.NotEmpty(t, client.params.TransparentToAddress)
+
+	_, err = w.Transfer(note, []ShieldedOutput{out, out, out})
+	assert.ErrorContains(t, err, "at most 2 outputs")
+	_, err = w.Transfer(note, []ShieldedOutput{{PaymentAddress: "ztron1bad", Value: 1}})
+	assert.ErrorContains(t, err, "invalid payment address")
+
+	note.Spent = true
+	_, err = w.Transfer(note, []ShieldedOutput{out})
+	assert.ErrorContains(t, err, "already spent")
+}
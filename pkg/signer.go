@@ -0,0 +1,89 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignTransaction signs the transaction ID with the private key and appends the
+// 65-byte recoverable signature to the transaction.
+func SignTransaction(tx *core.Transaction, key *ecdsa.PrivateKey) error {
+	txid, err := transactionID(tx.GetRawData())
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(txid, key)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	tx.Signature = append(tx.Signature, sig)
+	return nil
+}
+
+// PublicKeyToAddress returns the base58 address of a public key.
+func PublicKeyToAddress(pub ecdsa.PublicKey) string {
+	addr := crypto.PubkeyToAddress(pub)
+	return base58.EncodeCheck(append([]byte{abi.TronAddressPrefix}, addr.Bytes()...))
+}
+
+// RecoverTransactionSigners returns the address recovered from each signature
+// of the transaction.
+func RecoverTransactionSigners(tx *core.Transaction) ([]string, error) {
+	txid, err := transactionID(tx.GetRawData())
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]string, len(tx.GetSignature()))
+	for i, sig := range tx.GetSignature() {
+		if signers[i], err = recoverAddress(txid, sig); err != nil {
+			return nil, fmt.Errorf("signature %d: %w", i, err)
+		}
+	}
+	return signers, nil
+}
+
+// recoverAddress returns the address that produced a 65-byte recoverable signature
+// of the hash. The recovery ID may be 0/1 or 27/28.
+func recoverAddress(hash, sig []byte) (string, error) {
+	if len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	sig = append([]byte(nil), sig...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover public key: %w", err)
+	}
+	return PublicKeyToAddress(*pub), nil
+}
+
+// LoadKeystore decrypts the private key of a keystore file in the Web3 Secret
+// Storage format, as exported by TronLink and wallet-cli.
+func LoadKeystore(path, password string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return key.PrivateKey, nil
+}
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	tx := &core.Transaction{RawData: &core.TransactionRaw{RefBlockBytes: []byte{0xa1, 0xb2}, Expiration: 1700000060000}}
+
+	require.Nil(t, SignTransaction(tx, key))
+	require.Len(t, tx.Signature, 1)
+	assert.Len(t, tx.Signature[0], 65)
+
+	txid, err := transactionID(tx.RawData)
+	require.Nil(t, err)
+	pub, err := crypto.SigToPub(txid, tx.Signature[0])
+	require.Nil(t, err)
+	assert.Equal(t, PublicKeyToAddress(key.PublicKey), PublicKeyToAddress(*pub))
+	assert.Equal(t, byte('T'), PublicKeyToAddress(key.PublicKey)[0])
+
+	other, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	require.Nil(t, SignTransaction(tx, other))
+	signers, err := RecoverTransactionSigners(tx)
+	require.Nil(t, err)
+	assert.Equal(t, []string{PublicKeyToAddress(key.PublicKey), PublicKeyToAddress(other.PublicKey)}, signers)
+
+	tx.Signature = append(tx.Signature, []byte{1, 2, 3})
+	_, err = RecoverTransactionSigners(tx)
+	assert.ErrorContains(t, err, "signature 2: invalid signature length 3")
+}
+
+func TestLoadKeystore(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	account, err := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP).ImportECDSA(key, "secret")
+	require.Nil(t, err)
+
+	loaded, err := LoadKeystore(account.URL.Path, "secret")
+	require.Nil(t, err)
+	assert.True(t, key.Equal(loaded))
+
+	_, err = LoadKeystore(account.URL.Path, "wrong")
+	assert.ErrorContains(t, err, "failed to decrypt keystore")
+	_, err = LoadKeystore(account.URL.Path+".missing", "secret")
+	assert.ErrorContains(t, err, "failed to read keystore")
+}
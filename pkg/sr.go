@@ -0,0 +1,331 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/core"
+)
+
+const (
+	// SuperRepresentativeCount is the number of witnesses that produce blocks.
+	SuperRepresentativeCount = 27
+	// RewardedWitnessCount is the number of witnesses sharing the vote reward.
+	RewardedWitnessCount = 127
+)
+
+// Chain parameter keys used by the SR toolkit.
+const (
+	ParamWitnessPayPerBlock      = "getWitnessPayPerBlock"
+	ParamWitness127PayPerBlock   = "getWitness127PayPerBlock"
+	ParamMaintenanceTimeInterval = "getMaintenanceTimeInterval"
+)
+
+// WitnessInfo is a witness and its rank by votes.
+type WitnessInfo struct {
+	Address        string
+	URL            string
+	Rank           int // 1-based
+	Votes          int64
+	TotalProduced  int64
+	TotalMissed    int64
+	LatestBlockNum int64
+	Producing      bool // in the current producing schedule
+}
+
+// SuperRepresentative reports whether the witness is ranked among the block producers.
+func (w *WitnessInfo) SuperRepresentative() bool {
+	return w.Rank <= SuperRepresentativeCount
+}
+
+// MissRate returns the fraction of scheduled blocks the witness missed.
+func (w *WitnessInfo) MissRate() float64 {
+	total := w.TotalProduced + w.TotalMissed
+	if total == 0 {
+		return 0
+	}
+	return float64(w.TotalMissed) / float64(total)
+}
+
+// RankWitnesses converts a witness list into WitnessInfo sorted by votes,
+// breaking ties by address.
+func RankWitnesses(witnesses []*core.Witness) []*WitnessInfo {
+	ranked := make([]*WitnessInfo, len(witnesses))
+	for i, w := range witnesses {
+		ranked[i] = &WitnessInfo{
+			Address:        base58.EncodeCheck(w.GetAddress()),
+			URL:            w.GetUrl(),
+			Votes:          w.GetVoteCount(),
+			TotalProduced:  w.GetTotalProduced(),
+			TotalMissed:    w.GetTotalMissed(),
+			LatestBlockNum: w.GetLatestBlockNum(),
+			Producing:      w.GetIsJobs(),
+		}
+	}
+	sortWitnesses(ranked)
+	return ranked
+}
+
+func sortWitnesses(ranked []*WitnessInfo) {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Votes != ranked[j].Votes {
+			return ranked[i].Votes > ranked[j].Votes
+		}
+		return ranked[i].Address < ranked[j].Address
+	})
+	for i, w := range ranked {
+		w.Rank = i + 1
+	}
+}
+
+// RewardParameters are the chain parameters driving witness and voter rewards.
+type RewardParameters struct {
+	BlockReward         int64 // sun paid to the producer of each block
+	VoteReward          int64 // sun shared by the top 127 witnesses for each block
+	MaintenanceInterval time.Duration
+}
+
+// NewRewardParameters extracts the reward parameters from the chain parameters.
+func NewRewardParameters(params *core.ChainParameters) (*RewardParameters, error) {
+	values := make(map[string]int64, len(params.GetChainParameter()))
+	for _, p := range params.GetChainParameter() {
+		values[p.GetKey()] = p.GetValue()
+	}
+	for _, key := range []string{ParamWitnessPayPerBlock, ParamWitness127PayPerBlock, ParamMaintenanceTimeInterval} {
+		if _, ok := values[key]; !ok {
+			return nil, fmt.Errorf("chain parameter %s is missing", key)
+		}
+	}
+	return &RewardParameters{
+		BlockReward:         values[ParamWitnessPayPerBlock],
+		VoteReward:          values[ParamWitness127PayPerBlock],
+		MaintenanceInterval: time.Duration(values[ParamMaintenanceTimeInterval]) * time.Millisecond,
+	}, nil
+}
+
+// RewardEstimate is the estimated reward of a witness and of a voter over a period, in sun.
+type RewardEstimate struct {
+	Witness     string
+	Rank        int
+	Brokerage   int64 // percentage kept by the witness
+	Blocks      int64 // blocks produced by the witness
+	BlockReward int64
+	VoteReward  int64
+	VoterReward int64 // share of the voter after brokerage
+}
+
+// EstimateReward estimates the reward of a witness over a period, and the share
+// of a voter with the given votes for it. The votes are added to the current tally.
+// It assumes the ranking holds and the witness produces its share of the blocks.
+func EstimateReward(ranked []*WitnessInfo, params *RewardParameters, witness string, votes, brokerage int64, period time.Duration) (*RewardEstimate, error) {
+	if votes < 0 {
+		return nil, fmt.Errorf("votes must not be negative")
+	}
+	if brokerage < 0 || brokerage > 100 {
+		return nil, fmt.Errorf("brokerage %d is out of range", brokerage)
+	}
+
+	after := make([]*WitnessInfo, len(ranked))
+	var target *WitnessInfo
+	for i, w := range ranked {
+		c := *w
+		if c.Address == witness {
+			c.Votes += votes
+			target = &c
+		}
+		after[i] = &c
+	}
+	if target == nil {
+		return nil, fmt.Errorf("witness %s is not a candidate", witness)
+	}
+	sortWitnesses(after)
+
+	est := &RewardEstimate{Witness: witness, Rank: target.Rank, Brokerage: brokerage}
+	blocks := int64(period / BlockInterval)
+	if target.SuperRepresentative() {
+		est.Blocks = blocks / SuperRepresentativeCount
+		est.BlockReward = est.Blocks * params.BlockReward
+	}
+	if target.Rank <= RewardedWitnessCount {
+		var total int64
+		for _, w := range after[:min(len(after), RewardedWitnessCount)] {
+			total += w.Votes
+		}
+		// blocks * VoteReward * witness votes / votes of the rewarded witnesses
+		if total > 0 {
+			reward := new(big.Int).Mul(big.NewInt(blocks), big.NewInt(params.VoteReward))
+			reward.Mul(reward, big.NewInt(target.Votes))
+			est.VoteReward = reward.Div(reward, big.NewInt(total)).Int64()
+		}
+	}
+	if target.Votes > 0 {
+		voters := new(big.Int).Mul(big.NewInt(est.BlockReward+est.VoteReward), big.NewInt(100-brokerage))
+		voters.Mul(voters, big.NewInt(votes))
+		est.VoterReward = voters.Div(voters, big.NewInt(100*target.Votes)).Int64()
+	}
+	return est, nil
+}
+
+// RankChange is a change of rank expected at the next maintenance.
+type RankChange struct {
+	Address  string
+	Rank     int
+	NewRank  int
+	Votes    int64
+	NewVotes int64
+}
+
+// Elected reports whether the witness joins the block producers.
+func (c *RankChange) Elected() bool {
+	return c.Rank > SuperRepresentativeCount && c.NewRank <= SuperRepresentativeCount
+}
+
+// Dropped reports whether the witness leaves the block producers.
+func (c *RankChange) Dropped() bool {
+	return c.Rank <= SuperRepresentativeCount && c.NewRank > SuperRepresentativeCount
+}
+
+// ForecastRanks applies vote changes (witness address to added or removed votes)
+// to the current ranking and returns the witnesses whose rank changes, by new rank.
+func ForecastRanks(ranked []*WitnessInfo, deltas map[string]int64) ([]*RankChange, error) {
+	after := make([]*WitnessInfo, len(ranked))
+	before := make(map[string]*WitnessInfo, len(ranked))
+	byAddress := make(map[string]*WitnessInfo, len(ranked))
+	for i, w := range ranked {
+		c := *w
+		after[i] = &c
+		before[w.Address] = w
+		byAddress[w.Address] = &c
+	}
+	for addr, delta := range deltas {
+		w, ok := byAddress[addr]
+		if !ok {
+			return nil, fmt.Errorf("witness %s is not a candidate", addr)
+		}
+		if w.Votes += delta; w.Votes < 0 {
+			return nil, fmt.Errorf("witness %s would have negative votes", addr)
+		}
+	}
+	sortWitnesses(after)
+
+	var changes []*RankChange
+	for _, w := range after {
+		old := before[w.Address]
+		if old.Rank != w.Rank || old.Votes != w.Votes {
+			changes = append(changes, &RankChange{
+				Address:  w.Address,
+				Rank:     old.Rank,
+				NewRank:  w.Rank,
+				Votes:    old.Votes,
+				NewVotes: w.Votes,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// SRToolkit combines witness, chain parameter and maintenance queries for
+// super representative operators.
+type SRToolkit struct {
+	client TronClient
+}
+
+// NewSRToolkit creates a toolkit querying the given client.
+func NewSRToolkit(client TronClient) *SRToolkit {
+	return &SRToolkit{client: client}
+}
+
+// Witnesses returns the witness candidates ranked by votes. The votes are the
+// tally of the last maintenance.
+func (t *SRToolkit) Witnesses() ([]*WitnessInfo, error) {
+	list, err := t.client.ListWitnesses()
+	if err != nil {
+		return nil, err
+	}
+	return RankWitnesses(list.GetWitnesses()), nil
+}
+
+// Witness returns a single ranked witness.
+func (t *SRToolkit) Witness(addr string) (*WitnessInfo, error) {
+	ranked, err := t.Witnesses()
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range ranked {
+		if w.Address == addr {
+			return w, nil
+		}
+	}
+	return nil, fmt.Errorf("witness %s is not a candidate", addr)
+}
+
+// RewardParameters queries the current reward parameters.
+func (t *SRToolkit) RewardParameters() (*RewardParameters, error) {
+	params, err := t.client.GetChainParameters()
+	if err != nil {
+		return nil, err
+	}
+	return NewRewardParameters(params)
+}
+
+// EstimateReward estimates the reward of a witness over a period and the share
+// of a voter adding the given votes, using the witness brokerage.
+func (t *SRToolkit) EstimateReward(witness string, votes int64, period time.Duration) (*RewardEstimate, error) {
+	ranked, err := t.Witnesses()
+	if err != nil {
+		return nil, err
+	}
+	params, err := t.RewardParameters()
+	if err != nil {
+		return nil, err
+	}
+	brokerage, err := t.client.GetBrokerageInfo(witness)
+	if err != nil {
+		return nil, err
+	}
+	return EstimateReward(ranked, params, witness, votes, int64(brokerage), period)
+}
+
+// NextMaintenance returns the time of the next maintenance, when votes are counted.
+func (t *SRToolkit) NextMaintenance() (time.Time, error) {
+	next, err := t.client.GetNextMaintenanceTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(next.GetNum()), nil
+}
+
+// MaintenanceForecast is the ranking expected after the next maintenance.
+type MaintenanceForecast struct {
+	At      time.Time
+	Changes []*RankChange
+}
+
+// Forecast applies the vote changes cast since the last maintenance to the
+// current ranking and returns the rank changes expected at the next maintenance.
+func (t *SRToolkit) Forecast(deltas map[string]int64) (*MaintenanceForecast, error) {
+	at, err := t.NextMaintenance()
+	if err != nil {
+		return nil, err
+	}
+	ranked, err := t.Witnesses()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := ForecastRanks(ranked, deltas)
+	if err != nil {
+		return nil, err
+	}
+	return &MaintenanceForecast{At: at, Changes: changes}, nil
+}
@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSRClient serves a fixed witness list and chain parameters.
+type fakeSRClient struct {
+	TronClient
+	witnesses []*core.Witness
+	params    *core.ChainParameters
+	brokerage float64
+	next      int64
+	// permissions maps witness addresses to the address of their witness permission key.
+	permissions map[string][]byte
+}
+
+func (f *fakeSRClient) GetAccount(addr string) (*core.Account, error) {
+	address, err := base58.DecodeCheck(addr)
+	if err != nil {
+		return nil, err
+	}
+	account := &core.Account{Address: address, IsWitness: true}
+	if key, ok := f.permissions[addr]; ok {
+		account.WitnessPermission = &core.Permission{
+			Type:      core.Permission_Witness,
+			Threshold: 1,
+			Keys:      []*core.Key{{Address: key, Weight: 1}},
+		}
+	}
+	return account, nil
+}
+
+func (f *fakeSRClient) ListWitnesses() (*api.WitnessList, error) {
+	return &api.WitnessList{Witnesses: f.witnesses}, nil
+}
+
+func (f *fakeSRClient) GetChainParameters() (*core.ChainParameters, error) {
+	return f.params, nil
+}
+
+func (f *fakeSRClient) GetBrokerageInfo(string) (float64, error) {
+	return f.brokerage, nil
+}
+
+func (f *fakeSRClient) GetNextMaintenanceTime() (*api.NumberMessage, error) {
+	return GetMessageNumber(f.next), nil
+}
+
+// witnessAddress returns a distinct address for each index.
+func witnessAddress(i int) []byte {
+	addr := make([]byte, 21)
+	addr[0] = 0x41
+	addr[20] = byte(i)
+	return addr
+}
+
+// newFakeSRClient creates 130 witnesses, witness i having 1000-i votes.
+func newFakeSRClient() *fakeSRClient {
+	f := &fakeSRClient{
+		params: &core.ChainParameters{ChainParameter: []*core.ChainParameters_ChainParameter{
+			{Key: ParamMaintenanceTimeInterval, Value: 21_600_000},
+			{Key: ParamWitnessPayPerBlock, Value: 16_000_000},
+			{Key: ParamWitness127PayPerBlock, Value: 160_000_000},
+		}},
+		brokerage: 20,
+		next:      1_700_000_000_000,
+	}
+	for i := 129; i >= 0; i-- {
+		f.witnesses = append(f.witnesses, &core.Witness{
+			Address:       witnessAddress(i),
+			VoteCount:     int64(1000 - i),
+			TotalProduced: 90,
+			TotalMissed:   int64(i % 11),
+			IsJobs:        i < SuperRepresentativeCount,
+		})
+	}
+	return f
+}
+
+func TestSRToolkitWitnesses(t *testing.T) {
+	toolkit := NewSRToolkit(newFakeSRClient())
+	ranked, err := toolkit.Witnesses()
+	require.Nil(t, err)
+	require.Len(t, ranked, 130)
+	for i, w := range ranked {
+		assert.Equal(t, i+1, w.Rank)
+		assert.Equal(t, base58.EncodeCheck(witnessAddress(i)), w.Address)
+	}
+	assert.True(t, ranked[26].SuperRepresentative())
+	assert.False(t, ranked[27].SuperRepresentative())
+	assert.InDelta(t, 0.1, ranked[10].MissRate(), 1e-9)
+
+	w, err := toolkit.Witness(base58.EncodeCheck(witnessAddress(3)))
+	require.Nil(t, err)
+	assert.Equal(t, 4, w.Rank)
+	_, err = toolkit.Witness(base58.EncodeCheck(witnessAddress(200)))
+	assert.ErrorContains(t, err, "not a candidate")
+}
+
+func TestSRToolkitEstimateReward(t *testing.T) {
+	f := newFakeSRClient()
+	toolkit := NewSRToolkit(f)
+
+	params, err := toolkit.RewardParameters()
+	require.Nil(t, err)
+	assert.Equal(t, 6*time.Hour, params.MaintenanceInterval)
+
+	// The first witness produces 1/27 of the 28800 daily blocks and receives its
+	// share of the vote reward among the top 127.
+	var top int64
+	for i := 0; i < RewardedWitnessCount; i++ {
+		top += int64(1000 - i)
+	}
+	est, err := toolkit.EstimateReward(base58.EncodeCheck(witnessAddress(0)), 1000, 24*time.Hour)
+	require.Nil(t, err)
+	assert.Equal(t, 1, est.Rank)
+	assert.Equal(t, int64(28800/27), est.Blocks)
+	assert.Equal(t, est.Blocks*16_000_000, est.BlockReward)
+	assert.Equal(t, 28800*160_000_000*int64(2000)/(top+1000), est.VoteReward)
+	assert.Equal(t, (est.BlockReward+est.VoteReward)*80/100/2, est.VoterReward)
+
+	// Below the top 127 there is no reward at all.
+	est, err = toolkit.EstimateReward(base58.EncodeCheck(witnessAddress(129)), 0, 24*time.Hour)
+	require.Nil(t, err)
+	assert.Equal(t, 130, est.Rank)
+	assert.Zero(t, est.BlockReward+est.VoteReward+est.VoterReward)
+
+	f.params.ChainParameter = f.params.ChainParameter[1:]
+	_, err = toolkit.RewardParameters()
+	assert.ErrorContains(t, err, ParamMaintenanceTimeInterval)
+}
+
+func TestSRToolkitForecast(t *testing.T) {
+	toolkit := NewSRToolkit(newFakeSRClient())
+	elected := base58.EncodeCheck(witnessAddress(30))
+	forecast, err := toolkit.Forecast(map[string]int64{elected: 50})
+	require.Nil(t, err)
+	assert.Equal(t, time.UnixMilli(1_700_000_000_000), forecast.At)
+
+	// Witness 30 moves to the top, pushing the first 30 down by one.
+	require.Len(t, forecast.Changes, 31)
+	assert.Equal(t, &RankChange{Address: elected, Rank: 31, NewRank: 1, Votes: 970, NewVotes: 1020}, forecast.Changes[0])
+	assert.True(t, forecast.Changes[0].Elected())
+	assert.False(t, forecast.Changes[1].Elected() || forecast.Changes[1].Dropped())
+	dropped := forecast.Changes[27]
+	assert.Equal(t, base58.EncodeCheck(witnessAddress(26)), dropped.Address)
+	assert.True(t, dropped.Dropped())
+
+	_, err = toolkit.Forecast(map[string]int64{elected: -2000})
+	assert.ErrorContains(t, err, "negative votes")
+}
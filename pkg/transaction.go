@@ -10,6 +10,7 @@ package pkg
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/dszi/go-tron/common/base58"
@@ -19,6 +20,10 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// ErrTransactionInfoNotFound is returned when the node has no receipt for a
+// transaction: it is unknown or not in a block yet.
+var ErrTransactionInfoNotFound = errors.New("transaction info not found")
+
 // CreateTransaction creates a TRX transfer transaction.
 func (g *GrpcClient) CreateTransaction(from, toAddress string, amount int64) (*api.TransactionExtention, error) {
 	contract := &core.TransferContract{}
@@ -108,7 +113,7 @@ func (g *GrpcClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, e
 		return nil, fmt.Errorf("GetTransactionInfoByID RPC error: %w", err)
 	}
 	if !bytes.Equal(txi.Id, req.Value) {
-		return nil, fmt.Errorf("GetTransactionInfoByID: transaction info not found")
+		return nil, fmt.Errorf("GetTransactionInfoByID: %w", ErrTransactionInfoNotFound)
 	}
 	return txi, nil
 }
@@ -0,0 +1,293 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TronGridQuery holds the filters and pagination of a TronGrid v1 query.
+// Zero values are omitted.
+type TronGridQuery struct {
+	OnlyConfirmed   bool
+	OnlyUnconfirmed bool
+	MinTimestamp    time.Time
+	MaxTimestamp    time.Time
+	// ContractAddress restricts TRC-20 transfers to one token contract.
+	ContractAddress string
+	// EventName restricts contract events to one event, e.g. "Transfer".
+	EventName string
+	// OrderBy is e.g. "block_timestamp,desc".
+	OrderBy string
+	// Limit is the page size, up to 200.
+	Limit int
+	// Fingerprint continues from a previous page.
+	Fingerprint string
+}
+
+// values encodes the query parameters.
+func (q *TronGridQuery) values() url.Values {
+	v := url.Values{}
+	if q == nil {
+		return v
+	}
+	if q.OnlyConfirmed {
+		v.Set("only_confirmed", "true")
+	}
+	if q.OnlyUnconfirmed {
+		v.Set("only_unconfirmed", "true")
+	}
+	if !q.MinTimestamp.IsZero() {
+		v.Set("min_timestamp", strconv.FormatInt(q.MinTimestamp.UnixMilli(), 10))
+	}
+	if !q.MaxTimestamp.IsZero() {
+		v.Set("max_timestamp", strconv.FormatInt(q.MaxTimestamp.UnixMilli(), 10))
+	}
+	if q.ContractAddress != "" {
+		v.Set("contract_address", q.ContractAddress)
+	}
+	if q.EventName != "" {
+		v.Set("event_name", q.EventName)
+	}
+	if q.OrderBy != "" {
+		v.Set("order_by", q.OrderBy)
+	}
+	if q.Limit > 0 {
+		v.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Fingerprint != "" {
+		v.Set("fingerprint", q.Fingerprint)
+	}
+	return v
+}
+
+// TokenInfo describes the token of a TRC-20 transfer.
+type TokenInfo struct {
+	Symbol   string `json:"symbol"`
+	Address  string `json:"address"`
+	Decimals int    `json:"decimals"`
+	Name     string `json:"name"`
+}
+
+// TRC20Transfer is a TRC-20 transfer of an account.
+type TRC20Transfer struct {
+	TxID           string    `json:"transaction_id"`
+	TokenInfo      TokenInfo `json:"token_info"`
+	BlockTimestamp int64     `json:"block_timestamp"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	Type           string    `json:"type"`
+	Value          string    `json:"value"` // amount in the token's smallest unit
+}
+
+// ContractEvent is an event emitted by a contract.
+type ContractEvent struct {
+	TxID                  string            `json:"transaction_id"`
+	BlockNumber           int64             `json:"block_number"`
+	BlockTimestamp        int64             `json:"block_timestamp"`
+	CallerContractAddress string            `json:"caller_contract_address"`
+	ContractAddress       string            `json:"contract_address"`
+	EventIndex            int               `json:"event_index"`
+	EventName             string            `json:"event_name"`
+	Event                 string            `json:"event"`
+	Result                map[string]any    `json:"result"`
+	ResultType            map[string]string `json:"result_type"`
+	Unconfirmed           bool              `json:"_unconfirmed"`
+}
+
+// TRC20TransferPage is a page of TRC-20 transfers. Fingerprint is empty on the last page.
+type TRC20TransferPage struct {
+	Transfers   []TRC20Transfer
+	Fingerprint string
+}
+
+// ContractEventPage is a page of contract events. Fingerprint is empty on the last page.
+type ContractEventPage struct {
+	Events      []ContractEvent
+	Fingerprint string
+}
+
+// TronGridError is an error response of the TronGrid API.
+type TronGridError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *TronGridError) Error() string {
+	return fmt.Sprintf("TronGrid error %d: %s", e.StatusCode, e.Message)
+}
+
+// TronGridClient queries the TronGrid v1 REST API for account history and contract events.
+type TronGridClient struct {
+	BaseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	apiKey     string
+}
+
+// NewTronGridClient creates a client for the TronGrid API at baseURL, defaulting to
+// "https://api.trongrid.io". WithTimeout, WithAPIKey and WithHTTPClient apply.
+func NewTronGridClient(baseURL string, options ...HTTPOption) *TronGridClient {
+	o := newHTTPOptions(options)
+	if baseURL == "" {
+		baseURL = "https://api.trongrid.io"
+	}
+	client := &TronGridClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: o.httpClient,
+		timeout:    o.timeout,
+		apiKey:     o.apiKey,
+	}
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+	return client
+}
+
+// get performs a GET request and decodes the data of the response into data.
+// It returns the fingerprint of the next page.
+func (c *TronGridClient) get(path string, query url.Values, data any) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var msg struct {
+		Data    json.RawMessage `json:"data"`
+		Success bool            `json:"success"`
+		Error   string          `json:"error"`
+		Meta    struct {
+			Fingerprint string `json:"fingerprint"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", &TronGridError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		}
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || !msg.Success {
+		return "", &TronGridError{StatusCode: resp.StatusCode, Message: msg.Error}
+	}
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, data); err != nil {
+			return "", fmt.Errorf("invalid response data: %w", err)
+		}
+	}
+	return msg.Meta.Fingerprint, nil
+}
+
+// GetTRC20Transfers queries a page of TRC-20 transfers of an account.
+func (c *TronGridClient) GetTRC20Transfers(addr string, q *TronGridQuery) (*TRC20TransferPage, error) {
+	page := new(TRC20TransferPage)
+	fingerprint, err := c.get("/v1/accounts/"+url.PathEscape(addr)+"/transactions/trc20", q.values(), &page.Transfers)
+	if err != nil {
+		return nil, fmt.Errorf("GetTRC20Transfers: %w", err)
+	}
+	page.Fingerprint = fingerprint
+	return page, nil
+}
+
+// GetContractEvents queries a page of events emitted by a contract.
+func (c *TronGridClient) GetContractEvents(contractAddress string, q *TronGridQuery) (*ContractEventPage, error) {
+	page := new(ContractEventPage)
+	fingerprint, err := c.get("/v1/contracts/"+url.PathEscape(contractAddress)+"/events", q.values(), &page.Events)
+	if err != nil {
+		return nil, fmt.Errorf("GetContractEvents: %w", err)
+	}
+	page.Fingerprint = fingerprint
+	return page, nil
+}
+
+// GetTransactionEvents queries the events emitted by a transaction.
+func (c *TronGridClient) GetTransactionEvents(txID string, q *TronGridQuery) ([]ContractEvent, error) {
+	var events []ContractEvent
+	if _, err := c.get("/v1/transactions/"+url.PathEscape(txID)+"/events", q.values(), &events); err != nil {
+		return nil, fmt.Errorf("GetTransactionEvents: %w", err)
+	}
+	return events, nil
+}
+
+// ForEachTRC20Transfer follows the fingerprints through every page of TRC-20
+// transfers of an account, calling fn for each transfer until it returns an error.
+func (c *TronGridClient) ForEachTRC20Transfer(addr string, q *TronGridQuery, fn func(TRC20Transfer) error) error {
+	query := TronGridQuery{}
+	if q != nil {
+		query = *q
+	}
+	for {
+		page, err := c.GetTRC20Transfers(addr, &query)
+		if err != nil {
+			return err
+		}
+		for _, t := range page.Transfers {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
+		if page.Fingerprint == "" || len(page.Transfers) == 0 {
+			return nil
+		}
+		query.Fingerprint = page.Fingerprint
+	}
+}
+
+// ForEachContractEvent follows the fingerprints through every page of events
+// of a contract, calling fn for each event until it returns an error.
+func (c *TronGridClient) ForEachContractEvent(contractAddress string, q *TronGridQuery, fn func(ContractEvent) error) error {
+	query := TronGridQuery{}
+	if q != nil {
+		query = *q
+	}
+	for {
+		page, err := c.GetContractEvents(contractAddress, &query)
+		if err != nil {
+			return err
+		}
+		for _, e := range page.Events {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if page.Fingerprint == "" || len(page.Events) == 0 {
+			return nil
+		}
+		query.Fingerprint = page.Fingerprint
+	}
+}
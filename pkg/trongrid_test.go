@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTronGridTRC20Transfers(t *testing.T) {
+	addr, usdt := "TDS7NjQwQn7iNBN1UxxpsFD5UB3pxB7msL", "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("TRON-PRO-API-KEY"))
+		assert.Equal(t, "/v1/accounts/"+addr+"/transactions/trc20", r.URL.Path)
+		q := r.URL.Query()
+		assert.Equal(t, "true", q.Get("only_confirmed"))
+		assert.Equal(t, "1700000000000", q.Get("min_timestamp"))
+		assert.Equal(t, usdt, q.Get("contract_address"))
+
+		switch q.Get("fingerprint") {
+		case "":
+			_, _ = io.WriteString(w, `{"data":[{"transaction_id":"aa","token_info":{"symbol":"USDT","address":"`+usdt+`","decimals":6},`+
+				`"block_timestamp":1700000001000,"from":"`+addr+`","to":"`+usdt+`","type":"Transfer","value":"1000000"}],`+
+				`"success":true,"meta":{"at":1,"fingerprint":"page2","page_size":1}}`)
+		case "page2":
+			_, _ = io.WriteString(w, `{"data":[{"transaction_id":"bb","value":"5"}],"success":true,"meta":{"at":1,"page_size":1}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"success":false,"error":"invalid fingerprint","statusCode":400}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewTronGridClient(server.URL, WithAPIKey("secret"))
+	query := &TronGridQuery{OnlyConfirmed: true, MinTimestamp: time.UnixMilli(1700000000000), ContractAddress: usdt}
+
+	page, err := client.GetTRC20Transfers(addr, query)
+	require.Nil(t, err)
+	assert.Equal(t, "page2", page.Fingerprint)
+	require.Len(t, page.Transfers, 1)
+	assert.Equal(t, "USDT", page.Transfers[0].TokenInfo.Symbol)
+	assert.Equal(t, 6, page.Transfers[0].TokenInfo.Decimals)
+
+	var ids []string
+	err = client.ForEachTRC20Transfer(addr, query, func(tr TRC20Transfer) error {
+		ids = append(ids, tr.TxID)
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"aa", "bb"}, ids)
+
+	_, err = client.GetTRC20Transfers(addr, &TronGridQuery{OnlyConfirmed: true, MinTimestamp: query.MinTimestamp, ContractAddress: usdt, Fingerprint: "bad"})
+	var tgErr *TronGridError
+	require.ErrorAs(t, err, &tgErr)
+	assert.Equal(t, http.StatusBadRequest, tgErr.StatusCode)
+	assert.Equal(t, "invalid fingerprint", tgErr.Message)
+}
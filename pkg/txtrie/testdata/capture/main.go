@@ -0,0 +1,107 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+// Command capture writes blocks of a live node as txtrie test fixtures:
+//
+//	go run ./pkg/txtrie/testdata/capture -node grpc.trongrid.io:50051 -network mainnet 60000000 60000001
+//
+// Each block is written to pkg/txtrie/testdata/<network>_<number>.json, with its
+// header and signed transactions as encoded on the chain. A block whose
+// transactions do not match the TxTrieRoot of its header is not written.
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/dszi/go-tron/pkg"
+	"github.com/dszi/go-tron/pkg/txtrie"
+	"google.golang.org/protobuf/proto"
+)
+
+type fixture struct {
+	Network      string   `json:"network"`
+	Number       int64    `json:"number"`
+	BlockID      string   `json:"block_id"`
+	Header       string   `json:"header"`
+	TxTrieRoot   string   `json:"tx_trie_root"`
+	Transactions []string `json:"transactions"`
+	TxIDs        []string `json:"txids"`
+}
+
+func main() {
+	node := flag.String("node", "grpc.trongrid.io:50051", "gRPC address of the node")
+	network := flag.String("network", "mainnet", "network name of the fixtures")
+	dir := flag.String("dir", "pkg/txtrie/testdata", "directory of the fixtures")
+	flag.Parse()
+
+	client := pkg.NewGrpcClient(*node)
+	if err := client.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer client.Stop()
+
+	for _, arg := range flag.Args() {
+		num, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			log.Fatalf("invalid block number %q", arg)
+		}
+		if err := capture(client, *network, *dir, num); err != nil {
+			log.Fatalf("block %d: %v", num, err)
+		}
+	}
+}
+
+func capture(client pkg.TronClient, network, dir string, num int64) error {
+	block, err := client.GetBlockByNum(num)
+	if err != nil {
+		return err
+	}
+	header, err := proto.Marshal(block.GetBlockHeader())
+	if err != nil {
+		return err
+	}
+	f := &fixture{
+		Network: network,
+		Number:  num,
+		BlockID: hex.EncodeToString(block.GetBlockid()),
+		Header:  hex.EncodeToString(header),
+	}
+	var txs []*core.Transaction
+	for _, tx := range block.GetTransactions() {
+		data, err := proto.Marshal(tx.GetTransaction())
+		if err != nil {
+			return err
+		}
+		txs = append(txs, tx.GetTransaction())
+		f.Transactions = append(f.Transactions, hex.EncodeToString(data))
+		f.TxIDs = append(f.TxIDs, hex.EncodeToString(tx.GetTxid()))
+	}
+	root, err := txtrie.Root(txs)
+	if err != nil {
+		return err
+	}
+	if want := block.GetBlockHeader().GetRawData().GetTxTrieRoot(); hex.EncodeToString(root) != hex.EncodeToString(want) {
+		return fmt.Errorf("root %x of the transactions does not match the header root %x", root, want)
+	}
+	f.TxTrieRoot = hex.EncodeToString(root)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%s_%d.json", network, num))
+	return os.WriteFile(name, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,244 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+// Package txtrie builds the transaction Merkle tree of a TRON block, whose root
+// is the TxTrieRoot of the block header, and proves that a transaction is
+// included in a block.
+//
+// The tree is built as java-tron does: each leaf is the sha256 hash of a signed
+// transaction, each level hashes pairs of nodes with sha256(left || right), and
+// an odd last node is carried up unchanged. A block without transactions has a
+// zero root.
+package txtrie
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/dszi/go-tron/pb/core"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// ErrNotFound is returned when a transaction is not in the tree.
+	ErrNotFound = errors.New("transaction not found in block")
+	// ErrInvalidProof is returned when a proof does not lead to the block root.
+	ErrInvalidProof = errors.New("invalid transaction inclusion proof")
+)
+
+// Leaf returns the leaf hash of a signed transaction.
+func Leaf(tx *core.Transaction) ([]byte, error) {
+	data, err := proto.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	return hash(data), nil
+}
+
+// TxID returns the ID of a transaction, the sha256 hash of its raw data.
+func TxID(tx *core.Transaction) ([]byte, error) {
+	data, err := proto.Marshal(tx.GetRawData())
+	if err != nil {
+		return nil, err
+	}
+	return hash(data), nil
+}
+
+// Root returns the Merkle root of the transactions of a block.
+func Root(txs []*core.Transaction) ([]byte, error) {
+	tree, err := New(txs)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root(), nil
+}
+
+// Tree is the transaction Merkle tree of a block.
+type Tree struct {
+	txs    [][]byte   // encoded transactions
+	txids  [][]byte   // transaction IDs, by index
+	levels [][][]byte // leaves first, root last
+}
+
+// New builds the tree of the transactions of a block, in block order.
+func New(txs []*core.Transaction) (*Tree, error) {
+	t := &Tree{
+		txs:   make([][]byte, len(txs)),
+		txids: make([][]byte, len(txs)),
+	}
+	leaves := make([][]byte, len(txs))
+	for i, tx := range txs {
+		data, err := proto.Marshal(tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if t.txids[i], err = TxID(tx); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		t.txs[i] = data
+		leaves[i] = hash(data)
+	}
+
+	t.levels = [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		level = parents(level)
+		t.levels = append(t.levels, level)
+	}
+	return t, nil
+}
+
+// Len returns the number of transactions in the tree.
+func (t *Tree) Len() int {
+	return len(t.txs)
+}
+
+// Root returns the Merkle root of the tree.
+func (t *Tree) Root() []byte {
+	if len(t.txs) == 0 {
+		return make([]byte, sha256.Size)
+	}
+	return t.levels[len(t.levels)-1][0]
+}
+
+// Index returns the position of a transaction in the block.
+func (t *Tree) Index(txid []byte) (int, error) {
+	for i, id := range t.txids {
+		if bytes.Equal(id, txid) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %x", ErrNotFound, txid)
+}
+
+// Prove returns the inclusion proof of a transaction.
+func (t *Tree) Prove(txid []byte) (*Proof, error) {
+	index, err := t.Index(txid)
+	if err != nil {
+		return nil, err
+	}
+	return t.ProveIndex(index)
+}
+
+// ProveIndex returns the inclusion proof of the transaction at a position in the block.
+func (t *Tree) ProveIndex(index int) (*Proof, error) {
+	if index < 0 || index >= len(t.txs) {
+		return nil, fmt.Errorf("transaction index %d out of range [0, %d)", index, len(t.txs))
+	}
+	p := &Proof{Transaction: t.txs[index], Index: index, Count: len(t.txs)}
+	i := index
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if sibling := i ^ 1; sibling < len(level) {
+			p.Siblings = append(p.Siblings, level[sibling])
+		}
+		i /= 2
+	}
+	return p, nil
+}
+
+// Proof proves that a transaction is included in a block.
+type Proof struct {
+	Transaction []byte   // the signed transaction, encoded as in the block
+	Index       int      // position of the transaction in the block
+	Count       int      // number of transactions in the block
+	Siblings    [][]byte // sibling hashes from the leaf up; levels where the node is carried up have none
+}
+
+// TxID returns the ID of the proven transaction, hashing its raw data as encoded
+// in the proof.
+func (p *Proof) TxID() ([]byte, error) {
+	b := p.Transaction
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: malformed transaction", ErrInvalidProof)
+		}
+		b = b[n:]
+		if num == 1 && typ == protowire.BytesType {
+			raw, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, fmt.Errorf("%w: malformed transaction", ErrInvalidProof)
+			}
+			return hash(raw), nil
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return nil, fmt.Errorf("%w: malformed transaction", ErrInvalidProof)
+		}
+		b = b[n:]
+	}
+	return nil, fmt.Errorf("%w: transaction has no raw data", ErrInvalidProof)
+}
+
+// Root computes the Merkle root the proof leads to.
+func (p *Proof) Root() ([]byte, error) {
+	if p.Index < 0 || p.Index >= p.Count {
+		return nil, fmt.Errorf("%w: index %d out of range [0, %d)", ErrInvalidProof, p.Index, p.Count)
+	}
+	node := hash(p.Transaction)
+	siblings := p.Siblings
+	for i, width := p.Index, p.Count; width > 1; i, width = i/2, (width+1)/2 {
+		if i%2 == 0 && i+1 == width {
+			continue // carried up
+		}
+		if len(siblings) == 0 {
+			return nil, fmt.Errorf("%w: missing sibling", ErrInvalidProof)
+		}
+		if i%2 == 0 {
+			node = pair(node, siblings[0])
+		} else {
+			node = pair(siblings[0], node)
+		}
+		siblings = siblings[1:]
+	}
+	if len(siblings) > 0 {
+		return nil, fmt.Errorf("%w: %d unused siblings", ErrInvalidProof, len(siblings))
+	}
+	return node, nil
+}
+
+// Verify checks that the proof leads to the TxTrieRoot of the block header and
+// returns the ID of the proven transaction. The header itself must be trusted,
+// e.g. checked against a known block ID.
+func (p *Proof) Verify(header *core.BlockHeader) ([]byte, error) {
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, header.GetRawData().GetTxTrieRoot()) {
+		return nil, fmt.Errorf("%w: root %x does not match the block header", ErrInvalidProof, root)
+	}
+	return p.TxID()
+}
+
+// parents returns the next level of the tree.
+func parents(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, pair(level[i], level[i+1]))
+	}
+	return next
+}
+
+// pair hashes two sibling nodes.
+func pair(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+func hash(data []byte) []byte {
+	h := sha256.Sum256(data)
+	return h[:]
+}
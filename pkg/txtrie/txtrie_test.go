@@ -0,0 +1,241 @@
+package txtrie
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fixture is a block with its header and transactions encoded as on the chain.
+type fixture struct {
+	Network      string   `json:"network"`
+	Number       int64    `json:"number"`
+	BlockID      string   `json:"block_id"`
+	Header       string   `json:"header"`
+	TxTrieRoot   string   `json:"tx_trie_root"`
+	Transactions []string `json:"transactions"`
+	TxIDs        []string `json:"txids"`
+}
+
+func loadFixture(t *testing.T, name string) (*fixture, *core.BlockHeader, []*core.Transaction) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.Nil(t, err)
+	f := new(fixture)
+	require.Nil(t, json.Unmarshal(data, f))
+
+	header := new(core.BlockHeader)
+	require.Nil(t, proto.Unmarshal(mustHex(t, f.Header), header))
+	txs := make([]*core.Transaction, len(f.Transactions))
+	for i, s := range f.Transactions {
+		txs[i] = new(core.Transaction)
+		require.Nil(t, proto.Unmarshal(mustHex(t, s), txs[i]))
+	}
+	return f, header, txs
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.Nil(t, err)
+	return b
+}
+
+// TestFixtures checks every block in testdata: its block ID commits to the
+// transaction trie root, and each of its transactions is proven against it.
+func TestFixtures(t *testing.T) {
+	names, err := filepath.Glob("testdata/*.json")
+	require.Nil(t, err)
+	require.NotEmpty(t, names)
+	for _, name := range names {
+		f, header, txs := loadFixture(t, filepath.Base(name))
+
+		raw, err := proto.Marshal(header.RawData)
+		require.Nil(t, err)
+		id := sha256.Sum256(raw)
+		binary.BigEndian.PutUint64(id[:8], uint64(f.Number))
+		assert.Equal(t, f.BlockID, hex.EncodeToString(id[:]), name)
+
+		tree, err := New(txs)
+		require.Nil(t, err)
+		assert.Equal(t, len(f.TxIDs), tree.Len(), name)
+		assert.Equal(t, f.TxTrieRoot, hex.EncodeToString(tree.Root()), name)
+		assert.Equal(t, header.RawData.TxTrieRoot, tree.Root(), name)
+
+		for i, txid := range f.TxIDs {
+			proof, err := tree.Prove(mustHex(t, txid))
+			require.Nil(t, err)
+			assert.Equal(t, i, proof.Index)
+			assert.Equal(t, mustHex(t, f.Transactions[i]), proof.Transaction)
+			proven, err := proof.Verify(header)
+			require.Nil(t, err, "%s: transaction %d", name, i)
+			assert.Equal(t, txid, hex.EncodeToString(proven))
+		}
+	}
+}
+
+// The mainnet genesis block holds three transactions: its odd leaf is carried up.
+func TestMainnetGenesis(t *testing.T) {
+	_, _, txs := loadFixture(t, "mainnet_genesis.json")
+	tree, err := New(txs)
+	require.Nil(t, err)
+	assert.Equal(t, 3, tree.Len())
+
+	// The last transaction is carried up to the root level.
+	proof, err := tree.ProveIndex(2)
+	require.Nil(t, err)
+	assert.Len(t, proof.Siblings, 1)
+
+	_, err = tree.Prove(make([]byte, 32))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestSignedTransactions checks that leaves commit to the signatures, in a block
+// of 13 transactions whose last node is carried up at two levels (13 and 7 nodes).
+func TestSignedTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	var txs []*core.Transaction
+	for n := 0; n < 13; n++ {
+		tx := &core.Transaction{RawData: &core.TransactionRaw{Timestamp: int64(n), Expiration: int64(n) + 60_000}}
+		txid, err := TxID(tx)
+		require.Nil(t, err)
+		sig, err := crypto.Sign(txid, key)
+		require.Nil(t, err)
+		tx.Signature = [][]byte{sig}
+		txs = append(txs, tx)
+	}
+	tree, err := New(txs)
+	require.Nil(t, err)
+	header := &core.BlockHeader{RawData: &core.BlockHeaderRaw{TxTrieRoot: tree.Root()}}
+
+	// The last transaction is carried up twice before it is paired at the levels of 4 and 2 nodes.
+	proof, err := tree.ProveIndex(12)
+	require.Nil(t, err)
+	assert.Len(t, proof.Siblings, 2)
+	_, err = proof.Verify(header)
+	require.Nil(t, err)
+
+	// The same transaction without its signature has the same ID but another leaf.
+	proof, err = tree.ProveIndex(5)
+	require.Nil(t, err)
+	unsigned, err := proto.Marshal(&core.Transaction{RawData: txs[5].RawData})
+	require.Nil(t, err)
+	tampered := *proof
+	tampered.Transaction = unsigned
+	txid, err := tampered.TxID()
+	require.Nil(t, err)
+	expected, err := TxID(txs[5])
+	require.Nil(t, err)
+	assert.Equal(t, expected, txid)
+	_, err = tampered.Verify(header)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	// Replacing a signature changes the root.
+	resigned := proto.Clone(txs[5]).(*core.Transaction)
+	resigned.Signature[0][0] ^= 1
+	txs[5] = resigned
+	root, err := Root(txs)
+	require.Nil(t, err)
+	assert.NotEqual(t, tree.Root(), root)
+}
+
+func TestProofTampering(t *testing.T) {
+	_, header, txs := loadFixture(t, "mainnet_genesis.json")
+	tree, err := New(txs)
+	require.Nil(t, err)
+
+	proof, err := tree.ProveIndex(1)
+	require.Nil(t, err)
+	tampered := *proof
+	tampered.Siblings = [][]byte{make([]byte, 32), proof.Siblings[1]}
+	_, err = tampered.Verify(header)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	tampered = *proof
+	tampered.Index = 0
+	_, err = tampered.Verify(header)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	tampered = *proof
+	tampered.Siblings = proof.Siblings[:1]
+	_, err = tampered.Verify(header)
+	assert.ErrorContains(t, err, "missing sibling")
+
+	tampered = *proof
+	tampered.Siblings = append(proof.Siblings, make([]byte, 32))
+	_, err = tampered.Verify(header)
+	assert.ErrorContains(t, err, "unused siblings")
+
+	tampered = *proof
+	tampered.Transaction = append([]byte(nil), proof.Transaction...)
+	tampered.Transaction[len(tampered.Transaction)-1] ^= 1
+	_, err = tampered.Verify(header)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+
+	tampered = *proof
+	tampered.Index = 3
+	_, err = tampered.Verify(header)
+	assert.ErrorContains(t, err, "out of range")
+}
+
+// reference computes the root of leaves recursively, pairing the first half
+// rounded up to a power of two with the rest, as java-tron's MerkleTree does.
+func reference(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	half := 1
+	for half*2 < len(leaves) {
+		half *= 2
+	}
+	return pair(reference(leaves[:half]), reference(leaves[half:]))
+}
+
+func TestTreeSizes(t *testing.T) {
+	root, err := Root(nil)
+	require.Nil(t, err)
+	assert.Equal(t, make([]byte, 32), root)
+
+	var txs []*core.Transaction
+	var leaves [][]byte
+	for n := 1; n <= 17; n++ {
+		tx := &core.Transaction{RawData: &core.TransactionRaw{Expiration: int64(n)}, Signature: [][]byte{{byte(n)}}}
+		txs = append(txs, tx)
+		leaf, err := Leaf(tx)
+		require.Nil(t, err)
+		leaves = append(leaves, leaf)
+
+		tree, err := New(txs)
+		require.Nil(t, err)
+		require.Equal(t, reference(leaves), tree.Root(), "%d transactions", n)
+
+		header := &core.BlockHeader{RawData: &core.BlockHeaderRaw{TxTrieRoot: tree.Root()}}
+		for i, tx := range txs {
+			txid, err := TxID(tx)
+			require.Nil(t, err)
+			proof, err := tree.Prove(txid)
+			require.Nil(t, err)
+			proven, err := proof.Verify(header)
+			require.Nil(t, err, "%d of %d transactions", i, n)
+			assert.Equal(t, txid, proven)
+		}
+	}
+}
+
+func TestProofTxID(t *testing.T) {
+	_, err := (&Proof{Transaction: []byte{0x12, 0x01, 0x00}}).TxID()
+	assert.ErrorContains(t, err, "no raw data")
+	_, err = (&Proof{Transaction: []byte{0x0a, 0x05, 0x00}}).TxID()
+	assert.ErrorContains(t, err, "malformed transaction")
+}
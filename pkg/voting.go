@@ -0,0 +1,290 @@
+//
+// Copyright (C) 2024 dszi
+//
+// This file may be modified and distributed under the terms
+// of the MIT license. See the LICENSE file for details.
+// Repository: https://github.com/dszi/go-tron
+//
+
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+)
+
+const (
+	// MaxVoteWitnesses is the number of witnesses an account may vote for at once.
+	MaxVoteWitnesses = 30
+	// RewardClaimInterval is the minimum time between two reward withdrawals.
+	RewardClaimInterval = 24 * time.Hour
+
+	// year is the period of the expected rewards of a vote plan.
+	year = 365 * 24 * time.Hour
+	// planSteps is the number of chunks the TRON Power is split into by the planner.
+	planSteps = 1000
+)
+
+// TronPower returns the votes an account can cast: one per TRX staked with
+// Stake 2.0, including the stake delegated to other accounts.
+func TronPower(acc *core.Account) int64 {
+	staked := acc.GetDelegatedFrozenV2BalanceForBandwidth() + acc.GetAccountResource().GetDelegatedFrozenV2BalanceForEnergy()
+	for _, frozen := range acc.GetFrozenV2() {
+		staked += frozen.GetAmount()
+	}
+	return staked / SunPerTrx
+}
+
+// ValidateVotes checks votes against the witness limit and the TRON Power of the account.
+func ValidateVotes(votes map[string]int64, power int64) error {
+	if len(votes) == 0 {
+		return fmt.Errorf("no votes")
+	}
+	if len(votes) > MaxVoteWitnesses {
+		return fmt.Errorf("votes for %d witnesses exceed the limit of %d", len(votes), MaxVoteWitnesses)
+	}
+	var total int64
+	for witness, count := range votes {
+		if count <= 0 {
+			return fmt.Errorf("votes for %s must be greater than 0", witness)
+		}
+		total += count
+	}
+	if total > power {
+		return fmt.Errorf("%d votes exceed the TRON Power of %d", total, power)
+	}
+	return nil
+}
+
+// PlannedVote is the share of a vote plan given to one witness.
+type PlannedVote struct {
+	Witness   string
+	Votes     int64
+	Brokerage int64 // percentage kept by the witness
+	Reward    int64 // expected yearly reward of the votes, in sun
+}
+
+// VotePlan splits the TRON Power of an account across witnesses.
+type VotePlan struct {
+	Owner  string
+	Power  int64
+	Votes  []*PlannedVote // by votes, descending
+	Reward int64          // expected yearly reward, in sun
+}
+
+// APR returns the expected yearly reward relative to the staked TRX.
+func (p *VotePlan) APR() float64 {
+	if p.Power == 0 {
+		return 0
+	}
+	return float64(p.Reward) / float64(p.Power*SunPerTrx)
+}
+
+// VoteMap returns the plan in the form taken by VoteWitnessAccount.
+func (p *VotePlan) VoteMap() map[string]int64 {
+	votes := make(map[string]int64, len(p.Votes))
+	for _, v := range p.Votes {
+		votes[v.Witness] = v.Votes
+	}
+	return votes
+}
+
+// voteCandidate is a witness considered by the planner.
+type voteCandidate struct {
+	witness   *WitnessInfo
+	brokerage int64
+	votes     int64 // planned
+}
+
+// reward returns the expected yearly voter reward of the given votes, in sun.
+// The vote reward is shared by votes across the rewarded witnesses, while the
+// block reward of a super representative is shared by the votes it receives.
+func (c *voteCandidate) reward(votes int64, total float64, params *RewardParameters) float64 {
+	if votes == 0 {
+		return 0
+	}
+	blocks := float64(year / BlockInterval)
+	reward := blocks * float64(params.VoteReward) * float64(votes) / total
+	if c.witness.SuperRepresentative() {
+		produced := blocks / SuperRepresentativeCount * float64(params.BlockReward)
+		reward += produced * float64(votes) / float64(c.witness.Votes+votes)
+	}
+	return reward * float64(100-c.brokerage) / 100
+}
+
+// VotePlanner plans the votes of an account to maximize its expected rewards.
+type VotePlanner struct {
+	client  TronClient
+	toolkit *SRToolkit
+}
+
+// NewVotePlanner creates a planner querying the given client.
+func NewVotePlanner(client TronClient) *VotePlanner {
+	return &VotePlanner{client: client, toolkit: NewSRToolkit(client)}
+}
+
+// Plan splits the TRON Power of the owner across at most maxWitnesses of the
+// rewarded witnesses. The power is allocated in small chunks, each going to the
+// witness whose voters gain the most from it given its brokerage and votes.
+// The current votes of the owner are replaced by the plan, and the ranking is
+// assumed to hold. It queries the brokerage of each of the top 127 witnesses.
+func (v *VotePlanner) Plan(owner string, maxWitnesses int) (*VotePlan, error) {
+	if maxWitnesses < 1 || maxWitnesses > MaxVoteWitnesses {
+		return nil, fmt.Errorf("Plan: witness count must be between 1 and %d", MaxVoteWitnesses)
+	}
+	acc, err := v.client.GetAccount(owner)
+	if err != nil {
+		return nil, err
+	}
+	power := TronPower(acc)
+	if power == 0 {
+		return nil, fmt.Errorf("Plan: account %s has no TRON Power", owner)
+	}
+	ranked, err := v.toolkit.Witnesses()
+	if err != nil {
+		return nil, err
+	}
+	params, err := v.toolkit.RewardParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the current votes of the owner, which the plan replaces.
+	current := make(map[string]int64, len(acc.GetVotes()))
+	for _, vote := range acc.GetVotes() {
+		current[base58.EncodeCheck(vote.GetVoteAddress())] += vote.GetVoteCount()
+	}
+	for i, w := range ranked {
+		c := *w
+		c.Votes = max(0, c.Votes-current[c.Address])
+		ranked[i] = &c
+	}
+	sortWitnesses(ranked)
+
+	candidates := make([]*voteCandidate, 0, RewardedWitnessCount)
+	total := float64(power)
+	for _, w := range ranked[:min(len(ranked), RewardedWitnessCount)] {
+		brokerage, err := v.client.GetBrokerageInfo(w.Address)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &voteCandidate{witness: w, brokerage: int64(brokerage)})
+		total += float64(w.Votes)
+	}
+
+	step := max(1, power/planSteps)
+	chosen := 0
+	for remaining := power; remaining > 0; {
+		amount := min(step, remaining)
+		var best *voteCandidate
+		var bestGain float64
+		for _, c := range candidates {
+			if c.votes == 0 && chosen == maxWitnesses {
+				continue
+			}
+			gain := c.reward(c.votes+amount, total, params) - c.reward(c.votes, total, params)
+			if best == nil || gain > bestGain {
+				best, bestGain = c, gain
+			}
+		}
+		if best == nil {
+			return nil, fmt.Errorf("Plan: no rewarded witness to vote for")
+		}
+		if best.votes == 0 {
+			chosen++
+		}
+		best.votes += amount
+		remaining -= amount
+	}
+
+	plan := &VotePlan{Owner: owner, Power: power}
+	for _, c := range candidates {
+		if c.votes == 0 {
+			continue
+		}
+		reward := int64(c.reward(c.votes, total, params))
+		plan.Votes = append(plan.Votes, &PlannedVote{
+			Witness:   c.witness.Address,
+			Votes:     c.votes,
+			Brokerage: c.brokerage,
+			Reward:    reward,
+		})
+		plan.Reward += reward
+	}
+	sort.SliceStable(plan.Votes, func(i, j int) bool { return plan.Votes[i].Votes > plan.Votes[j].Votes })
+	return plan, nil
+}
+
+// Vote builds the vote transaction of a plan.
+func (v *VotePlanner) Vote(plan *VotePlan) (*api.TransactionExtention, error) {
+	votes := plan.VoteMap()
+	if err := ValidateVotes(votes, plan.Power); err != nil {
+		return nil, fmt.Errorf("Vote: %w", err)
+	}
+	return v.client.VoteWitnessAccount(plan.Owner, votes)
+}
+
+// RewardClaimScheduler withdraws the voting rewards of an account once per
+// 24h window, when they reach a minimum amount.
+type RewardClaimScheduler struct {
+	client    TronClient
+	owner     string
+	minReward int64
+	now       func() time.Time
+}
+
+// NewRewardClaimScheduler creates a scheduler claiming the rewards of the owner
+// once they reach minReward sun.
+func NewRewardClaimScheduler(client TronClient, owner string, minReward int64) *RewardClaimScheduler {
+	return &RewardClaimScheduler{
+		client:    client,
+		owner:     owner,
+		minReward: minReward,
+		now:       time.Now,
+	}
+}
+
+// NextClaim returns the start of the next window in which the rewards can be withdrawn.
+func (s *RewardClaimScheduler) NextClaim() (time.Time, error) {
+	acc, err := s.client.GetAccount(s.owner)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if acc.GetLatestWithdrawTime() == 0 {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(acc.GetLatestWithdrawTime()).Add(RewardClaimInterval), nil
+}
+
+// Claim builds a withdraw transaction when the window since the last withdrawal
+// has passed and the unclaimed reward reaches the minimum. It returns a nil
+// transaction when there is nothing to claim yet.
+func (s *RewardClaimScheduler) Claim() (*api.TransactionExtention, error) {
+	next, err := s.NextClaim()
+	if err != nil {
+		return nil, err
+	}
+	if s.now().Before(next) {
+		return nil, nil
+	}
+	reward, err := s.client.GetRewardInfo(s.owner)
+	if err != nil {
+		return nil, err
+	}
+	if reward == 0 || reward < s.minReward {
+		return nil, nil
+	}
+	tx, err := s.client.WithdrawBalance(s.owner)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, fmt.Errorf("Claim: %w", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,153 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dszi/go-tron/common/base58"
+	"github.com/dszi/go-tron/pb/api"
+	"github.com/dszi/go-tron/pb/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVotingClient extends the SR fake with an account, per-witness brokerage and rewards.
+type fakeVotingClient struct {
+	*fakeSRClient
+	account    *core.Account
+	brokerages map[string]float64
+	reward     int64
+	votes      map[string]int64
+}
+
+func (f *fakeVotingClient) GetAccount(string) (*core.Account, error) {
+	return f.account, nil
+}
+
+func (f *fakeVotingClient) GetBrokerageInfo(witness string) (float64, error) {
+	if b, ok := f.brokerages[witness]; ok {
+		return b, nil
+	}
+	return 20, nil
+}
+
+func (f *fakeVotingClient) GetRewardInfo(string) (int64, error) {
+	return f.reward, nil
+}
+
+func (f *fakeVotingClient) WithdrawBalance(string) (*api.TransactionExtention, error) {
+	return &api.TransactionExtention{Transaction: &core.Transaction{}, Txid: []byte{1}, Result: &api.Return{Result: true}}, nil
+}
+
+func (f *fakeVotingClient) VoteWitnessAccount(_ string, votes map[string]int64) (*api.TransactionExtention, error) {
+	f.votes = votes
+	return &api.TransactionExtention{Transaction: &core.Transaction{}, Txid: []byte{2}, Result: &api.Return{Result: true}}, nil
+}
+
+func newFakeVotingClient() *fakeVotingClient {
+	return &fakeVotingClient{
+		fakeSRClient: newFakeSRClient(),
+		account: &core.Account{
+			FrozenV2: []*core.Account_FreezeV2{
+				{Amount: 6000 * SunPerTrx},
+				{Type: core.ResourceCode_ENERGY, Amount: 3000 * SunPerTrx},
+			},
+			AccountResource:                      &core.Account_AccountResource{DelegatedFrozenV2BalanceForEnergy: 1000 * SunPerTrx},
+			DelegatedFrozenV2BalanceForBandwidth: 500_000,
+			Votes:                                []*core.Vote{{VoteAddress: witnessAddress(0), VoteCount: 400}},
+		},
+		brokerages: map[string]float64{
+			base58.EncodeCheck(witnessAddress(5)):  100,
+			base58.EncodeCheck(witnessAddress(20)): 0,
+		},
+	}
+}
+
+func TestTronPowerAndValidateVotes(t *testing.T) {
+	f := newFakeVotingClient()
+	assert.Equal(t, int64(10000), TronPower(f.account))
+
+	assert.Nil(t, ValidateVotes(map[string]int64{"a": 6000, "b": 4000}, 10000))
+	assert.ErrorContains(t, ValidateVotes(map[string]int64{"a": 6000, "b": 4001}, 10000), "exceed the TRON Power")
+	assert.ErrorContains(t, ValidateVotes(map[string]int64{"a": 0}, 10000), "greater than 0")
+	assert.ErrorContains(t, ValidateVotes(nil, 10000), "no votes")
+	many := make(map[string]int64)
+	for i := 0; i <= MaxVoteWitnesses; i++ {
+		many[base58.EncodeCheck(witnessAddress(i))] = 1
+	}
+	assert.ErrorContains(t, ValidateVotes(many, 10000), "limit of 30")
+}
+
+func TestVotePlanner(t *testing.T) {
+	f := newFakeVotingClient()
+	planner := NewVotePlanner(f)
+
+	plan, err := planner.Plan("owner", MaxVoteWitnesses)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10000), plan.Power)
+	assert.LessOrEqual(t, len(plan.Votes), MaxVoteWitnesses)
+
+	var total, reward int64
+	votes := plan.VoteMap()
+	for _, v := range plan.Votes {
+		total += v.Votes
+		reward += v.Reward
+	}
+	assert.Equal(t, plan.Power, total)
+	assert.Equal(t, plan.Reward, reward)
+	assert.Greater(t, plan.APR(), 0.0)
+	// No votes go to a witness keeping all rewards, nor outside the top 127.
+	assert.NotContains(t, votes, base58.EncodeCheck(witnessAddress(5)))
+	assert.NotContains(t, votes, base58.EncodeCheck(witnessAddress(128)))
+	// The super representative without brokerage pays its voters the most.
+	assert.Equal(t, base58.EncodeCheck(witnessAddress(20)), plan.Votes[0].Witness)
+
+	single, err := planner.Plan("owner", 1)
+	require.Nil(t, err)
+	require.Len(t, single.Votes, 1)
+	assert.Equal(t, int64(10000), single.Votes[0].Votes)
+	assert.LessOrEqual(t, single.Reward, plan.Reward)
+
+	_, err = planner.Plan("owner", MaxVoteWitnesses+1)
+	assert.ErrorContains(t, err, "between 1 and 30")
+
+	tx, err := planner.Vote(plan)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{2}, tx.Txid)
+	assert.Equal(t, votes, f.votes)
+
+	f.account = &core.Account{}
+	_, err = planner.Plan("owner", MaxVoteWitnesses)
+	assert.ErrorContains(t, err, "no TRON Power")
+}
+
+func TestRewardClaimScheduler(t *testing.T) {
+	f := newFakeVotingClient()
+	now := time.UnixMilli(1_700_000_000_000)
+	s := NewRewardClaimScheduler(f, "owner", 5*SunPerTrx)
+	s.now = func() time.Time { return now }
+
+	// Never withdrawn: claim as soon as the reward is worthwhile.
+	f.reward = 4 * SunPerTrx
+	tx, err := s.Claim()
+	require.Nil(t, err)
+	assert.Nil(t, tx)
+	f.reward = 5 * SunPerTrx
+	tx, err = s.Claim()
+	require.Nil(t, err)
+	assert.Equal(t, []byte{1}, tx.Txid)
+
+	// Withdrawn 23 hours ago: wait for the window.
+	f.account.LatestWithdrawTime = now.Add(-23 * time.Hour).UnixMilli()
+	next, err := s.NextClaim()
+	require.Nil(t, err)
+	assert.Equal(t, now.Add(time.Hour), next)
+	tx, err = s.Claim()
+	require.Nil(t, err)
+	assert.Nil(t, tx)
+
+	now = now.Add(time.Hour)
+	tx, err = s.Claim()
+	require.Nil(t, err)
+	assert.NotNil(t, tx)
+}
@@ -18,6 +18,26 @@ import (
 
 // VoteWitnessAccount submits a vote for super representative candidates.
 func (g *GrpcClient) VoteWitnessAccount(from string, witnessMap map[string]int64) (*api.TransactionExtention, error) {
+	contract, err := newVoteWitnessContract(from, witnessMap)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := g.getContext()
+	defer cancel()
+
+	tx, err := g.Client.VoteWitnessAccount2(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("VoteWitnessAccount RPC error: %w", err)
+	}
+	if err := validateTx(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// newVoteWitnessContract builds a vote contract from a map of witness addresses to vote counts.
+func newVoteWitnessContract(from string, witnessMap map[string]int64) (*core.VoteWitnessContract, error) {
 	contract := &core.VoteWitnessContract{}
 	var err error
 
@@ -37,18 +57,7 @@ func (g *GrpcClient) VoteWitnessAccount(from string, witnessMap map[string]int64
 			VoteCount:   count,
 		})
 	}
-
-	ctx, cancel := g.getContext()
-	defer cancel()
-
-	tx, err := g.Client.VoteWitnessAccount2(ctx, contract)
-	if err != nil {
-		return nil, fmt.Errorf("VoteWitnessAccount RPC error: %w", err)
-	}
-	if err := validateTx(tx); err != nil {
-		return nil, err
-	}
-	return tx, nil
+	return contract, nil
 }
 
 // ListWitnesses queries the list of super representative candidates.
@@ -111,7 +120,7 @@ func (g *GrpcClient) UpdateWitness(from, urlStr string) (*api.TransactionExtenti
 	return tx, nil
 }
 
-// GetBrokerageInfo queries the unclaimed brokerage reward.
+// GetBrokerageInfo queries the brokerage ratio of a witness, in percent.
 func (g *GrpcClient) GetBrokerageInfo(witness string) (float64, error) {
 	addr, err := base58.DecodeCheck(witness)
 	if err != nil {